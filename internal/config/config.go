@@ -0,0 +1,351 @@
+package config
+
+import (
+	"Go-Utilities/internal/consts"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Config is the effective application configuration. It is built from the
+// defaults below, then a JSON config file, then GO_UTILITIES_* environment
+// variables, then command-line flags, each layer overriding the previous one.
+type Config struct {
+	Server    ServerConfig    `json:"server"`
+	Paths     PathsConfig     `json:"paths"`
+	Downloads DownloadsConfig `json:"downloads"`
+	YtDlp     YtDlpConfig     `json:"yt_dlp"`
+	Sites     SitesConfig     `json:"sites"`
+	Tagging   TaggingConfig   `json:"tagging"`
+}
+
+type ServerConfig struct {
+	Address     string `json:"address"`
+	OpenBrowser bool   `json:"open_browser"`
+}
+
+type PathsConfig struct {
+	DependenciesDir string `json:"dependencies_dir"`
+	TempDir         string `json:"temp_dir"`
+	DataDir         string `json:"data_dir"`
+	OutputDir       string `json:"output_dir"`
+	// YtDlp and FFmpeg pin a binary; when empty the dependency resolver
+	// searches for one.
+	YtDlp  string `json:"yt_dlp"`
+	FFmpeg string `json:"ffmpeg"`
+}
+
+type DownloadsConfig struct {
+	SaveTarget        string      `json:"save_target"`
+	FileRetention     Duration    `json:"file_retention"`
+	MaxConcurrentJobs int         `json:"max_concurrent_jobs"`
+	Retry             RetryConfig `json:"retry"`
+	// InterruptedRetention is how long the workspace of a job cut off by
+	// a shutdown is kept for it to be resumed.
+	InterruptedRetention Duration `json:"interrupted_retention"`
+}
+
+// RetryConfig is how jobs that fail for a reason that may pass, such as a
+// 403 or a timeout, are retried. The delay starts at InitialDelay and
+// doubles after every attempt up to MaxDelay, less a random part of up to
+// half. Each attempt after the first adds the next of Strategies to the
+// yt-dlp arguments, keeping those added before it.
+type RetryConfig struct {
+	MaxAttempts  int      `json:"max_attempts"`
+	InitialDelay Duration `json:"initial_delay"`
+	MaxDelay     Duration `json:"max_delay"`
+	Strategies   []string `json:"strategies"`
+}
+
+// YtDlpConfig holds the options passed to every yt-dlp invocation. The
+// argument lists can only be replaced from the config file.
+type YtDlpConfig struct {
+	UserAgent        string   `json:"user_agent"`
+	GeoBypassCountry string   `json:"geo_bypass_country"`
+	Proxy            string   `json:"proxy"`
+	DownloadArgs     []string `json:"download_args"`
+	AudioArgs        []string `json:"audio_args"`
+	InfoArgs         []string `json:"info_args"`
+	PlaylistArgs     []string `json:"playlist_args"`
+	SubtitleArgs     []string `json:"subtitle_args"`
+	// Mp3Args is the old name of AudioArgs, still read from config files.
+	Mp3Args []string `json:"mp3_args,omitempty"`
+}
+
+// TaggingConfig holds the rules for reading tags from a video title when
+// the site does not provide them. TitleCleanup patterns are removed from
+// the title, then the first TitlePatterns entry that matches fills the tags
+// named by its capture groups: artist, title, album, year and track.
+type TaggingConfig struct {
+	TitleCleanup  []string `json:"title_cleanup"`
+	TitlePatterns []string `json:"title_patterns"`
+	CoverArt      bool     `json:"cover_art"`
+}
+
+// SitesConfig limits which sites can be downloaded from. Entries are
+// domains and also match their subdomains; an empty allow list allows every
+// site, and a denied site is refused even when it is also allowed.
+type SitesConfig struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Duration is a time.Duration written as a Go duration string in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf(consts.ERR_CONFIG_INVALID_DURATION, string(data))
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf(consts.ERR_CONFIG_INVALID_DURATION, value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:     consts.DEFAULT_PORT,
+			OpenBrowser: true,
+		},
+		Paths: PathsConfig{
+			DependenciesDir: consts.DEPENDENCIES_DIR,
+			TempDir:         filepath.Join(os.TempDir(), consts.TEMP_DIR),
+			DataDir:         consts.DATA_DIR,
+			OutputDir:       consts.DEFAULT_OUTPUT_DIR,
+		},
+		Downloads: DownloadsConfig{
+			SaveTarget:           consts.DEFAULT_SAVE_TARGET,
+			FileRetention:        Duration(consts.DEFAULT_FILE_RETENTION_MINUTES * time.Minute),
+			MaxConcurrentJobs:    consts.DEFAULT_MAX_CONCURRENT_JOBS,
+			InterruptedRetention: Duration(consts.DEFAULT_INTERRUPTED_RETENTION_HOURS * time.Hour),
+			Retry: RetryConfig{
+				MaxAttempts:  consts.DEFAULT_MAX_ATTEMPTS,
+				InitialDelay: Duration(consts.DEFAULT_RETRY_INITIAL_DELAY_SECONDS * time.Second),
+				MaxDelay:     Duration(consts.DEFAULT_RETRY_MAX_DELAY_SECONDS * time.Second),
+				Strategies:   append([]string(nil), consts.DEFAULT_RETRY_STRATEGIES...),
+			},
+		},
+		YtDlp: YtDlpConfig{
+			UserAgent:        consts.USER_AGENT_STRING,
+			GeoBypassCountry: consts.DEFAULT_GEO_BYPASS_COUNTRY,
+			DownloadArgs:     append([]string(nil), consts.YT_DLP_DOWNLOAD_ARGS...),
+			AudioArgs:        append([]string(nil), consts.YT_DLP_AUDIO_ARGS...),
+			InfoArgs:         append([]string(nil), consts.YT_DLP_INFO_ARGS...),
+			PlaylistArgs:     append([]string(nil), consts.YT_DLP_PLAYLIST_ARGS...),
+			SubtitleArgs:     append([]string(nil), consts.YT_DLP_SUBTITLE_ARGS...),
+		},
+		Tagging: TaggingConfig{
+			TitleCleanup:  append([]string(nil), consts.DEFAULT_TAG_TITLE_CLEANUP...),
+			TitlePatterns: append([]string(nil), consts.DEFAULT_TAG_TITLE_PATTERNS...),
+			CoverArt:      true,
+		},
+	}
+}
+
+// Load builds the configuration from args (without the program name).
+func Load(args []string) (*Config, error) {
+	cli := flag.NewFlagSet(consts.APP_NAME, flag.ContinueOnError)
+	configPath := cli.String(consts.CONFIG_FLAG, os.Getenv(consts.CONFIG_FILE_ENV), consts.CONFIG_FLAG_USAGE)
+	bindFlags(cli, Default())
+	if err := cli.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Flags are applied last, so remember which ones were given and replay
+	// them once the file and environment layers are in place.
+	explicit := map[string]string{}
+	cli.Visit(func(f *flag.Flag) {
+		if f.Name != consts.CONFIG_FLAG {
+			explicit[f.Name] = f.Value.String()
+		}
+	})
+
+	cfg := Default()
+	if err := loadFile(cfg, *configPath); err != nil {
+		return nil, err
+	}
+
+	layer := flag.NewFlagSet(consts.APP_NAME, flag.ContinueOnError)
+	bindFlags(layer, cfg)
+
+	var err error
+	layer.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok || err != nil {
+			return
+		}
+		if setErr := layer.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf(consts.ERR_CONFIG_INVALID_ENV, envName(f.Name), setErr)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range explicit {
+		if err := layer.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func bindFlags(fs *flag.FlagSet, cfg *Config) {
+	fs.StringVar(&cfg.Server.Address, consts.FLAG_ADDRESS, cfg.Server.Address, consts.USAGE_ADDRESS)
+	fs.BoolVar(&cfg.Server.OpenBrowser, consts.FLAG_OPEN_BROWSER, cfg.Server.OpenBrowser, consts.USAGE_OPEN_BROWSER)
+	fs.StringVar(&cfg.Paths.DependenciesDir, consts.FLAG_DEPENDENCIES_DIR, cfg.Paths.DependenciesDir, consts.USAGE_DEPENDENCIES_DIR)
+	fs.StringVar(&cfg.Paths.TempDir, consts.FLAG_TEMP_DIR, cfg.Paths.TempDir, consts.USAGE_TEMP_DIR)
+	fs.StringVar(&cfg.Paths.DataDir, consts.FLAG_DATA_DIR, cfg.Paths.DataDir, consts.USAGE_DATA_DIR)
+	fs.StringVar(&cfg.Paths.YtDlp, consts.FLAG_YT_DLP_PATH, cfg.Paths.YtDlp, consts.USAGE_YT_DLP_PATH)
+	fs.StringVar(&cfg.Paths.FFmpeg, consts.FLAG_FFMPEG_PATH, cfg.Paths.FFmpeg, consts.USAGE_FFMPEG_PATH)
+	fs.StringVar(&cfg.Paths.OutputDir, consts.FLAG_OUTPUT_DIR, cfg.Paths.OutputDir, consts.USAGE_OUTPUT_DIR)
+	fs.StringVar(&cfg.Downloads.SaveTarget, consts.FLAG_SAVE_TARGET, cfg.Downloads.SaveTarget, consts.USAGE_SAVE_TARGET+strings.Join(consts.SAVE_TARGETS, ", "))
+	fs.DurationVar((*time.Duration)(&cfg.Downloads.FileRetention), consts.FLAG_FILE_TTL, time.Duration(cfg.Downloads.FileRetention), consts.USAGE_FILE_TTL)
+	fs.IntVar(&cfg.Downloads.MaxConcurrentJobs, consts.FLAG_MAX_CONCURRENT, cfg.Downloads.MaxConcurrentJobs, consts.USAGE_MAX_CONCURRENT)
+	fs.IntVar(&cfg.Downloads.Retry.MaxAttempts, consts.FLAG_MAX_ATTEMPTS, cfg.Downloads.Retry.MaxAttempts, consts.USAGE_MAX_ATTEMPTS)
+	fs.StringVar(&cfg.YtDlp.UserAgent, consts.FLAG_USER_AGENT, cfg.YtDlp.UserAgent, consts.USAGE_USER_AGENT)
+	fs.StringVar(&cfg.YtDlp.GeoBypassCountry, consts.FLAG_GEO_BYPASS_COUNTRY, cfg.YtDlp.GeoBypassCountry, consts.USAGE_GEO_BYPASS_COUNTRY)
+	fs.StringVar(&cfg.YtDlp.Proxy, consts.FLAG_PROXY, cfg.YtDlp.Proxy, consts.USAGE_PROXY)
+	fs.Var(listValue{&cfg.Sites.Allow}, consts.FLAG_ALLOW_SITES, consts.USAGE_ALLOW_SITES)
+	fs.Var(listValue{&cfg.Sites.Deny}, consts.FLAG_DENY_SITES, consts.USAGE_DENY_SITES)
+}
+
+// listValue binds a comma-separated flag to a string slice.
+type listValue struct {
+	list *[]string
+}
+
+func (v listValue) String() string {
+	if v.list == nil {
+		return ""
+	}
+	return strings.Join(*v.list, consts.LIST_SEPARATOR)
+}
+
+func (v listValue) Set(value string) error {
+	var list []string
+	for _, item := range strings.Split(value, consts.LIST_SEPARATOR) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*v.list = list
+	return nil
+}
+
+func envName(flagName string) string {
+	return consts.CONFIG_ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// loadFile merges a JSON config file into cfg. A missing file is only an
+// error when it was asked for explicitly.
+func loadFile(cfg *Config, path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = consts.DEFAULT_CONFIG_FILE
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(consts.ERR_CONFIG_READ, path, err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf(consts.ERR_CONFIG_PARSE, path, err)
+	}
+	if cfg.YtDlp.Mp3Args != nil {
+		cfg.YtDlp.AudioArgs, cfg.YtDlp.Mp3Args = cfg.YtDlp.Mp3Args, nil
+	}
+	return nil
+}
+
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(c.Server.Address)
+	check(err == nil, consts.ERR_CONFIG_INVALID_ADDRESS, c.Server.Address)
+	check(c.Paths.DependenciesDir != "", consts.ERR_CONFIG_EMPTY, consts.CONFIG_KEY_PATHS_DEPENDENCIES_DIR)
+	check(c.Paths.TempDir != "", consts.ERR_CONFIG_EMPTY, consts.CONFIG_KEY_PATHS_TEMP_DIR)
+	check(c.Paths.DataDir != "", consts.ERR_CONFIG_EMPTY, consts.CONFIG_KEY_PATHS_DATA_DIR)
+	check(c.Paths.OutputDir != "", consts.ERR_CONFIG_EMPTY, consts.CONFIG_KEY_PATHS_OUTPUT_DIR)
+	check(isSaveTarget(c.Downloads.SaveTarget), consts.ERR_UNKNOWN_SAVE_TARGET, c.Downloads.SaveTarget)
+	check(c.Downloads.FileRetention > 0, consts.ERR_INVALID_FILE_RETENTION, time.Duration(c.Downloads.FileRetention))
+	check(c.Downloads.MaxConcurrentJobs >= 1 && c.Downloads.MaxConcurrentJobs <= consts.MAX_CONCURRENT_JOBS_LIMIT,
+		consts.ERR_INVALID_CONCURRENCY, c.Downloads.MaxConcurrentJobs, consts.MAX_CONCURRENT_JOBS_LIMIT)
+	check(c.Downloads.InterruptedRetention > 0, consts.ERR_INVALID_INTERRUPTED_RETENTION, time.Duration(c.Downloads.InterruptedRetention))
+	retry := c.Downloads.Retry
+	check(retry.MaxAttempts >= 1 && retry.MaxAttempts <= consts.MAX_ATTEMPTS_LIMIT,
+		consts.ERR_INVALID_ATTEMPTS, retry.MaxAttempts, consts.MAX_ATTEMPTS_LIMIT)
+	check(retry.InitialDelay > 0 && retry.InitialDelay <= retry.MaxDelay,
+		consts.ERR_CONFIG_INVALID_DELAYS, time.Duration(retry.InitialDelay), time.Duration(retry.MaxDelay))
+	for _, strategy := range retry.Strategies {
+		_, ok := consts.RETRY_STRATEGY_ARGS[strategy]
+		check(ok, consts.ERR_CONFIG_UNKNOWN_STRATEGY, strategy)
+	}
+	check(c.YtDlp.UserAgent != "", consts.ERR_CONFIG_EMPTY, consts.CONFIG_KEY_YT_DLP_USER_AGENT)
+	check(c.YtDlp.GeoBypassCountry == "" || len(c.YtDlp.GeoBypassCountry) == 2, consts.ERR_CONFIG_INVALID_COUNTRY, c.YtDlp.GeoBypassCountry)
+	if c.YtDlp.Proxy != "" {
+		proxy, err := url.Parse(c.YtDlp.Proxy)
+		check(err == nil && proxy.Scheme != "" && proxy.Host != "", consts.ERR_CONFIG_INVALID_PROXY)
+	}
+	for _, pattern := range append(append([]string{}, c.Tagging.TitleCleanup...), c.Tagging.TitlePatterns...) {
+		_, err := regexp.Compile(pattern)
+		check(err == nil, consts.ERR_CONFIG_INVALID_PATTERN, pattern, err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf(consts.ERR_CONFIG_INVALID, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isSaveTarget(name string) bool {
+	for _, target := range consts.SAVE_TARGETS {
+		if name == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted returns a copy that is safe to show to clients.
+func (c *Config) Redacted() Config {
+	redacted := *c
+	if proxy, err := url.Parse(c.YtDlp.Proxy); err == nil && proxy.User != nil {
+		// A user name alone can be a token, so hide all of the userinfo.
+		proxy.User = url.User(consts.REDACTED_VALUE)
+		redacted.YtDlp.Proxy = proxy.String()
+	}
+	return redacted
+}
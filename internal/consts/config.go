@@ -4,24 +4,120 @@ package consts
 const (
 	DEFAULT_PORT       = ":8484"
 	BASE_URL           = "http://localhost"
+	HTTP_SCHEME_PREFIX = "http://"
+	ANY_IPV4_HOST      = "0.0.0.0"
+	ANY_IPV6_HOST      = "::"
 	FILE_PICKER_FILTER = "Video Files|*.mp4;*.mkv;*.avi;*.mov;*.wmv;*.flv;*.webm|All Files|*.*"
 	SAVE_DIALOG_TITLE  = "Save Video As"
 )
 
+//---------- CONFIGURATION --------------
+const (
+	APP_NAME                          = "go-utilities"
+	DEFAULT_CONFIG_FILE               = "config.json"
+	CONFIG_FILE_ENV                   = "GO_UTILITIES_CONFIG"
+	CONFIG_ENV_PREFIX                 = "GO_UTILITIES_"
+	CONFIG_FLAG                       = "config"
+	CONFIG_FLAG_USAGE                 = "path to a JSON config file"
+	DEFAULT_GEO_BYPASS_COUNTRY        = "US"
+	REDACTED_VALUE                    = "REDACTED"
+	FLAG_ADDRESS                      = "address"
+	FLAG_OPEN_BROWSER                 = "open-browser"
+	FLAG_DEPENDENCIES_DIR             = "dependencies-dir"
+	FLAG_TEMP_DIR                     = "temp-dir"
+	FLAG_DATA_DIR                     = "data-dir"
+	FLAG_YT_DLP_PATH                  = "yt-dlp-path"
+	FLAG_FFMPEG_PATH                  = "ffmpeg-path"
+	FLAG_OUTPUT_DIR                   = "output-dir"
+	FLAG_SAVE_TARGET                  = "save-target"
+	FLAG_FILE_TTL                     = "file-ttl"
+	FLAG_MAX_CONCURRENT               = "max-concurrent"
+	FLAG_USER_AGENT                   = "user-agent"
+	FLAG_GEO_BYPASS_COUNTRY           = "geo-bypass-country"
+	FLAG_PROXY                        = "proxy"
+	FLAG_ALLOW_SITES                  = "allow-sites"
+	FLAG_DENY_SITES                   = "deny-sites"
+	FLAG_MAX_ATTEMPTS                 = "max-attempts"
+	USAGE_ADDRESS                     = "address the HTTP server listens on"
+	USAGE_OPEN_BROWSER                = "open the UI in a browser on start"
+	USAGE_DEPENDENCIES_DIR            = "directory containing yt-dlp and ffmpeg"
+	USAGE_TEMP_DIR                    = "directory for job workspaces"
+	USAGE_DATA_DIR                    = "directory for persistent data such as history"
+	USAGE_YT_DLP_PATH                 = "path to the yt-dlp binary, searched for when empty"
+	USAGE_FFMPEG_PATH                 = "path to the ffmpeg binary, searched for when empty"
+	USAGE_OUTPUT_DIR                  = "directory used by the directory save target"
+	USAGE_SAVE_TARGET                 = "where finished files go: "
+	USAGE_FILE_TTL                    = "how long unfetched files are kept for the browser"
+	USAGE_MAX_CONCURRENT              = "number of jobs that run at the same time"
+	USAGE_USER_AGENT                  = "user agent sent by yt-dlp"
+	USAGE_GEO_BYPASS_COUNTRY          = "two-letter country code used for geo bypass"
+	USAGE_PROXY                       = "proxy URL passed to yt-dlp"
+	USAGE_ALLOW_SITES                 = "comma-separated sites that may be downloaded from, all when empty"
+	USAGE_DENY_SITES                  = "comma-separated sites that are refused"
+	USAGE_MAX_ATTEMPTS                = "attempts a job gets when it fails for a reason that may pass, 1 to never retry"
+	LIST_SEPARATOR                    = ","
+	CONFIG_KEY_PATHS_DEPENDENCIES_DIR = "paths.dependencies_dir"
+	CONFIG_KEY_PATHS_TEMP_DIR         = "paths.temp_dir"
+	CONFIG_KEY_PATHS_DATA_DIR         = "paths.data_dir"
+	CONFIG_KEY_PATHS_OUTPUT_DIR       = "paths.output_dir"
+	CONFIG_KEY_YT_DLP_USER_AGENT      = "yt_dlp.user_agent"
+)
+
 //---------- EXECUTABLE NAMES AND FILE EXTENSIONS --------------
 const (
-	YT_DLP_EXE_NAME  = "yt-dlp.exe"
-	FFMPEG_EXE_NAME  = "ffmpeg.exe"
-	FRAGMENT_EXT     = ".f"
+	YT_DLP_BINARY    = "yt-dlp"
+	FFMPEG_BINARY    = "ffmpeg"
+	FFPROBE_BINARY   = "ffprobe"
+	EXE_SUFFIX       = ".exe"
 	TEMP_EXT         = ".temp"
+	PART_EXT         = ".part"
+	YTDL_EXT         = ".ytdl"
 	DEPENDENCIES_DIR = "dependencies"
 )
 
+//---------- DEPENDENCY SEARCH --------------
+const (
+	SOURCE_CONFIG            = "configured path"
+	SOURCE_EXECUTABLE_DIR    = "dependencies directory next to the executable"
+	SOURCE_DEPENDENCIES_DIR  = "dependencies directory"
+	SOURCE_PATH              = "PATH"
+	SOURCE_WELL_KNOWN        = "well-known install location"
+	USR_LOCAL_BIN_DIR        = "/usr/local/bin"
+	USR_BIN_DIR              = "/usr/bin"
+	SNAP_BIN_DIR             = "/snap/bin"
+	USER_LOCAL_BIN_DIR       = ".local/bin"
+	HOMEBREW_BIN_DIR         = "/opt/homebrew/bin"
+	MACPORTS_BIN_DIR         = "/opt/local/bin"
+	WINDOWS_FFMPEG_DIR       = `C:\ffmpeg\bin`
+	WINGET_LINKS_DIR         = `Microsoft\WinGet\Links`
+	PROGRAM_FILES_FFMPEG_DIR = `ffmpeg\bin`
+	CHOCOLATEY_BIN_DIR       = "bin"
+	SCOOP_SHIMS_DIR          = `scoop\shims`
+	ENV_LOCALAPPDATA         = "LOCALAPPDATA"
+	ENV_PROGRAMFILES         = "ProgramFiles"
+	ENV_CHOCOLATEY_INSTALL   = "ChocolateyInstall"
+)
+
 //---------- TEMPORARY DIRECTORY NAMES --------------
 const (
 	TEMP_DIR = "go-utilities-temp"
 )
 
+//---------- HISTORY STORE --------------
+const (
+	DATA_DIR               = "data"
+	HISTORY_FILE_NAME      = "history.jsonl"
+	JOURNAL_FILE_NAME      = "jobs.json"
+	HISTORY_MAX_LINE_BYTES = 1024 * 1024
+	HISTORY_DEFAULT_LIMIT  = 50
+	HISTORY_MAX_LIMIT      = 500
+	QUERY_PARAM_STATUS     = "status"
+	QUERY_PARAM_TYPE       = "type"
+	QUERY_PARAM_SEARCH     = "q"
+	QUERY_PARAM_LIMIT      = "limit"
+	QUERY_PARAM_OFFSET     = "offset"
+)
+
 //---------- YT-DLP CONFIGURATION --------------
 const (
 	YT_DLP_OUTPUT_FORMAT = "%(title)s.%(ext)s"
@@ -45,18 +141,80 @@ const (
 
 //---------- APPLICATION STATE CONSTANTS --------------
 const (
+	STATUS_QUEUED      = "queued"
 	STATUS_STARTING    = "starting"
 	STATUS_DOWNLOADING = "downloading"
 	STATUS_CONVERTING  = "converting"
 	STATUS_ERROR       = "error"
 	STATUS_COMPLETED   = "completed"
+	STATUS_PAUSED      = "paused"
+	STATUS_RETRYING    = "retrying"
+	STATUS_INTERRUPTED = "interrupted"
+	STATUS_CANCELLED   = "cancelled"
+)
+
+//---------- ERROR CODES --------------
+const (
+	ERROR_CODE_VIDEO_PRIVATE      = "VIDEO_PRIVATE"
+	ERROR_CODE_VIDEO_UNAVAILABLE  = "VIDEO_UNAVAILABLE"
+	ERROR_CODE_AGE_RESTRICTED     = "AGE_RESTRICTED"
+	ERROR_CODE_LOGIN_REQUIRED     = "LOGIN_REQUIRED"
+	ERROR_CODE_GEO_BLOCKED        = "GEO_BLOCKED"
+	ERROR_CODE_RATE_LIMITED       = "RATE_LIMITED"
+	ERROR_CODE_FORBIDDEN          = "FORBIDDEN"
+	ERROR_CODE_FRAGMENTS_FAILED   = "FRAGMENTS_FAILED"
+	ERROR_CODE_NETWORK_ERROR      = "NETWORK_ERROR"
+	ERROR_CODE_FORMAT_UNAVAILABLE = "FORMAT_UNAVAILABLE"
+	ERROR_CODE_UNSUPPORTED_URL    = "UNSUPPORTED_URL"
+	ERROR_CODE_DEPENDENCY_MISSING = "DEPENDENCY_MISSING"
+	ERROR_CODE_DISK_FULL          = "DISK_FULL"
+	ERROR_CODE_CANCELLED          = "CANCELLED"
+	ERROR_CODE_INVALID_REQUEST    = "INVALID_REQUEST"
+	ERROR_CODE_NOT_FOUND          = "NOT_FOUND"
+	ERROR_CODE_CONFLICT           = "CONFLICT"
+	ERROR_CODE_INTERNAL           = "INTERNAL"
+	ERROR_CODE_UNKNOWN            = "UNKNOWN"
+)
+
+//---------- JOB QUEUE --------------
+const (
+	DEFAULT_MAX_CONCURRENT_JOBS = 2
+	MAX_CONCURRENT_JOBS_LIMIT   = 16
+)
+
+//---------- JOB RETRIES --------------
+const (
+	DEFAULT_MAX_ATTEMPTS                = 3
+	MAX_ATTEMPTS_LIMIT                  = 10
+	DEFAULT_RETRY_INITIAL_DELAY_SECONDS = 5
+	DEFAULT_RETRY_MAX_DELAY_SECONDS     = 60
+	RETRY_BACKOFF_FACTOR                = 2
+	RETRY_STRATEGY_PLAYER_CLIENT        = "player_client"
+	RETRY_STRATEGY_FORCE_IPV4           = "force_ipv4"
+	RETRY_STRATEGY_FORCE_IPV6           = "force_ipv6"
+	RETRY_STRATEGY_LOWER_FORMAT         = "lower_format"
+	RETRY_PLAYER_CLIENTS                = "youtube:player_client=tv,mweb"
+	RETRY_LOWER_FORMAT_SORT             = "res:720"
+)
+
+//---------- JOB TYPES --------------
+const (
+	JOB_TYPE_VIDEO     = "video"
+	JOB_TYPE_AUDIO     = "audio"
+	JOB_TYPE_MP3       = "mp3"
+	JOB_TYPE_PLAYLIST  = "playlist"
+	JOB_TYPE_SUBTITLES = "subtitles"
 )
 
 //---------- FORMAT AND ID TEMPLATES --------------
 const (
-	DOWNLOAD_ID_FORMAT = "dl_%d"
-	MP3_ID_FORMAT      = "mp3_%d"
-	TIMESTAMP_FORMAT   = "20060102-150405"
+	DOWNLOAD_ID_FORMAT  = "dl_%s"
+	AUDIO_ID_FORMAT     = "audio_%s"
+	PLAYLIST_ID_FORMAT  = "pl_%s"
+	SUBTITLES_ID_FORMAT = "sub_%s"
+	JOB_ID_LENGTH       = 26
+	CROCKFORD_ALPHABET  = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	TIMESTAMP_FORMAT    = "20060102-150405"
 )
 
 //---------- APPLICATION DEFAULTS --------------
@@ -70,13 +228,92 @@ const (
 	COMMAND_FLAG       = "-Command"
 	EXPLORER_COMMAND   = "explorer"
 	WINDOWS_OS         = "windows"
+	DARWIN_OS          = "darwin"
+	TASKKILL_COMMAND   = "taskkill"
+	ZENITY_COMMAND     = "zenity"
+	KDIALOG_COMMAND    = "kdialog"
+	OSASCRIPT_COMMAND  = "osascript"
+	OPEN_COMMAND       = "open"
+	XDG_OPEN_COMMAND   = "xdg-open"
+)
+
+//---------- RETAINED FILES --------------
+const (
+	DEFAULT_FILE_RETENTION_MINUTES = 60
+	FILE_JANITOR_INTERVAL_SECONDS  = 60
+	CONTENT_TYPE_OCTET_STREAM      = "application/octet-stream"
+)
+
+//---------- INTERRUPTED JOBS --------------
+const (
+	DEFAULT_INTERRUPTED_RETENTION_HOURS = 7 * 24
+	// SHUTDOWN_JOB_WAIT_SECONDS bounds how long a shutdown waits for the
+	// stopped jobs to wind down before the history is closed.
+	SHUTDOWN_JOB_WAIT_SECONDS = 10
+)
+
+//---------- SAVE TARGETS --------------
+const (
+	SAVE_TARGET_AUTO             = "auto"
+	SAVE_TARGET_DIRECTORY        = "directory"
+	SAVE_TARGET_BROWSER          = "browser"
+	SAVE_TARGET_POWERSHELL       = "powershell"
+	SAVE_TARGET_ZENITY           = "zenity"
+	SAVE_TARGET_KDIALOG          = "kdialog"
+	SAVE_TARGET_OSASCRIPT        = "osascript"
+	DEFAULT_SAVE_TARGET          = SAVE_TARGET_BROWSER
+	DEFAULT_OUTPUT_DIR           = "downloads"
+	DIALOG_CANCEL_EXIT_CODE      = 1
+	INVALID_FILENAME_CHARS       = `<>:"/\|?*`
+	DUPLICATE_FILE_FORMAT        = "%s (%d)%s"
+	ZENITY_FILENAME_FLAG         = "--filename="
+	OSASCRIPT_SCRIPT_FLAG        = "-e"
+	OSASCRIPT_SAVE_DIALOG_SCRIPT = `POSIX path of (choose file name with prompt "%s" default name "%s")`
+)
+
+//---------- PROCESS CONTROL --------------
+const (
+	NTDLL_DLL                 = "ntdll.dll"
+	NT_SUSPEND_PROCESS_PROC   = "NtSuspendProcess"
+	NT_RESUME_PROCESS_PROC    = "NtResumeProcess"
+	PROCESS_SUSPEND_RESUME    = 0x0800
+	TH32CS_SNAPPROCESS        = 0x00000002
 )
 
 //---------- YT-DLP COMMAND OPTIONS --------------
 const (
-	FFMPEG_LOCATION_FLAG = "--ffmpeg-location"
-	YT_DLP_VERSION_FLAG  = "--version"
-	FORMAT_FLAG          = "-f"
+	FFMPEG_LOCATION_FLAG     = "--ffmpeg-location"
+	YT_DLP_VERSION_FLAG      = "--version"
+	FFMPEG_VERSION_FLAG      = "-version"
+	FORMAT_FLAG              = "-f"
+	USER_AGENT_FLAG          = "--user-agent"
+	GEO_BYPASS_COUNTRY_FLAG  = "--geo-bypass-country"
+	PROXY_FLAG               = "--proxy"
+	MERGE_OUTPUT_FORMAT_FLAG = "--merge-output-format"
+	WRITE_SUBS_FLAG          = "--write-subs"
+	WRITE_AUTO_SUBS_FLAG     = "--write-auto-subs"
+	SUB_LANGS_FLAG           = "--sub-langs"
+	CONVERT_SUBS_FLAG        = "--convert-subs"
+	EMBED_SUBS_FLAG          = "--embed-subs"
+	EXTRACTOR_ARGS_FLAG      = "--extractor-args"
+	FORCE_IPV4_FLAG          = "--force-ipv4"
+	FORCE_IPV6_FLAG          = "--force-ipv6"
+	FORMAT_SORT_FLAG         = "-S"
+)
+
+//---------- SUBTITLES --------------
+const (
+	SUBTITLE_MODE_EMBED     = "embed"
+	SUBTITLE_MODE_SIDECAR   = "sidecar"
+	SUBTITLE_FORMAT_SRT     = "srt"
+	SUBTITLE_FORMAT_VTT     = "vtt"
+	SUBTITLE_FORMAT_ASS     = "ass"
+	CONTAINER_MP4           = "mp4"
+	CONTAINER_MKV           = "mkv"
+	CONTAINER_WEBM          = "webm"
+	LIVE_CHAT_LANGUAGE      = "live_chat"
+	SUBTITLE_LANGUAGE_REGEX = `^-?[\w.*-]+$`
+	BUNDLE_EXT              = ".zip"
 )
 
 //---------- YT-DLP FORMAT STRINGS --------------
@@ -84,21 +321,74 @@ const (
 	QUALITY_SUFFIX                 = "p"
 	QUALITY_HEIGHT_FORMAT          = "bestvideo[height<=%s][ext=mp4]+bestaudio[ext=m4a]/bestvideo[height<=%s]+bestaudio/best[height<=%s]"
 	QUALITY_CUSTOM_FORMAT          = "(%s+bestaudio[ext=m4a])/(%s+bestaudio)/%s/best"
+	FORMAT_MERGE_FORMAT            = "%s+%s"
+	FORMAT_BEST_VIDEO_WITH_AUDIO   = "bestvideo+%s/best"
+	FORMAT_BEST_VIDEO              = "bestvideo"
+	FORMAT_BEST_AUDIO              = "bestaudio"
+	FORMAT_BEST                    = "best"
+	FORMAT_ALTERNATIVE_SEPARATOR   = "/"
+	FORMAT_HEIGHT_FILTER           = "[height<=?%d]"
+	FORMAT_FPS_FILTER              = "[fps<=?%d]"
+	FORMAT_SDR_FILTER              = "[dynamic_range=?SDR]"
+	FORMAT_CODEC_FILTER            = "[vcodec~='%s']"
+	FORMAT_EXT_FILTER              = "[ext=%s]"
+	FORMAT_SIZE_FILTER             = "[filesize<?%s][filesize_approx<?%s]"
 	QUALITY_BEST_FORMAT            = "bestvideo[height>=1080]+bestaudio[ext=m4a]/bestvideo[height>=1080]+bestaudio/bestvideo[height>=720][fps>=30]+bestaudio[ext=m4a]/bestvideo[height>=720][fps>=30]+bestaudio/bestvideo[height>=720]+bestaudio[ext=m4a]/bestvideo[height>=720]+bestaudio/best[height>=720]/best"
 )
 
+//---------- QUALITY PREFERENCES --------------
+const (
+	VIDEO_CODEC_H264   = "h264"
+	VIDEO_CODEC_VP9    = "vp9"
+	VIDEO_CODEC_AV1    = "av1"
+	MAX_FILESIZE_REGEX = `^[0-9]+(\.[0-9]+)?[kKmMgG]$`
+	// MAX_FILESIZE_AUDIO_SHARE is the part of a maximum file size left to
+	// the audio stream when video and audio are merged into one file.
+	MAX_FILESIZE_AUDIO_SHARE = 0.2
+)
+
 //---------- URL PATTERNS AND COMPONENTS --------------
 const (
-	YOUTUBE_DOMAIN     = "youtube.com"
-	YOUTU_BE_DOMAIN    = "youtu.be"
-	YOUTUBE_EMBED_PATH = "/embed/"
-	URL_PATH_SEPARATOR = "/"
-	URL_VIDEO_PARAM    = "v"
+	YOUTUBE_DOMAIN        = "youtube.com"
+	YOUTUBE_EXTRACTOR_KEY = "Youtube"
+	YOUTU_BE_DOMAIN       = "youtu.be"
+	URL_PATH_SEPARATOR    = "/"
+	URL_VIDEO_PARAM       = "v"
+	URL_LIST_PARAM        = "list"
+	DEFAULT_CHANNEL_TAB   = "videos"
+	HTTP_SCHEME           = "http"
+	HTTPS_SCHEME          = "https"
+	SCHEME_SEPARATOR      = "://"
+	SUBDOMAIN_SEPARATOR   = "."
+	WILDCARD_PREFIX       = "*."
 )
 
 //---------- VERSION AND VALIDATION --------------
 const (
-	MIN_YTDLP_YEAR = "2024"
+	YT_DLP_MAX_AGE_DAYS  = 180
+	YT_DLP_VERSION_REGEX = `(\d{4})\.(\d{2})\.(\d{2})`
+	FFMPEG_VERSION_REGEX = `version n?(\d+)\.(\d+)(?:\.(\d+))?`
+	DATE_VERSION_FORMAT  = "%s-%s-%s"
+	DATE_VERSION_LAYOUT  = "2006-01-02"
+	SEMVER_FORMAT        = "%s.%s.%s"
+)
+
+//---------- HEALTH CHECKS --------------
+const (
+	CHECK_PASS                  = "pass"
+	CHECK_WARN                  = "warn"
+	CHECK_FAIL                  = "fail"
+	CHECK_ENCODERS              = "encoders"
+	CHECK_DISK_PREFIX           = "disk:"
+	DISK_TEMP                   = "temp"
+	DISK_OUTPUT                 = "output"
+	MIN_FREE_DISK_BYTES         = 512 * 1024 * 1024
+	LOW_FREE_DISK_BYTES         = 5 * 1024 * 1024 * 1024
+	FFMPEG_HIDE_BANNER_FLAG     = "-hide_banner"
+	FFMPEG_ENCODERS_FLAG        = "-encoders"
+	FFMPEG_LIST_SEPARATOR       = "------"
+	KERNEL32_DLL                = "kernel32.dll"
+	GET_DISK_FREE_SPACE_EX_PROC = "GetDiskFreeSpaceExW"
 )
 
 //---------- FILE SIZE FORMATTING --------------
@@ -108,24 +398,24 @@ const (
 	APPROX_PREFIX   = "~"
 )
 
-//---------- VIDEO INFO JSON FIELDS --------------
-const (
-	JSON_TITLE            = "title"
-	JSON_DURATION         = "duration"
-	JSON_THUMBNAIL        = "thumbnail"
-	JSON_FORMATS          = "formats"
-	JSON_HEIGHT           = "height"
-	JSON_VCODEC           = "vcodec"
-	JSON_ACODEC           = "acodec"
-	JSON_EXT              = "ext"
-	JSON_FORMAT_ID        = "format_id"
-	JSON_TBR              = "tbr"
-	JSON_FILESIZE         = "filesize"
-	JSON_FILESIZE_APPROX  = "filesize_approx"
-	VCODEC_NONE           = "none"
+//---------- VIDEO INFO FORMATTING --------------
+const (
+	YT_DLP_DATE_LAYOUT    = "20060102"
+	UPLOAD_DATE_LAYOUT    = "2006-01-02"
+	CODEC_NONE            = "none"
 	RESOLUTION_UNKNOWN    = "unknown"
 	RESOLUTION_FORMAT     = "%dp"
 	DURATION_FORMAT       = "%d:%02d"
+	FPS_LABEL_FORMAT      = "%s%d"
+	HDR_LABEL_FORMAT      = "%s %s"
+	DYNAMIC_RANGE_SDR     = "SDR"
+	HIGH_FPS_THRESHOLD    = 30
+	FORMAT_KIND_VIDEO     = "video"
+	FORMAT_KIND_AUDIO     = "audio"
+	FORMAT_KIND_MUXED     = "video+audio"
+	FORMAT_ID_REGEX       = `^[\w.-]+$`
+	BITS_PER_BYTE         = 8
+	KILOBIT               = 1000
 )
 
 //---------- HTTP ROUTES AND PATHS --------------
@@ -136,8 +426,31 @@ const (
 	SHUTDOWN_ROUTE            = "/shutdown"
 	API_ROUTE_PREFIX          = "/api"
 	DOWNLOAD_ROUTE            = "/download"
+	AUDIO_EXTRACT_ROUTE       = "/audio-extract"
 	MP3_CONVERT_ROUTE         = "/mp3-convert"
 	VIDEO_INFO_ROUTE          = "/video-info"
+	CANCEL_ROUTE              = "/cancel"
+	PAUSE_ROUTE               = "/pause"
+	RESUME_ROUTE              = "/resume"
+	HISTORY_ROUTE             = "/history"
+	HEALTH_ROUTE              = "/health"
+	DIAGNOSTICS_ROUTE         = "/diagnostics"
+	CONFIG_ROUTE              = "/config"
+	QUEUE_ROUTE               = "/queue"
+	QUEUE_MOVE_ROUTE          = "/queue/move"
+	QUEUE_PRIORITY_ROUTE      = "/queue/priority"
+	QUEUE_REMOVE_ROUTE        = "/queue/remove"
+	QUEUE_CONCURRENCY_ROUTE   = "/queue/concurrency"
+	PLAYLIST_INFO_ROUTE       = "/playlist-info"
+	PLAYLIST_DOWNLOAD_ROUTE   = "/playlist-download"
+	SUBTITLES_ROUTE           = "/subtitles"
+	RETRY_ROUTE               = "/retry"
+	JOB_ROUTE                 = "/jobs/{id}"
+	JOB_FILE_ROUTE            = "/jobs/{id}/file"
+	INTERRUPTED_ROUTE         = "/interrupted"
+	INTERRUPTED_RESUME_ROUTE  = "/interrupted/resume"
+	INTERRUPTED_DISCARD_ROUTE = "/interrupted/discard"
+	JOB_FILE_URL_FORMAT       = "/api/jobs/%s/file"
 	WEBSOCKET_ROUTE           = "/ws"
 	TEMPLATE_PATH             = "static/html/index.html"
 	SHUTDOWN_TEMPLATE_PATH    = "static/html/shutdown.html"
@@ -147,6 +460,7 @@ const (
 const (
 	HTTP_GET  = "GET"
 	HTTP_POST = "POST"
+	HTTP_HEAD = "HEAD"
 )
 
 //---------- HTTP HEADERS --------------
@@ -154,6 +468,8 @@ const (
 	CONTENT_TYPE_JSON = "application/json"
 	CONTENT_TYPE_HTML = "text/html"
 	HEADER_CONTENT_TYPE = "Content-Type"
+	HEADER_CONTENT_DISPOSITION = "Content-Disposition"
+	CONTENT_DISPOSITION_ATTACHMENT = "attachment; filename=\"%s\"; filename*=UTF-8''%s"
 )
 
 //---------- WEBSOCKET CONFIGURATION --------------
@@ -161,6 +477,11 @@ const (
 	SHUTDOWN_SIGNAL_BUFFER = 10
 	SHUTDOWN_DELAY_MS      = 500
 	WS_MESSAGE_TYPE_SHUTDOWN = "shutdown"
+	WS_MESSAGE_TYPE_SNAPSHOT  = "snapshot"
+	WS_MESSAGE_TYPE_SUBSCRIBE = "subscribe"
+	// SUBSCRIBER_BUFFER_SIZE is how many updates wait for a client before
+	// its updates are coalesced to the latest state of each job.
+	SUBSCRIBER_BUFFER_SIZE = 100
 )
 
 //---------- BROWSER PATHS --------------
@@ -206,7 +527,130 @@ if ($saveFileDialog.ShowDialog() -eq [System.Windows.Forms.DialogResult]::OK) {
 
 //---------- YT-DLP OUTPUT PARSING PATTERNS --------------
 const (
-	YT_DLP_PROGRESS_REGEX_WITH_SPEED = `\[download\]\s+(\d+\.?\d*)%\s+of\s+.*?\s+at\s+(\S+)\s+ETA\s+(\S+)`
-	YT_DLP_PROGRESS_REGEX_SIMPLE     = `\[download\]\s+(\d+\.?\d*)%`
-	YT_DLP_TITLE_REGEX               = `\[download\] Destination: (.+)`
+	YT_DLP_TITLE_REGEX             = `\[download\] Destination: (.+)`
+	YT_DLP_PROGRESS_NA_REGEX       = `:\s*NA\s*([,}])`
+	YT_DLP_PROGRESS_NA_REPLACEMENT = `:null$1`
+	YT_DLP_FRAGMENT_FILE_REGEX     = `\.f\d+\.`
+)
+
+//---------- YT-DLP PROCESS --------------
+const (
+	YT_DLP_EXIT_CODE_MAX_DOWNLOADS = 101
+	STDERR_TAIL_LINES              = 20
+	YT_DLP_MAX_LINE_BYTES          = 16 * 1024 * 1024
+	AUDIO_FAILURE_OUTPUT_LINES     = 5
+	AUDIO_OUTPUT_TAIL_LINES        = 20
+)
+
+//---------- YT-DLP PROGRESS TEMPLATE --------------
+const (
+	YT_DLP_PROGRESS_TEMPLATE_FLAG      = "--progress-template"
+	YT_DLP_DOWNLOAD_PROGRESS_PREFIX    = "[goutil:download]"
+	YT_DLP_POSTPROCESS_PROGRESS_PREFIX = "[goutil:postprocess]"
+	YT_DLP_DOWNLOAD_PROGRESS_TEMPLATE  = "download:" + YT_DLP_DOWNLOAD_PROGRESS_PREFIX + ` {"progress":%(progress)j,"vcodec":%(info.vcodec)j,"acodec":%(info.acodec)j,"format_id":%(info.format_id)j}`
+	YT_DLP_POSTPROCESS_PROGRESS_TEMPLATE = "postprocess:" + YT_DLP_POSTPROCESS_PROGRESS_PREFIX + ` {"status":%(progress.status)j,"postprocessor":%(progress.postprocessor)j}`
+	YT_DLP_PROGRESS_STATUS_FINISHED    = "finished"
+	YT_DLP_PP_MERGER                   = "Merger"
+	YT_DLP_PP_EXTRACT_AUDIO            = "ExtractAudio"
+	YT_DLP_PP_METADATA                 = "Metadata"
+	YT_DLP_PP_EMBED_THUMBNAIL          = "EmbedThumbnail"
+	YT_DLP_PP_SPLIT_CHAPTERS           = "SplitChapters"
+	SPEED_SUFFIX                       = "/s"
+	ETA_HOURS_FORMAT                   = "%d:%02d:%02d"
+)
+
+//---------- PROGRESS PHASES --------------
+const (
+	PHASE_DOWNLOAD_VIDEO = "download_video"
+	PHASE_DOWNLOAD_AUDIO = "download_audio"
+	PHASE_MERGE          = "merge"
+	PHASE_EXTRACT_AUDIO  = "extract_audio"
+	PHASE_EMBED_METADATA = "embed_metadata"
+	PHASE_POSTPROCESS    = "postprocess"
+	PHASE_CLIP           = "clip"
+	PHASE_SPLIT_CHAPTERS = "split_chapters"
+)
+
+//---------- CLIPS --------------
+// Clip names carry their section in seconds so a clip that yt-dlp could not
+// cut can still be trimmed afterwards.
+const (
+	YT_DLP_CLIP_OUTPUT_FORMAT    = "%(title)s [%(section_start)s-%(section_end)s].%(ext)s"
+	DOWNLOAD_SECTIONS_FLAG       = "--download-sections"
+	FORCE_KEYFRAMES_FLAG         = "--force-keyframes-at-cuts"
+	CLIP_RANGE_FORMAT            = "*%s-%s"
+	CLIP_CHAPTER_FORMAT          = "^%s$"
+	CLIP_OPEN_END                = "inf"
+	CLIP_NAME_REGEX              = `^(.*) \[(\d+(?:\.\d+)?)-(\d+(?:\.\d+)?|inf)\]$`
+	CLIP_NAME_FORMAT             = "%s [%s-%s]%s"
+	CLIP_TIME_FORMAT             = "%02d.%02d.%02d"
+	CLIP_TIME_END                = "end"
+	CLIP_TRIM_SUFFIX             = ".trim"
+	CLIP_DURATION_TOLERANCE      = 2.0
+	FFMPEG_PROGRESS_OUT_TIME     = "out_time_us="
+	FFMPEG_PROGRESS_OUT_TIME_OLD = "out_time_ms="
+	FFMPEG_SEEK_FLAG             = "-ss"
+	FFMPEG_INPUT_FLAG            = "-i"
+	FFMPEG_DURATION_FLAG         = "-t"
+	TIMESTAMP_SEPARATOR          = ":"
+)
+
+//---------- AUDIO EXTRACTION --------------
+// "original" keeps the downloaded audio stream as it is; yt-dlp's "best"
+// audio format skips re-encoding whenever the stream can be copied.
+const (
+	AUDIO_FORMAT_MP3         = "mp3"
+	AUDIO_FORMAT_OPUS        = "opus"
+	AUDIO_FORMAT_ORIGINAL    = "original"
+	YT_DLP_AUDIO_FORMAT_BEST = "best"
+	AUDIO_QUALITY_BEST       = "0"
+	AUDIO_QUALITY_REGEX      = `^(10|[0-9]|[1-9][0-9]{1,2}[kK])$`
+	AUDIO_MAX_CHANNELS       = 2
+	AUDIO_LABEL_FORMAT       = "%s %s"
+	EXTRACT_AUDIO_FLAG       = "-x"
+	AUDIO_FORMAT_FLAG        = "--audio-format"
+	AUDIO_QUALITY_FLAG       = "--audio-quality"
+	POSTPROCESSOR_ARGS_FLAG  = "--postprocessor-args"
+	EXTRACT_AUDIO_PP_ARGS    = "ExtractAudio:%s"
+	FFMPEG_SAMPLE_RATE_FLAG  = "-ar"
+	FFMPEG_CHANNELS_FLAG     = "-ac"
+)
+
+//---------- CHAPTERS --------------
+// Split chapters go to their own directory, numbered so they sort in order
+// and can be tagged as album tracks afterwards.
+const (
+	CHAPTER_MODE_EMBED           = "embed"
+	CHAPTER_MODE_SPLIT           = "split"
+	EMBED_CHAPTERS_FLAG          = "--embed-chapters"
+	SPLIT_CHAPTERS_FLAG          = "--split-chapters"
+	YT_DLP_CHAPTER_OUTPUT_TYPE   = "chapter:"
+	YT_DLP_CHAPTER_OUTPUT_FORMAT = "%(title)s - %(section_number)03d - %(section_title)s.%(ext)s"
+	CHAPTERS_DIR                 = "chapters"
+	CHAPTER_NAME_REGEX           = `^(.*) - (\d{3,}) - (.*)$`
+)
+
+//---------- AUDIO TAGGING --------------
+// Title patterns fill the tags named by their capture groups. Tagging reads
+// the info JSON and thumbnail yt-dlp writes next to the audio.
+const (
+	TAG_GROUP_ARTIST     = "artist"
+	TAG_GROUP_TITLE      = "title"
+	TAG_GROUP_ALBUM      = "album"
+	TAG_GROUP_YEAR       = "year"
+	TAG_GROUP_TRACK      = "track"
+	TAG_YEAR_REGEX       = `^\d{4}$`
+	TAG_TRACK_REGEX      = `^\d+(/\d+)?$`
+	TAG_TRACK_FORMAT     = "%d/%d"
+	TOPIC_CHANNEL_SUFFIX = " - Topic"
+	INFO_JSON_GLOB       = "*.info.json"
+	THUMBNAIL_GLOB       = "*.jpg"
+	TAGGED_FILE_SUFFIX   = ".tagged"
+	FFMPEG_METADATA_FLAG = "-metadata"
+	FFMPEG_TAG_TITLE     = "title=%s"
+	FFMPEG_TAG_ARTIST    = "artist=%s"
+	FFMPEG_TAG_ALBUM     = "album=%s"
+	FFMPEG_TAG_DATE      = "date=%s"
+	FFMPEG_TAG_TRACK     = "track=%s"
+	MP3_EXT              = ".mp3"
 )
\ No newline at end of file
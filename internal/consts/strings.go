@@ -2,15 +2,11 @@ package consts
 
 // ---------- LOG MESSAGES - INFORMATIONAL --------------
 const (
-	LOG_YT_DLP_VERSION           = "yt-dlp version: %s"
 	LOG_PATH                     = "Path: %s"
 	LOG_FULL_ARGS                = "Full args: %v"
 	LOG_QUALITY_REQUESTED        = "Quality requested: %s"
 	LOG_URL                      = "URL: %s"
-	LOG_URL_MP3                  = "URL: %s -> %s"
-	LOG_TEMP_DIR_MP3             = "Temp dir: %s"
 	LOG_DOWNLOADING_COMMAND      = "=== DOWNLOADING WITH COMMAND ==="
-	LOG_CONVERTING_TO_MP3_PATH   = "=== CONVERTING TO MP3 === | Path: %s"
 	LOG_RAW_VIDEO_INFO           = "=== RAW VIDEO INFO OUTPUT ==="
 	LOG_AVAILABLE_FORMATS_TOTAL  = "=== AVAILABLE FORMATS === | Total formats found: %d"
 	LOG_RAW_VIDEO_INFO_LENGTH    = "=== RAW VIDEO INFO OUTPUT === | Output length: %d bytes"
@@ -19,11 +15,9 @@ const (
 	LOG_YT_DLP_INFO_FAILED_STDERR = "yt-dlp video info command failed: %v | yt-dlp stderr: %s"
 	LOG_FORMAT_DETAILS           = "Format %d: height=%v, vcodec=%v, acodec=%v, ext=%v, format_id=%v, tbr=%v"
 	LOG_FOUND_VIDEO_FORMAT       = "Found video format: %s (format_id: %v)"
-	LOG_GETTING_VIDEO_INFO       = "Getting video info with command: %s -j --no-warnings %s"
 	LOG_BROADCASTING_UPDATE      = "Broadcasting update: %+v"
 	LOG_BROADCASTING_SUBSCRIBERS = "Broadcasting to %d subscribers"
-	LOG_SENT_UPDATE              = "Sent update to subscriber %d"
-	LOG_SUBSCRIBER_CHANNEL_FULL  = "Subscriber %d channel full, skipping"
+	LOG_SUBSCRIBER_BEHIND        = "Subscriber is behind, coalescing updates of job %s"
 	LOG_SERVER_STARTING          = "Server starting on %s"
 	LOG_UNSUPPORTED_PLATFORM     = "Unsupported platform, please open %s manually"
 	LOG_OPEN_MANUALLY            = "Please open %s manually"
@@ -31,14 +25,34 @@ const (
 	LOG_RECEIVED_SIGNAL          = "Received signal: %v. Shutting down gracefully..."
 	LOG_SHUTDOWN_COMPLETE        = "Server shutdown complete"
 	LOG_OPENING_FILE_EXPLORER    = "Opening File Explorer..."
+	LOG_CANCELLING_JOB           = "Cancelling job %s"
+	LOG_PAUSING_JOB              = "Pausing job %s"
+	LOG_RESUMING_JOB             = "Resuming job %s"
+	LOG_REMOVED_TEMP_FILE        = "Removed temp file: %s"
+	LOG_HISTORY_LOADED           = "Loaded %d history entries from %s"
+	LOG_JOB_DEQUEUED             = "Starting queued job %s"
+	LOG_JOB_REMOVED_FROM_QUEUE   = "Removed job %s from queue"
+	LOG_CONCURRENCY_CHANGED      = "Max concurrent jobs set to %d"
+	LOG_WORKSPACE_CREATED        = "Created workspace for job %s: %s"
+	LOG_WORKSPACE_REMOVED        = "Removed workspace for job %s: %s"
+	LOG_DEPENDENCY_RESOLVED      = "Using %s %s at %s (found via %s)"
+	LOG_SAVE_TARGET_SELECTED     = "Save target: %s (%s)"
+	LOG_SAVE_DIALOG_FAILED       = "%s save dialog failed: %v"
+	LOG_OPEN_FILE_EXPLORER_FAILED = "Failed to open file explorer: %v"
+	LOG_JOB_FILE_SERVED          = "Served file for job %s: %s"
+	LOG_JOB_FILE_EXPIRED         = "Retained file for job %s expired before it was fetched"
 )
 
 // ---------- LOG MESSAGES - WARNINGS --------------
 const (
-	WARNING_YT_DLP_OUTDATED      = "WARNING: yt-dlp version may be outdated. Consider updating from https://github.com/yt-dlp/yt-dlp/releases"
 	WARNING_FFMPEG_NOT_FOUND     = "Warning: FFmpeg not found, audio merging may not work: %v"
-	WARNING_FFMPEG_NOT_FOUND_MP3 = "Warning: FFmpeg not found, MP3 conversion may not work: %v"
-	LOG_YT_DLP_TEST_FAILED       = "WARNING: yt-dlp test failed: %v"
+	WARNING_FFMPEG_MISSING_AUDIO = "Warning: FFmpeg not found, audio extraction may not work: %v"
+	WARNING_HEALTH_CHECK         = "WARNING: %s check %s: %s"
+	WARNING_HISTORY_BAD_LINE     = "WARNING: skipping unreadable history entry: %v"
+	WARNING_HISTORY_UNAVAILABLE  = "WARNING: history store unavailable, history will not persist: %v"
+	WARNING_HISTORY_SAVE_FAILED  = "WARNING: failed to save history entry: %v"
+	WARNING_JOURNAL_UNAVAILABLE  = "WARNING: job journal unavailable, interrupted jobs cannot be resumed: %v"
+	WARNING_JOURNAL_SAVE_FAILED  = "WARNING: failed to save job journal: %v"
 )
 
 // ---------- LOG MESSAGES - WEBSOCKET --------------
@@ -47,6 +61,9 @@ const (
 	LOG_WS_CONNECTION_ESTABLISHED = "WebSocket connection established"
 	LOG_SENDING_WS_UPDATE         = "Sending WebSocket update: %+v"
 	LOG_WS_WRITE_ERROR            = "WebSocket write error: %v"
+	LOG_WS_CONNECTION_CLOSED      = "WebSocket connection closed: %v"
+	LOG_WS_SUBSCRIBED             = "WebSocket client subscribed to jobs %v"
+	LOG_WS_INVALID_MESSAGE        = "Ignoring WebSocket message of type %q"
 	LOG_SENDING_SHUTDOWN_TO_WS    = "Sending shutdown signal to WebSocket client"
 	LOG_SENDING_SHUTDOWN_SIGNAL   = "Sending shutdown signal to all WebSocket clients..."
 	LOG_SHUTDOWN_SIGNAL_SENT      = "Shutdown signal sent"
@@ -56,9 +73,9 @@ const (
 // ---------- LOG MESSAGES - PROCESS OUTPUT --------------
 const (
 	LOG_YT_DLP_STDERR         = "yt-dlp stderr: %s"
-	LOG_YT_DLP_MP3_STDERR     = "yt-dlp MP3 stderr: %s"
 	LOG_YT_DLP_STDOUT         = "yt-dlp stdout: %s"
-	LOG_CMD_WAIT_FAILED_OUTPUT = "cmd.Wait() failed with error: %v | Full stdout output: %v"
+	LOG_YT_DLP_OUTPUT_SKIPPED = "Skipping the rest of yt-dlp output: %v"
+	LOG_CMD_WAIT_FAILED_OUTPUT = "cmd.Wait() failed with error: %v | Last stdout lines: %v"
 	LOG_EXIT_CODE_STDERR      = "Exit code: %d, stderr: %s"
 	LOG_EXIT_CODE_101_SUCCESS = "yt-dlp exit code 101 due to --max-downloads or existing file, treating as success"
 	LOG_PROCESS_COMPLETED_LOOKING = "yt-dlp process completed successfully | Looking for converted file in: %s"
@@ -67,13 +84,22 @@ const (
 
 // ---------- LOG MESSAGES - DOWNLOAD/CONVERSION PROCESS --------------
 const (
-	LOG_DOWNLOAD_COMMAND_INFO    = "=== DOWNLOADING WITH COMMAND === | Path: %s | Full args: %v | Quality requested: %s | URL: %s"
+	LOG_YT_DLP_COMMAND           = "=== RUNNING YT-DLP === | Path: %s | Full args: %v"
 	LOG_STARTING_DOWNLOAD        = "Starting download for URL: %s, Quality: %s"
 	LOG_DOWNLOAD_STARTED         = "Download started with ID: %s"
-	LOG_STARTING_MP3_CONVERSION  = "Starting MP3 conversion for URL: %s"
-	LOG_MP3_CONVERSION_STARTED   = "MP3 conversion started with ID: %s"
+	LOG_STARTING_AUDIO_JOB       = "Starting audio extraction for URL: %s"
+	LOG_AUDIO_JOB_STARTED        = "Audio extraction started with ID: %s"
+	LOG_PLAYLIST_STARTED         = "Playlist %s started with %d items"
+	LOG_RETRYING_JOB             = "Retrying job %s (retry %d)"
+	LOG_INTERRUPTED_JOBS_FOUND   = "Found %d jobs interrupted by the last shutdown"
+	LOG_RESUMING_INTERRUPTED     = "Resuming interrupted job %s"
+	LOG_DISCARDING_INTERRUPTED   = "Discarding interrupted job %s"
+	LOG_JOB_STOPPED_AT_SHUTDOWN  = "Job %s stopped by the shutdown, kept for the next run"
+	LOG_SHUTDOWN_JOBS_TIMEOUT    = "Gave up waiting for stopped jobs after %s"
+	LOG_ATTEMPT_FAILED           = "Attempt %d of %d of job %s failed with %s, retrying in %s"
 	LOG_INVALID_REQUEST_BODY     = "Invalid request body: %v"
-	LOG_INVALID_REQUEST_BODY_MP3 = "Invalid request body: %v"
+	LOG_INVALID_AUDIO_REQUEST    = "Invalid request body: %v"
+	LOG_JOB_CONTROL_FAILED       = "Job control failed: %v"
 	LOG_TEMPLATE_ERROR           = "Template error: %v"
 	LOG_TEMPLATE_EXECUTION_ERROR = "Template execution error: %v"
 )
@@ -82,77 +108,247 @@ const (
 const (
 	MSG_STARTING_DOWNLOAD       = "Starting download..."
 	MSG_DOWNLOAD_COMPLETE       = "Download completed, processing..."
-	MSG_CONVERTING_VIDEO        = "Converting video..."
-	MSG_STARTING_MP3_CONVERSION = "Starting MP3 conversion..."
-	MSG_MP3_CONVERSION_COMPLETE = "MP3 conversion completed, processing..."
-	MSG_CONVERTING_TO_MP3       = "Converting to MP3..."
+	MSG_EXTRACTING_AUDIO        = "Starting audio extraction..."
+	MSG_AUDIO_EXTRACT_COMPLETED = "Audio extraction completed, processing..."
 	MSG_SAVED_AS                = "Saved as: %s"
-	MSG_MP3_SAVED_AS            = "MP3 saved as: %s"
+	MSG_AUDIO_SAVED_AS          = "Audio saved as: %s"
+	MSG_PHASE_DOWNLOAD_VIDEO    = "Downloading video..."
+	MSG_PHASE_DOWNLOAD_AUDIO    = "Downloading audio..."
+	MSG_PHASE_MERGE             = "Merging video and audio..."
+	MSG_PHASE_EXTRACT_AUDIO     = "Extracting audio..."
+	MSG_PHASE_EMBED_METADATA    = "Embedding metadata..."
+	MSG_PHASE_POSTPROCESS       = "Post-processing..."
+	MSG_PHASE_SPLIT_CHAPTERS    = "Splitting into chapters..."
+	MSG_JOB_PAUSED              = "Paused"
+	MSG_JOB_RESUMED             = "Resumed"
+	MSG_JOB_CANCELLED           = "Cancelled"
+	MSG_QUEUED_POSITION         = "Queued (%d of %d)"
+	MSG_READY_TO_FETCH          = "Ready, downloading to your browser..."
+	MSG_PLAYLIST_PROGRESS       = "%d of %d items finished"
+	MSG_PLAYLIST_ITEMS_FAILED   = "%d of %d items failed"
+	MSG_JOB_RETRYING            = "Queued for retry %d"
+	MSG_RETRYING_IN             = "Attempt %d of %d failed: %s. Retrying in %ds..."
+	MSG_STARTING_ATTEMPT        = "Starting attempt %d of %d..."
 )
 
 // ---------- USER NOTIFICATION MESSAGES --------------
 const (
-	DUPLICATE_MSG         = "Duplicate file detected. Saving as: %s"
-	POWERSHELL_FAILED     = "PowerShell SaveFileDialog failed: %v"
-	MP3_CONVERSION_FAILED = "MP3 conversion failed: %v"
+	DUPLICATE_MSG        = "Duplicate file detected. Saving as: %s"
+	AUDIO_EXTRACT_FAILED = "Audio extraction failed: %v"
 )
 
 // ---------- ERROR MESSAGES - FILE SYSTEM --------------
 const (
-	ERR_YT_DLP_NOT_FOUND     = "yt-dlp.exe not found at %s"
-	ERR_FFMPEG_NOT_FOUND     = "ffmpeg.exe not found at %s"
+	ERR_DEPENDENCY_NOT_FOUND = "%s not found (searched: %s)"
+	ERR_DEPENDENCY_UNUSABLE  = "%s is set to %s in the config, but that is not an executable file"
 	ERR_NO_VIDEO_FILE        = "no video file found in %s"
 	ERR_CREATE_TEMP_DIR      = "Failed to create temp directory: %v"
 	ERR_RENAME_FILE          = "Failed to rename file with resolution: %v"
 	ERR_SAVE_FILE            = "Failed to save file: %v"
-	ERR_SAVE_MP3_FILE        = "Failed to save MP3 file: %v"
+	ERR_SAVE_AUDIO_FILE      = "Failed to save audio file: %v"
 	ERR_SAVE_FILE_PICKER     = "failed to save file: %v"
 	ERR_SAVE_CANCELLED       = "save cancelled by user"
+	ERR_UNKNOWN_SAVE_TARGET  = "unknown save target %q"
 	ERR_FIND_DOWNLOADED_FILE = "Could not find downloaded file: %v"
-	ERR_FIND_MP3_FILE        = "Could not find converted MP3 file: %v"
+	ERR_FIND_AUDIO_FILE      = "Could not find extracted audio file: %v"
+	ERR_HISTORY_OPEN         = "failed to open history store %s: %v"
+	ERR_HISTORY_WRITE        = "failed to write history store: %v"
+	ERR_JOURNAL_READ         = "failed to read job journal %s: %v"
+	ERR_JOURNAL_WRITE        = "failed to write job journal: %v"
 )
 
 // ---------- ERROR MESSAGES - PROCESS EXECUTION --------------
 const (
-	ERR_CREATE_STDOUT_PIPE     = "Failed to create stdout pipe: %v"
-	ERR_CREATE_STDERR_PIPE     = "Failed to create stderr pipe: %v"
-	ERR_CREATE_STDOUT_PIPE_MP3 = "Failed to create stdout pipe: %v"
-	ERR_CREATE_STDERR_PIPE_MP3 = "Failed to create stderr pipe: %v"
-	ERR_START_YT_DLP           = "Failed to start yt-dlp: %v"
-	ERR_START_YT_DLP_EXE       = "Failed to start yt-dlp: %v. Make sure yt-dlp.exe exists and is executable"
-	ERR_START_YT_DLP_DOWNLOAD  = "yt-dlp not found: %v"
-	ERR_START_YT_DLP_MP3       = "Failed to start yt-dlp for MP3: %v"
-	ERR_START_YT_DLP_INFO      = "yt-dlp not found: %v"
-	ERR_START_MP3_CONVERSION   = "Failed to start MP3 conversion: %v"
-	ERR_YT_DLP_INFO_FAILED     = "yt-dlp video info command failed: %v"
-	ERR_YT_DLP_TEST_FAILED     = "yt-dlp test failed: %v"
+	ERR_CREATE_STDOUT_PIPE    = "Failed to create stdout pipe: %v"
+	ERR_CREATE_STDERR_PIPE    = "Failed to create stderr pipe: %v"
+	ERR_START_YT_DLP          = "Failed to start yt-dlp: %v"
+	ERR_START_YT_DLP_EXE      = "Failed to start yt-dlp: %v. Make sure yt-dlp exists and is executable"
+	ERR_YT_DLP_NOT_FOUND      = "yt-dlp not found: %v"
+	ERR_YT_DLP_INFO_FAILED    = "yt-dlp video info command failed: %v"
+	ERR_KILL_PROCESS          = "failed to kill process tree: %v"
+	ERR_SUSPEND_PROCESS       = "failed to suspend process: %v"
+	ERR_RESUME_PROCESS        = "failed to resume process: %v"
+	ERR_REMOVE_TEMP_FILE      = "Failed to remove temp file %s: %v"
+	ERR_GENERATE_JOB_ID       = "failed to generate job ID: %v"
+)
+
+// ---------- ERROR MESSAGES - JOB CONTROL --------------
+const (
+	ERR_JOB_NOT_FOUND       = "job %s not found"
+	ERR_JOB_NOT_RUNNING     = "job %s is not running"
+	ERR_JOB_ALREADY_PAUSED  = "job %s is already paused"
+	ERR_JOB_NOT_PAUSED      = "job %s is not paused"
+	ERR_JOB_FINISHED        = "job %s has already finished"
+	ERR_JOB_NOT_QUEUED      = "job %s is not queued"
+	ERR_JOB_NOT_RETRYABLE   = "job %s cannot be retried while %s"
+	ERR_NOTHING_TO_RETRY    = "playlist %s has no failed items to retry"
+	ERR_PLAYLIST_EMPTY      = "no playlist entries selected"
+	ERR_UNKNOWN_JOB_TYPE    = "unknown job type %q"
+	ERR_JOB_FILE_UNAVAILABLE = "no file is waiting to be fetched for job %s"
+	ERR_INVALID_FILE_RETENTION = "invalid file retention %s, must be positive"
+	ERR_INVALID_INTERRUPTED_RETENTION = "invalid interrupted job retention %s, must be positive"
+	ERR_JOB_NOT_INTERRUPTED = "job %s was not interrupted"
+	ERR_INVALID_QUEUE_POSITION = "invalid queue position %d (queue length %d)"
+	ERR_INVALID_CONCURRENCY = "invalid concurrency %d, must be between 1 and %d"
+	ERR_INVALID_ATTEMPTS    = "invalid max attempts %d, must be between 1 and %d"
+	ERR_INVALID_REQUEST_JOB = "Invalid request"
+)
+
+// ---------- SUBTITLE MESSAGES --------------
+const (
+	LOG_STARTING_SUBTITLES        = "Starting subtitle download for URL: %s"
+	LOG_SUBTITLES_STARTED         = "Subtitle download started with ID: %s"
+	WARNING_SIDECAR_NOT_SAVED     = "Warning: could not save %s next to the video: %v"
+	MSG_STARTING_SUBTITLES        = "Fetching subtitles..."
+	MSG_SUBTITLES_SAVED_AS        = "Subtitles saved as: %s"
+	ERR_SUBTITLES_FAILED          = "Subtitle download failed: %v"
+	ERR_SAVE_SUBTITLES            = "Failed to save subtitles: %v"
+	ERR_NO_SUBTITLE_LANGUAGES     = "choose at least one subtitle language"
+	ERR_INVALID_SUBTITLE_LANGUAGE = "invalid subtitle language %q"
+	ERR_INVALID_SUBTITLE_MODE     = "unknown subtitle mode %q"
+	ERR_INVALID_SUBTITLE_FORMAT   = "unknown subtitle format %q"
+	ERR_INVALID_CONTAINER         = "unknown container %q"
+	ERR_INVALID_FORMAT_ID         = "invalid format ID %q"
+	ERR_VIDEO_FORMAT_FOR_AUDIO    = "a video format cannot be chosen for audio extraction"
+	ERR_EMBED_NEEDS_MKV           = "%s subtitles can only be embedded in MKV"
+	ERR_EMBED_WEBM_NEEDS_VTT      = "only VTT subtitles can be embedded in WebM"
+	ERR_INVALID_VIDEO_CODEC       = "unknown video codec %q"
+	ERR_DUPLICATE_VIDEO_CODEC     = "video codec %q is listed twice"
+	ERR_CODEC_NOT_IN_WEBM         = "%s video cannot be stored in WebM"
+	ERR_INVALID_MAX_FPS           = "the maximum frame rate cannot be negative"
+	ERR_INVALID_MAX_FILESIZE      = "invalid maximum file size %q, expected a size such as 500M"
+	ERR_PREFERENCES_NEED_HEIGHT   = "quality preferences need a height such as 720p, not %q"
+	ERR_NO_SUBTITLE_FILES         = "no subtitles were found for the requested languages"
+	ERR_BUNDLE_FILES              = "failed to bundle files: %v"
+)
+
+// ---------- CLIP MESSAGES --------------
+const (
+	LOG_TRIMMING_CLIP        = "Trimming %s locally to %s-%s"
+	WARNING_CLIP_NOT_CHECKED = "Warning: cannot check clip lengths, ffprobe not found: %v"
+	MSG_PHASE_CLIP           = "Trimming clip %d of %d..."
+	ERR_CLIP_EMPTY           = "choose at least one time range or chapter to clip"
+	ERR_CLIP_TIMESTAMP       = "invalid timestamp %q, expected seconds, MM:SS or HH:MM:SS"
+	ERR_CLIP_RANGE           = "clip range %s-%s ends before it starts"
+	ERR_CLIP_NEEDS_FFMPEG    = "clipping needs ffmpeg: %v"
+	ERR_PROBE_DURATION       = "failed to read the length of %s: %v"
+	ERR_TRIM_CLIP            = "failed to trim %s: %v"
+	ERR_RENAME_CLIP          = "Failed to rename clip: %v"
+)
+
+// ---------- AUDIO MESSAGES --------------
+const (
+	ERR_INVALID_AUDIO_FORMAT   = "unknown audio format %q"
+	ERR_INVALID_AUDIO_QUALITY  = "invalid audio quality %q, expected a VBR level from 0 to 10 or a bitrate such as 192K"
+	ERR_INVALID_SAMPLE_RATE    = "sample rate %d Hz is not supported for %s"
+	ERR_INVALID_CHANNELS       = "channels must be 1 (mono) or 2 (stereo)"
+	ERR_ORIGINAL_AUDIO_OPTIONS = "the original audio stream is kept as is, so it cannot be resampled or remixed"
+)
+
+// ---------- CHAPTER MESSAGES --------------
+const (
+	WARNING_NO_CHAPTERS      = "Warning: %s has no chapters, keeping the whole file"
+	MSG_PHASE_TAG_CHAPTERS   = "Tagging chapter %d of %d..."
+	ERR_INVALID_CHAPTER_MODE = "unknown chapter mode %q"
+	ERR_CHAPTERS_WITH_CLIP   = "a clip cannot also be split into chapters"
+	ERR_CHAPTERS_NEED_FFMPEG = "chapters need ffmpeg: %v"
+)
+
+// ---------- TAGGING MESSAGES --------------
+const (
+	WARNING_NO_TRACK_INFO   = "Warning: no info JSON in %s, tagging from the title only"
+	WARNING_TAGGING_SKIPPED = "Warning: ffmpeg not found, tags were not written: %v"
+	WARNING_NO_COVER_ART    = "Warning: cover art cannot be embedded in %s files"
+	WARNING_BAD_TITLE_RULE  = "Warning: skipping tagging pattern %q: %v"
+	ERR_TAG_FILE            = "failed to tag %s: %v"
+	ERR_INVALID_TAG_YEAR    = "invalid year %q, expected four digits"
+	ERR_INVALID_TAG_TRACK   = "invalid track %q, expected a number such as 3 or 3/12"
+)
+
+// ---------- HEALTH CHECK MESSAGES --------------
+const (
+	MSG_CHECK_YT_DLP_MISSING   = "yt-dlp was not found; downloads will fail"
+	MSG_CHECK_FFMPEG_MISSING   = "ffmpeg was not found; merging and MP3 conversion will fail"
+	MSG_CHECK_FFPROBE_MISSING  = "ffprobe was not found; some post-processing may fail"
+	MSG_CHECK_VERSION_UNKNOWN  = "could not parse version %q"
+	MSG_CHECK_YT_DLP_OUTDATED  = "yt-dlp %s may be outdated, consider updating from https://github.com/yt-dlp/yt-dlp/releases"
+	MSG_CHECK_ENCODERS_MISSING = "ffmpeg is missing encoders %v"
+	MSG_CHECK_ENCODERS_FOUND   = "%d encoders available"
+	MSG_CHECK_DISK_FREE        = "%s free in %s"
+	ERR_LIST_ENCODERS          = "failed to list ffmpeg encoders: %v"
+	ERR_DISK_SPACE_UNSUPPORTED = "free disk space is not available on this platform"
+)
+
+// ---------- ERROR MESSAGES - CONFIGURATION --------------
+const (
+	ERR_CONFIG_READ             = "failed to read config file %s: %v"
+	ERR_CONFIG_PARSE            = "failed to parse config file %s: %v"
+	ERR_CONFIG_INVALID_ENV      = "invalid value in %s: %v"
+	ERR_CONFIG_INVALID          = "invalid configuration: %s"
+	ERR_CONFIG_INVALID_DURATION = "invalid duration %s"
+	ERR_CONFIG_INVALID_ADDRESS  = "invalid server address %q"
+	ERR_CONFIG_EMPTY            = "%s must not be empty"
+	ERR_CONFIG_INVALID_COUNTRY  = "invalid geo-bypass country %q, expected a two-letter code"
+	ERR_CONFIG_INVALID_PROXY    = "invalid proxy URL"
+	ERR_CONFIG_INVALID_PATTERN  = "invalid tagging pattern %q: %v"
+	ERR_CONFIG_INVALID_DELAYS   = "invalid retry delays %s and %s, the initial delay must be positive and not above the maximum"
+	ERR_CONFIG_UNKNOWN_STRATEGY = "unknown retry strategy %q"
 )
 
 // ---------- ERROR MESSAGES - URL AND VIDEO HANDLING --------------
 const (
 	ERR_INVALID_URL          = "invalid URL: %v"
-	ERR_NOT_YOUTUBE_URL      = "not a YouTube URL"
 	ERR_EXTRACT_VIDEO_ID     = "could not extract video ID from URL"
-	ERR_MISSING_V_PARAM      = "missing v parameter in URL"
-	ERR_INVALID_YOUTUBE_URL  = "invalid YouTube URL: %v"
-	ERR_VIDEO_UNAVAILABLE    = "video is unavailable or private"
-	ERR_YOUTUBE_BLOCKED      = "youtube blocked the request. Try a different video or wait a moment."
-	ERR_VIDEO_RESTRICTED     = "video requires sign-in or is age-restricted"
-	ERR_VIDEO_RESTRICTED_GEO = "video is restricted or geo-blocked. YouTube blocked access to this video"
-	ERR_VIDEO_FRAGMENTS      = "video fragments are unavailable. This video may be corrupted or restricted"
-	ERR_VIDEO_PRIVATE        = "this is a private video"
-	ERR_VIDEO_REMOVED        = "video is unavailable or has been removed"
+	ERR_NOT_PLAYLIST_URL     = "not a YouTube playlist or channel URL"
+	ERR_PARSE_PLAYLIST       = "failed to parse playlist: %v"
+	ERR_UNSUPPORTED_SCHEME   = "unsupported URL scheme %q, expected http or https"
+	ERR_MISSING_HOST         = "URL has no host"
+	ERR_SITE_DENIED          = "%s is blocked by the site deny list"
+	ERR_SITE_NOT_ALLOWED     = "%s is not in the list of allowed sites"
 	ERR_DOWNLOAD_FAILED      = "Download failed: %s"
 	ERR_PARSE_VIDEO_INFO     = "failed to parse video info: %v"
 	ERR_GET_VIDEO_INFO       = "failed to get video info: %s"
-	ERR_GET_VIDEO_INFO_2     = "failed to get video info: %v"
+)
+
+// ---------- ERROR MESSAGES - CLASSIFIED FAILURES --------------
+const (
+	ERR_VIDEO_PRIVATE        = "this is a private video"
+	ERR_VIDEO_UNAVAILABLE    = "video is unavailable or has been removed"
+	ERR_VIDEO_AGE_RESTRICTED = "video is age-restricted"
+	ERR_VIDEO_LOGIN_REQUIRED = "video requires signing in"
+	ERR_VIDEO_GEO_BLOCKED    = "video is not available in your country"
+	ERR_RATE_LIMITED         = "the site is limiting how often it can be asked"
+	ERR_FORBIDDEN            = "the site refused the request (HTTP 403)"
+	ERR_FRAGMENTS_FAILED     = "some fragments of the video could not be downloaded"
+	ERR_NETWORK              = "the connection to the site failed"
+	ERR_FORMAT_UNAVAILABLE   = "the requested format is not available"
+	ERR_URL_NOT_SUPPORTED    = "yt-dlp does not support this URL"
+	ERR_YT_DLP_EXIT_CODE     = "yt-dlp exited with code %d: %s"
+	ERR_NO_AUDIO_RESULT      = "no result returned"
+)
+
+// ---------- ERROR HINTS --------------
+const (
+	HINT_VIDEO_PRIVATE      = "Only the uploader and the people they share it with can watch it."
+	HINT_VIDEO_UNAVAILABLE  = "Check the URL; the video may have been deleted."
+	HINT_AGE_RESTRICTED     = "Age-restricted videos can only be downloaded with a signed-in account."
+	HINT_LOGIN_REQUIRED     = "The site wants a signed-in account. Wait a while or try from another network."
+	HINT_GEO_BLOCKED        = "Set -geo-bypass-country or a -proxy in a country where the video is available."
+	HINT_RATE_LIMITED       = "Wait a few minutes before trying again."
+	HINT_FORBIDDEN          = "Retry in a moment. If it keeps happening, update yt-dlp."
+	HINT_FRAGMENTS_FAILED   = "Retry the download. If it keeps failing, pick another format."
+	HINT_NETWORK_ERROR      = "Check the internet connection and retry."
+	HINT_FORMAT_UNAVAILABLE = "Load the video info again and pick one of the listed formats."
+	HINT_UNSUPPORTED_URL    = "Check the URL; it must be a video page of a supported site."
+	HINT_DEPENDENCY_MISSING = "Install the missing program or set its path; /api/diagnostics shows what was searched."
+	HINT_DISK_FULL          = "Free up space in the temp and output directories."
 )
 
 // ---------- ERROR MESSAGES - HTTP AND REQUESTS --------------
 const (
 	ERR_INVALID_REQUEST      = "Invalid request"
 	ERR_INVALID_REQUEST_INFO = "Invalid request"
-	ERR_INVALID_REQUEST_MP3  = "Invalid request"
+	ERR_INVALID_QUERY_PARAM  = "Invalid %s parameter"
 	ERR_TEMPLATE             = "Template error: %s"
 	ERR_TEMPLATE_EXECUTION   = "Template execution error"
 	ERR_SERVER_START         = "Server failed to start:"
@@ -164,31 +360,28 @@ const (
 
 // ---------- YT-DLP OUTPUT TEXT PATTERNS --------------
 const (
-	YT_DLP_DOWNLOAD_100_PERCENT     = "[download] 100%"
-	YT_DLP_ALREADY_DOWNLOADED       = "has already been downloaded"
-	YT_DLP_FFMPEG_TAG               = "[ffmpeg]"
-	YT_DLP_MAX_DOWNLOADS_REACHED    = "Maximum number of downloads reached"
-	YT_DLP_VIDEO_UNAVAILABLE        = "Video unavailable"
-	YT_DLP_FORBIDDEN_403            = "403"
-	YT_DLP_FORBIDDEN_TEXT           = "Forbidden"
-	YT_DLP_SIGN_IN_REQUIRED         = "Sign in"
-	YT_DLP_FRAGMENT_TEXT            = "fragment"
-	YT_DLP_NOT_FOUND_TEXT           = "not found"
-	YT_DLP_PRIVATE_VIDEO_TEXT       = "Private video"
-	MP3_CONVERSION_FAILED_PREFIX    = "mp3 conversion failed: %s"
-	MP3_CONVERSION_FAILED_EXIT_CODE = "mp3 conversion failed (exit code %d): %s"
-	MP3_CONVERSION_FAILED_GENERIC   = "mp3 conversion failed: %v"
+	YT_DLP_ALREADY_DOWNLOADED    = "has already been downloaded"
+	YT_DLP_MAX_DOWNLOADS_REACHED = "Maximum number of downloads reached"
+	YT_DLP_ERROR_PREFIX          = "ERROR:"
 )
 
 //---------- URL TEMPLATES --------------
 const (
-	YOUTUBE_WATCH_URL = "https://www.youtube.com/watch?v=%s"
+	YOUTUBE_WATCH_URL    = "https://www.youtube.com/watch?v=%s"
+	YOUTUBE_PLAYLIST_URL = "https://www.youtube.com/playlist?list=%s"
+	YOUTUBE_CHANNEL_URL  = "https://www.youtube.com%s/%s"
 )
 
 //---------- HTTP RESPONSE MESSAGES --------------
 const (
 	MSG_DOWNLOAD_STARTED     = "Download started"
-	MSG_MP3_CONVERSION_STARTED = "MP3 conversion started"
+	MSG_AUDIO_JOB_STARTED    = "Audio extraction started"
+	MSG_PLAYLIST_STARTED     = "Playlist download started"
+	MSG_SUBTITLES_STARTED    = "Subtitle download started"
+	MSG_JOB_RETRY_REQUESTED  = "Retry queued"
+	MSG_JOB_CANCEL_REQUESTED = "Cancellation requested"
+	MSG_JOB_PAUSE_REQUESTED  = "Job paused"
+	MSG_JOB_RESUME_REQUESTED = "Job resumed"
 	MSG_SHUTDOWN_SIGNAL      = "Application is shutting down"
 	MSG_TAB_CLOSE_AUTO       = "This tab will close automatically."
 	MSG_APP_SHUTTING_DOWN    = "Application Shutting Down"
@@ -1,20 +1,17 @@
 package consts
 
 //---------- YT-DLP DOWNLOAD ARGUMENTS --------------
+// Defaults for the config file. The user agent, geo-bypass country and proxy
+// are configured separately and appended by the downloader, along with the
+// options of the site being downloaded from.
 var YT_DLP_DOWNLOAD_ARGS = []string{
 	"--merge-output-format", "mp4",
 	"--embed-metadata",
 	"--write-thumbnail",
-	"--user-agent", USER_AGENT_STRING,
-	"--referer", "https://www.youtube.com/",
 	"--add-header", HEADER_ACCEPT_LANGUAGE,
 	"--add-header", HEADER_ACCEPT_ENCODING,
 	"--add-header", HEADER_ACCEPT,
-	"--add-header", "Sec-Ch-Ua:\"Google Chrome\";v=\"131\", \"Chromium\";v=\"131\", \"Not_A Brand\";v=\"24\"",
-	"--add-header", "Sec-Ch-Ua-Mobile:?0",
-	"--add-header", "Sec-Ch-Ua-Platform:\"Windows\"",
 	"--geo-bypass",
-	"--geo-bypass-country", "US",
 	"--extractor-retries", "10",
 	"--fragment-retries", "20",
 	"--retry-sleep", "exp=1:120",
@@ -26,25 +23,24 @@ var YT_DLP_DOWNLOAD_ARGS = []string{
 	"--force-ipv4",
 	"--newline",
 	"--prefer-free-formats",
-	"--youtube-skip-dash-manifest",
 	"--hls-prefer-native",
 }
 
-//---------- YT-DLP MP3 CONVERSION ARGUMENTS --------------
-var YT_DLP_MP3_ARGS = []string{
-	"-x",
-	"--audio-format", "mp3",
-	"--audio-quality", "0",
+//---------- YT-DLP PROGRESS ARGUMENTS --------------
+// Machine-readable progress lines parsed by the downloader instead of the
+// human-readable, locale-dependent progress bar.
+var YT_DLP_PROGRESS_ARGS = []string{
+	YT_DLP_PROGRESS_TEMPLATE_FLAG, YT_DLP_DOWNLOAD_PROGRESS_TEMPLATE,
+	YT_DLP_PROGRESS_TEMPLATE_FLAG, YT_DLP_POSTPROCESS_PROGRESS_TEMPLATE,
+}
+
+//---------- YT-DLP AUDIO EXTRACTION ARGUMENTS --------------
+// The audio format, quality and ffmpeg options are appended per job.
+var YT_DLP_AUDIO_ARGS = []string{
 	"--embed-metadata",
-	"--user-agent", USER_AGENT_STRING,
-	"--referer", "https://www.youtube.com/",
 	"--add-header", HEADER_ACCEPT_LANGUAGE,
 	"--add-header", HEADER_ACCEPT_ENCODING,
-	"--add-header", "Sec-Ch-Ua:\"Google Chrome\";v=\"131\", \"Chromium\";v=\"131\", \"Not_A Brand\";v=\"24\"",
-	"--add-header", "Sec-Ch-Ua-Mobile:?0",
-	"--add-header", "Sec-Ch-Ua-Platform:\"Windows\"",
 	"--geo-bypass",
-	"--geo-bypass-country", "US",
 	"--extractor-retries", "10",
 	"--fragment-retries", "20",
 	"--retry-sleep", "exp=1:120",
@@ -56,7 +52,6 @@ var YT_DLP_MP3_ARGS = []string{
 	"--force-ipv4",
 	"--newline",
 	"--prefer-free-formats",
-	"--youtube-skip-dash-manifest",
 	"--hls-prefer-native",
 }
 
@@ -64,20 +59,413 @@ var YT_DLP_MP3_ARGS = []string{
 var YT_DLP_INFO_ARGS = []string{
 	"-j",
 	"--no-warnings",
-	"--user-agent", USER_AGENT_STRING,
-	"--referer", "https://www.youtube.com/",
 	"--add-header", HEADER_ACCEPT_LANGUAGE,
 	"--add-header", HEADER_ACCEPT_ENCODING,
-	"--add-header", "Sec-Ch-Ua:\"Google Chrome\";v=\"131\", \"Chromium\";v=\"131\", \"Not_A Brand\";v=\"24\"",
-	"--add-header", "Sec-Ch-Ua-Mobile:?0",
-	"--add-header", "Sec-Ch-Ua-Platform:\"Windows\"",
 	"--geo-bypass",
-	"--geo-bypass-country", "US",
 	"--extractor-retries", "10",
 	"--fragment-retries", "10",
 	"--retry-sleep", "exp=1:120",
 	"--no-check-certificate",
 	"--force-ipv4",
 	"--prefer-free-formats",
+}
+
+//---------- YT-DLP PLAYLIST ARGUMENTS --------------
+// Flat extraction lists playlist and channel entries without resolving each
+// video, so enumerating a large channel stays a single quick request.
+var YT_DLP_PLAYLIST_ARGS = []string{
+	"-J",
+	"--flat-playlist",
+	"--no-warnings",
+	"--add-header", HEADER_ACCEPT_LANGUAGE,
+	"--add-header", HEADER_ACCEPT_ENCODING,
+	"--geo-bypass",
+	"--extractor-retries", "10",
+	"--retry-sleep", "exp=1:120",
+	"--no-check-certificate",
+	"--force-ipv4",
+}
+
+//---------- YT-DLP SUBTITLE ARGUMENTS --------------
+// Subtitle-only jobs skip the media entirely; the tracks, languages and
+// format are appended per request.
+var YT_DLP_SUBTITLE_ARGS = []string{
+	"--skip-download",
+	"--no-warnings",
+	"--add-header", HEADER_ACCEPT_LANGUAGE,
+	"--add-header", HEADER_ACCEPT_ENCODING,
+	"--geo-bypass",
+	"--extractor-retries", "10",
+	"--retry-sleep", "exp=1:120",
+	"--no-check-certificate",
+	"--force-ipv4",
+}
+
+// FILESIZE_UNITS are the bytes of each size suffix as yt-dlp reads them in
+// format filters: upper case is decimal, lower case binary.
+var FILESIZE_UNITS = map[string]float64{
+	"K": 1e3,
+	"k": 1 << 10,
+	"M": 1e6,
+	"m": 1 << 20,
+	"G": 1e9,
+	"g": 1 << 30,
+}
+
+// JOB_ID_FORMATS are the formats of every kind of job ID.
+var JOB_ID_FORMATS = []string{
+	DOWNLOAD_ID_FORMAT,
+	AUDIO_ID_FORMAT,
+	PLAYLIST_ID_FORMAT,
+	SUBTITLES_ID_FORMAT,
+}
+
+var SUBTITLE_FORMATS = []string{
+	SUBTITLE_FORMAT_SRT,
+	SUBTITLE_FORMAT_VTT,
+	SUBTITLE_FORMAT_ASS,
+}
+
+// Formats MP4 cannot carry; yt-dlp converts the others to mov_text.
+var MP4_UNEMBEDDABLE_SUBTITLE_FORMATS = []string{
+	SUBTITLE_FORMAT_ASS,
+}
+
+var CONTAINERS = []string{
+	CONTAINER_MP4,
+	CONTAINER_MKV,
+	CONTAINER_WEBM,
+}
+
+// Audio extensions that merge into each video extension without yt-dlp
+// falling back to MKV.
+var MERGE_COMPATIBLE_AUDIO = map[string][]string{
+	CONTAINER_MP4:  {"m4a", "mp4"},
+	CONTAINER_WEBM: {"webm"},
+}
+
+// The audio extension that goes with each container's own video extension.
+var CONTAINER_AUDIO_EXTENSIONS = map[string]string{
+	CONTAINER_MP4:  "m4a",
+	CONTAINER_WEBM: "webm",
+}
+
+//---------- QUALITY PREFERENCES --------------
+// yt-dlp reports codecs as strings such as "avc1.640028" or "vp09.00.40.08";
+// these match the codecs a download can prefer.
+var VIDEO_CODEC_PATTERNS = map[string]string{
+	VIDEO_CODEC_H264: `^(avc1|h264)`,
+	VIDEO_CODEC_VP9:  `^vp0?9`,
+	VIDEO_CODEC_AV1:  `^av0?1`,
+}
+
+// Codecs WebM cannot carry.
+var WEBM_INCOMPATIBLE_CODECS = []string{VIDEO_CODEC_H264}
+
+// Extensions of the subtitle files yt-dlp writes, before or after
+// conversion.
+var SUBTITLE_EXTENSIONS = []string{
+	".srt",
+	".vtt",
+	".ass",
+	".ssa",
+	".ttml",
+	".srv1",
+	".srv2",
+	".srv3",
+	".json3",
+	".lrc",
+}
+
+//---------- AUDIO FORMATS --------------
+// Formats offered for audio extraction and their yt-dlp --audio-format
+// names; yt-dlp calls Ogg Vorbis "vorbis".
+var AUDIO_FORMATS = map[string]string{
+	"mp3":  "mp3",
+	"m4a":  "m4a",
+	"opus": "opus",
+	"flac": "flac",
+	"wav":  "wav",
+	"ogg":  "vorbis",
+}
+
+// Quality presets do not apply to these.
+var AUDIO_LOSSLESS_FORMATS = []string{"flac", "wav"}
+
+var AUDIO_SAMPLE_RATES = []int{8000, 11025, 16000, 22050, 24000, 32000, 44100, 48000, 96000}
+
+// The Opus encoder only accepts these rates.
+var OPUS_SAMPLE_RATES = []int{8000, 12000, 16000, 24000, 48000}
+
+//---------- AUDIO TAGGING --------------
+// Audio jobs keep the info JSON and thumbnail in the workspace for tagging.
+var YT_DLP_TAGGING_ARGS = []string{"--write-info-json", "--write-thumbnail", "--convert-thumbnails", "jpg"}
+
+// Removed from a video title before the title patterns are tried.
+var DEFAULT_TAG_TITLE_CLEANUP = []string{
+	`(?i)\s*[(\[](official\s+)?(music\s+|lyric\s+)?(video|audio|lyrics?|visuali[sz]er|hd|hq|4k)[)\]]`,
+}
+
+// Tried in order against the cleaned title; the first match wins.
+var DEFAULT_TAG_TITLE_PATTERNS = []string{
+	`^(?P<artist>.+?)\s+[-–—]\s+(?P<title>.+)$`,
+	`^(?P<artist>[^"]+?)\s+"(?P<title>[^"]+)"`,
+}
+
+// Containers ffmpeg can embed cover art in.
+var COVER_ART_EXTENSIONS = []string{".mp3", ".m4a", ".flac"}
+
+// The thumbnail is cropped to a centred square and stored as front cover.
+var FFMPEG_COVER_ART_ARGS = []string{
+	"-map", "0:a", "-map", "1:v",
+	"-c:a", "copy", "-c:v", "mjpeg",
+	"-filter:v", "crop='min(iw,ih)':'min(iw,ih)'",
+	"-disposition:v", "attached_pic",
+}
+
+// ID3v2.3 is read by more players than ffmpeg's default v2.4.
+var FFMPEG_ID3_ARGS = []string{"-id3v2_version", "3"}
+
+//---------- FFMPEG CLIP ARGUMENTS --------------
+var FFMPEG_PROBE_DURATION_ARGS = []string{
+	"-v", "error",
+	"-show_entries", "format=duration",
+	"-of", "default=noprint_wrappers=1:nokey=1",
+}
+
+// Machine-readable progress on stdout, parsed like yt-dlp's.
+var FFMPEG_PROGRESS_ARGS = []string{"-y", "-hide_banner", "-nostats", "-progress", "pipe:1"}
+
+// Stream copy cuts on the nearest keyframe; re-encoding cuts exactly.
+var FFMPEG_CLIP_COPY_ARGS = []string{"-map", "0", "-c", "copy"}
+
+var FFMPEG_CLIP_ENCODE_ARGS = []string{
+	"-map", "0:v?", "-map", "0:a?",
+	"-c:v", "libx264", "-preset", "veryfast", "-crf", "18",
+	"-c:a", "aac", "-b:a", "192k",
+}
+
+// Containers that cannot hold H.264/AAC; re-encoded clips become MKV.
+var FFMPEG_CLIP_ENCODE_INCOMPATIBLE_EXTENSIONS = []string{".webm"}
+
+//---------- YOUTUBE URLS --------------
+// Hosts canonicalised by the YouTube URL normalizer, including subdomains
+// such as m.youtube.com and music.youtube.com.
+var YOUTUBE_DOMAINS = []string{
+	YOUTUBE_DOMAIN,
+	YOUTU_BE_DOMAIN,
+	"youtube-nocookie.com",
+}
+
+// YT_DLP_YOUTUBE_ARGS are added to every yt-dlp command for a YouTube URL.
+var YT_DLP_YOUTUBE_ARGS = []string{
+	"--referer", "https://www.youtube.com/",
+	"--add-header", "Sec-Ch-Ua:\"Google Chrome\";v=\"131\", \"Chromium\";v=\"131\", \"Not_A Brand\";v=\"24\"",
+	"--add-header", "Sec-Ch-Ua-Mobile:?0",
+	"--add-header", "Sec-Ch-Ua-Platform:\"Windows\"",
 	"--youtube-skip-dash-manifest",
-}
\ No newline at end of file
+}
+
+// PLAYLIST_ENTRY_URL_FORMATS build the URL of a flat playlist entry that
+// only has an ID, by the key of the extractor that listed it.
+var PLAYLIST_ENTRY_URL_FORMATS = map[string]string{
+	YOUTUBE_EXTRACTOR_KEY: YOUTUBE_WATCH_URL,
+}
+
+// Path prefixes followed by a video ID.
+var YOUTUBE_VIDEO_PATHS = []string{
+	"/embed/",
+	"/shorts/",
+	"/live/",
+	"/v/",
+}
+
+//---------- YOUTUBE CHANNEL PATHS --------------
+// Path prefixes of channel pages and the channel tabs that list videos.
+var YOUTUBE_CHANNEL_PREFIXES = []string{
+	"/@",
+	"/channel/",
+	"/c/",
+	"/user/",
+}
+
+var YOUTUBE_CHANNEL_TABS = []string{
+	"videos",
+	"shorts",
+	"streams",
+}
+
+//---------- REQUIRED FFMPEG ENCODERS --------------
+// Encoders used by MP3 conversion and the default MP4 merge.
+var REQUIRED_ENCODERS = []string{
+	"libmp3lame",
+	"aac",
+}
+
+//---------- SAVE TARGET NAMES --------------
+var SAVE_TARGETS = []string{
+	SAVE_TARGET_AUTO,
+	SAVE_TARGET_DIRECTORY,
+	SAVE_TARGET_BROWSER,
+	SAVE_TARGET_POWERSHELL,
+	SAVE_TARGET_ZENITY,
+	SAVE_TARGET_KDIALOG,
+	SAVE_TARGET_OSASCRIPT,
+}
+
+// Save dialog arguments ahead of the suggested file name.
+var ZENITY_SAVE_DIALOG_ARGS = []string{
+	"--file-selection",
+	"--save",
+	"--confirm-overwrite",
+	"--title=" + SAVE_DIALOG_TITLE,
+}
+
+var KDIALOG_SAVE_DIALOG_ARGS = []string{
+	"--title", SAVE_DIALOG_TITLE,
+	"--getsavefilename",
+}
+
+// APPLESCRIPT_ESCAPES are the replacement pairs that quote a file name
+// inside an AppleScript string.
+var APPLESCRIPT_ESCAPES = []string{
+	`\`, `\\`,
+	`"`, `\"`,
+}
+
+//---------- NON-MEDIA FILE EXTENSIONS --------------
+var NON_MEDIA_EXTENSIONS = []string{
+	PART_EXT,
+	YTDL_EXT,
+	".jpg",
+	".jpeg",
+	".png",
+	".webp",
+	".json",
+	".description",
+}
+
+//---------- MEDIA CONTENT TYPES --------------
+// Not every platform's MIME table knows the containers yt-dlp produces.
+var MEDIA_CONTENT_TYPES = map[string]string{
+	".mp4":  "video/mp4",
+	".mkv":  "video/x-matroska",
+	".webm": "video/webm",
+	".mov":  "video/quicktime",
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".opus": "audio/ogg",
+	".ogg":  "audio/ogg",
+	".flac": "audio/flac",
+	".wav":  "audio/wav",
+	".zip":  "application/zip",
+	".srt":  "application/x-subrip",
+	".vtt":  "text/vtt",
+	".ass":  "text/x-ssa",
+}
+
+//---------- ERROR CLASSIFICATION --------------
+// ERROR_CLASSIFICATION_ORDER is the order ERROR_OUTPUT_PATTERNS are tried
+// in, so that "Private video. Sign in if you've been granted access" is a
+// private video rather than a sign-in.
+var ERROR_CLASSIFICATION_ORDER = []string{
+	ERROR_CODE_DISK_FULL,
+	ERROR_CODE_DEPENDENCY_MISSING,
+	ERROR_CODE_VIDEO_PRIVATE,
+	ERROR_CODE_AGE_RESTRICTED,
+	ERROR_CODE_GEO_BLOCKED,
+	ERROR_CODE_LOGIN_REQUIRED,
+	ERROR_CODE_VIDEO_UNAVAILABLE,
+	ERROR_CODE_UNSUPPORTED_URL,
+	ERROR_CODE_FORMAT_UNAVAILABLE,
+	ERROR_CODE_RATE_LIMITED,
+	ERROR_CODE_FORBIDDEN,
+	ERROR_CODE_FRAGMENTS_FAILED,
+	ERROR_CODE_NETWORK_ERROR,
+}
+
+// ERROR_OUTPUT_PATTERNS are matched without regard to case against the
+// error lines of yt-dlp and against the text of other errors.
+var ERROR_OUTPUT_PATTERNS = map[string]string{
+	ERROR_CODE_DISK_FULL:          `no space left on device|not enough space on the disk|disk quota exceeded`,
+	ERROR_CODE_DEPENDENCY_MISSING: `not found \(searched: |ffmpeg not found|ffprobe not found|ffprobe and ffmpeg not found|ffmpeg is not installed`,
+	ERROR_CODE_VIDEO_PRIVATE:      `private video|this video is private`,
+	ERROR_CODE_AGE_RESTRICTED:     `confirm your age|age[- ]restricted|inappropriate for some users`,
+	ERROR_CODE_GEO_BLOCKED:        `in your country|geo[- ]?restrict|geo[- ]?blocked`,
+	ERROR_CODE_LOGIN_REQUIRED:     `sign in|log in|login required|members[- ]only|join this channel`,
+	ERROR_CODE_VIDEO_UNAVAILABLE:  `video unavailable|has been removed|no longer available|this video is not available`,
+	ERROR_CODE_UNSUPPORTED_URL:    `unsupported url`,
+	ERROR_CODE_FORMAT_UNAVAILABLE: `requested format is not available|no video formats found`,
+	ERROR_CODE_RATE_LIMITED:       `http error 429|too many requests|rate[- ]limit`,
+	ERROR_CODE_FORBIDDEN:          `http error 403|\b403\b|forbidden`,
+	ERROR_CODE_FRAGMENTS_FAILED:   `fragment \d+ not found|fragment not found|fragment retries|did not get any data blocks`,
+	ERROR_CODE_NETWORK_ERROR:      `timed out|connection (reset|refused|aborted)|temporary failure in name resolution|name or service not known|network is unreachable|unable to download webpage|remote end closed connection|incompleteread`,
+}
+
+// ERROR_MESSAGES replaces the output of a classified failure. Codes without
+// a message keep the original text, which names what is missing.
+var ERROR_MESSAGES = map[string]string{
+	ERROR_CODE_VIDEO_PRIVATE:      ERR_VIDEO_PRIVATE,
+	ERROR_CODE_VIDEO_UNAVAILABLE:  ERR_VIDEO_UNAVAILABLE,
+	ERROR_CODE_AGE_RESTRICTED:     ERR_VIDEO_AGE_RESTRICTED,
+	ERROR_CODE_LOGIN_REQUIRED:     ERR_VIDEO_LOGIN_REQUIRED,
+	ERROR_CODE_GEO_BLOCKED:        ERR_VIDEO_GEO_BLOCKED,
+	ERROR_CODE_RATE_LIMITED:       ERR_RATE_LIMITED,
+	ERROR_CODE_FORBIDDEN:          ERR_FORBIDDEN,
+	ERROR_CODE_FRAGMENTS_FAILED:   ERR_FRAGMENTS_FAILED,
+	ERROR_CODE_NETWORK_ERROR:      ERR_NETWORK,
+	ERROR_CODE_FORMAT_UNAVAILABLE: ERR_FORMAT_UNAVAILABLE,
+	ERROR_CODE_UNSUPPORTED_URL:    ERR_URL_NOT_SUPPORTED,
+}
+
+var ERROR_HINTS = map[string]string{
+	ERROR_CODE_VIDEO_PRIVATE:      HINT_VIDEO_PRIVATE,
+	ERROR_CODE_VIDEO_UNAVAILABLE:  HINT_VIDEO_UNAVAILABLE,
+	ERROR_CODE_AGE_RESTRICTED:     HINT_AGE_RESTRICTED,
+	ERROR_CODE_LOGIN_REQUIRED:     HINT_LOGIN_REQUIRED,
+	ERROR_CODE_GEO_BLOCKED:        HINT_GEO_BLOCKED,
+	ERROR_CODE_RATE_LIMITED:       HINT_RATE_LIMITED,
+	ERROR_CODE_FORBIDDEN:          HINT_FORBIDDEN,
+	ERROR_CODE_FRAGMENTS_FAILED:   HINT_FRAGMENTS_FAILED,
+	ERROR_CODE_NETWORK_ERROR:      HINT_NETWORK_ERROR,
+	ERROR_CODE_FORMAT_UNAVAILABLE: HINT_FORMAT_UNAVAILABLE,
+	ERROR_CODE_UNSUPPORTED_URL:    HINT_UNSUPPORTED_URL,
+	ERROR_CODE_DEPENDENCY_MISSING: HINT_DEPENDENCY_MISSING,
+	ERROR_CODE_DISK_FULL:          HINT_DISK_FULL,
+}
+
+// RETRYABLE_ERROR_CODES are failures that may well not happen again.
+var RETRYABLE_ERROR_CODES = []string{
+	ERROR_CODE_RATE_LIMITED,
+	ERROR_CODE_FORBIDDEN,
+	ERROR_CODE_FRAGMENTS_FAILED,
+	ERROR_CODE_NETWORK_ERROR,
+}
+
+//---------- RETRY STRATEGIES --------------
+// RETRY_STRATEGY_ARGS are the yt-dlp options each retry strategy adds. The
+// lower format only ranks formats up to 720p first; a format picked by ID
+// is kept.
+var RETRY_STRATEGY_ARGS = map[string][]string{
+	RETRY_STRATEGY_PLAYER_CLIENT: {EXTRACTOR_ARGS_FLAG, RETRY_PLAYER_CLIENTS},
+	RETRY_STRATEGY_FORCE_IPV4:    {FORCE_IPV4_FLAG},
+	RETRY_STRATEGY_FORCE_IPV6:    {FORCE_IPV6_FLAG},
+	RETRY_STRATEGY_LOWER_FORMAT:  {FORMAT_SORT_FLAG, RETRY_LOWER_FORMAT_SORT},
+}
+
+// RETRY_STRATEGY_REMOVED_ARGS are the yt-dlp options a strategy takes out
+// of the job's arguments; forcing one address family drops the other.
+var RETRY_STRATEGY_REMOVED_ARGS = map[string][]string{
+	RETRY_STRATEGY_FORCE_IPV4: {FORCE_IPV6_FLAG},
+	RETRY_STRATEGY_FORCE_IPV6: {FORCE_IPV4_FLAG},
+}
+
+// DEFAULT_RETRY_STRATEGIES are added one per attempt after the first. The
+// default arguments already force IPv4, so the address family switches to
+// IPv6, and last since it fails outright on hosts without IPv6.
+var DEFAULT_RETRY_STRATEGIES = []string{
+	RETRY_STRATEGY_PLAYER_CLIENT,
+	RETRY_STRATEGY_LOWER_FORMAT,
+	RETRY_STRATEGY_FORCE_IPV6,
+}
@@ -0,0 +1,210 @@
+package dependencies
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Tool describes an external binary the application depends on.
+type Tool struct {
+	Name        string
+	VersionArgs []string
+}
+
+var (
+	YtDlp   = Tool{Name: consts.YT_DLP_BINARY, VersionArgs: []string{consts.YT_DLP_VERSION_FLAG}}
+	FFmpeg  = Tool{Name: consts.FFMPEG_BINARY, VersionArgs: []string{consts.FFMPEG_VERSION_FLAG}}
+	FFprobe = Tool{Name: consts.FFPROBE_BINARY, VersionArgs: []string{consts.FFMPEG_VERSION_FLAG}}
+)
+
+// Resolution records which binary was chosen for a tool and why.
+type Resolution struct {
+	Tool    string   `json:"tool"`
+	Path    string   `json:"path"`
+	Version string   `json:"version"`
+	Source  string   `json:"source"`
+	Tried   []string `json:"tried"`
+}
+
+type candidate struct {
+	path   string
+	source string
+}
+
+// Resolver finds external binaries by searching, in order: the path set in
+// the config, the dependencies directory next to the executable and in the
+// working directory, PATH, and well-known install locations. A yt-dlp or
+// ffmpeg path set in the config is the only one tried, so a mistake there
+// is reported rather than covered up by another copy. Results are cached
+// until Refresh is called.
+type Resolver struct {
+	cfg   *config.Config
+	cache map[string]Resolution
+	mu    sync.Mutex
+}
+
+func NewResolver(cfg *config.Config) *Resolver {
+	return &Resolver{cfg: cfg, cache: make(map[string]Resolution)}
+}
+
+func (r *Resolver) YtDlpPath() (string, error) {
+	resolution, err := r.Resolve(YtDlp)
+	return resolution.Path, err
+}
+
+func (r *Resolver) FFmpegPath() (string, error) {
+	resolution, err := r.Resolve(FFmpeg)
+	return resolution.Path, err
+}
+
+func (r *Resolver) FFprobePath() (string, error) {
+	resolution, err := r.Resolve(FFprobe)
+	return resolution.Path, err
+}
+
+// Resolve returns the cached resolution for tool, searching on first use.
+// Failed lookups are not cached so a binary installed later is picked up.
+func (r *Resolver) Resolve(tool Tool) (Resolution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if resolution, ok := r.cache[tool.Name]; ok {
+		return resolution, nil
+	}
+
+	resolution := Resolution{Tool: tool.Name}
+	for _, c := range r.candidates(tool) {
+		resolution.Tried = append(resolution.Tried, c.path)
+		if !isExecutable(c.path) {
+			continue
+		}
+		resolution.Path = c.path
+		resolution.Source = c.source
+		resolution.Version = probeVersion(c.path, tool.VersionArgs)
+		r.cache[tool.Name] = resolution
+		log.Printf(consts.LOG_DEPENDENCY_RESOLVED, tool.Name, resolution.Version, resolution.Path, resolution.Source)
+		return resolution, nil
+	}
+
+	if configured, explicit := r.configuredPath(tool); explicit {
+		resolution.Source = consts.SOURCE_CONFIG
+		return resolution, fmt.Errorf(consts.ERR_DEPENDENCY_UNUSABLE, tool.Name, configured)
+	}
+	return resolution, fmt.Errorf(consts.ERR_DEPENDENCY_NOT_FOUND, tool.Name, strings.Join(resolution.Tried, ", "))
+}
+
+// Refresh drops every cached resolution.
+func (r *Resolver) Refresh() {
+	r.mu.Lock()
+	r.cache = make(map[string]Resolution)
+	r.mu.Unlock()
+}
+
+func (r *Resolver) candidates(tool Tool) []candidate {
+	var candidates []candidate
+	binary := binaryName(tool.Name)
+
+	if configured, explicit := r.configuredPath(tool); explicit {
+		return []candidate{{configured, consts.SOURCE_CONFIG}}
+	} else if configured != "" {
+		candidates = append(candidates, candidate{configured, consts.SOURCE_CONFIG})
+	}
+
+	depsDir := r.cfg.Paths.DependenciesDir
+	if !filepath.IsAbs(depsDir) {
+		if exe, err := os.Executable(); err == nil {
+			candidates = append(candidates, candidate{filepath.Join(filepath.Dir(exe), depsDir, binary), consts.SOURCE_EXECUTABLE_DIR})
+		}
+	}
+	if dir, err := filepath.Abs(depsDir); err == nil {
+		candidates = append(candidates, candidate{filepath.Join(dir, binary), consts.SOURCE_DEPENDENCIES_DIR})
+	}
+
+	if path, err := exec.LookPath(tool.Name); err == nil {
+		candidates = append(candidates, candidate{path, consts.SOURCE_PATH})
+	}
+
+	for _, dir := range wellKnownDirs() {
+		candidates = append(candidates, candidate{filepath.Join(dir, binary), consts.SOURCE_WELL_KNOWN})
+	}
+	return candidates
+}
+
+// configuredPath returns the config's path for tool, and whether it was set
+// explicitly rather than derived from another tool's path.
+func (r *Resolver) configuredPath(tool Tool) (string, bool) {
+	switch tool.Name {
+	case consts.YT_DLP_BINARY:
+		return r.cfg.Paths.YtDlp, r.cfg.Paths.YtDlp != ""
+	case consts.FFMPEG_BINARY:
+		return r.cfg.Paths.FFmpeg, r.cfg.Paths.FFmpeg != ""
+	case consts.FFPROBE_BINARY:
+		// ffprobe ships next to ffmpeg in every distribution we support.
+		if r.cfg.Paths.FFmpeg != "" {
+			return filepath.Join(filepath.Dir(r.cfg.Paths.FFmpeg), binaryName(tool.Name)), false
+		}
+	}
+	return "", false
+}
+
+func binaryName(name string) string {
+	if runtime.GOOS == consts.WINDOWS_OS {
+		return name + consts.EXE_SUFFIX
+	}
+	return name
+}
+
+func wellKnownDirs() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case consts.WINDOWS_OS:
+		dirs := []string{consts.WINDOWS_FFMPEG_DIR}
+		if localAppData := os.Getenv(consts.ENV_LOCALAPPDATA); localAppData != "" {
+			dirs = append(dirs, filepath.Join(localAppData, consts.WINGET_LINKS_DIR))
+		}
+		if programFiles := os.Getenv(consts.ENV_PROGRAMFILES); programFiles != "" {
+			dirs = append(dirs, filepath.Join(programFiles, consts.PROGRAM_FILES_FFMPEG_DIR))
+		}
+		if chocolatey := os.Getenv(consts.ENV_CHOCOLATEY_INSTALL); chocolatey != "" {
+			dirs = append(dirs, filepath.Join(chocolatey, consts.CHOCOLATEY_BIN_DIR))
+		}
+		if home != "" {
+			dirs = append(dirs, filepath.Join(home, consts.SCOOP_SHIMS_DIR))
+		}
+		return dirs
+	case consts.DARWIN_OS:
+		return []string{consts.HOMEBREW_BIN_DIR, consts.USR_LOCAL_BIN_DIR, consts.MACPORTS_BIN_DIR}
+	default:
+		dirs := []string{consts.USR_LOCAL_BIN_DIR, consts.USR_BIN_DIR, consts.SNAP_BIN_DIR}
+		if home != "" {
+			dirs = append(dirs, filepath.Join(home, consts.USER_LOCAL_BIN_DIR))
+		}
+		return dirs
+	}
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == consts.WINDOWS_OS || info.Mode()&0111 != 0
+}
+
+// probeVersion returns the first line of the tool's version output.
+func probeVersion(path string, args []string) string {
+	output, err := exec.Command(path, args...).Output()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line)
+}
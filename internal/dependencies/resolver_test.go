@@ -0,0 +1,54 @@
+package dependencies
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestResolveConfiguredPath(t *testing.T) {
+	if runtime.GOOS == consts.WINDOWS_OS {
+		t.Skip("executable bits are not used on Windows")
+	}
+
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "yt-dlp")
+	notExecutable := filepath.Join(dir, "yt-dlp.txt")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(notExecutable, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "executable", path: executable},
+		{name: "not executable", path: notExecutable, wantErr: true},
+		{name: "missing", path: filepath.Join(dir, "missing"), wantErr: true},
+		{name: "directory", path: dir, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Paths.YtDlp = tt.path
+			resolution, err := NewResolver(cfg).Resolve(YtDlp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if resolution.Source != consts.SOURCE_CONFIG || len(resolution.Tried) != 1 {
+				t.Errorf("Resolve() = %+v, want only the configured path tried", resolution)
+			}
+			if !tt.wantErr && resolution.Path != tt.path {
+				t.Errorf("Resolve() path = %q, want %q", resolution.Path, tt.path)
+			}
+		})
+	}
+}
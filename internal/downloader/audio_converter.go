@@ -1,65 +1,73 @@
 package downloader
 
 import (
+	"Go-Utilities/internal/config"
 	"Go-Utilities/internal/consts"
-	"bufio"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/models"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
-func ExecuteMp3Conversion(url string, progressCallback ProgressCallback) (*YtDlpResult, error) {
-	cleanURL, tempDir, err := prepareMp3ConversionEnvironment(url)
+func ExecuteAudioExtraction(cfg *config.Config, deps *dependencies.Resolver, ytdlp YtDlpClient, workspace string, req models.DownloadRequest, progressCallback ProgressCallback, processCallback ProcessCallback) (*YtDlpResult, error) {
+	args, err := buildAudioExtractionCommand(cfg, deps, workspace, req)
 	if err != nil {
 		return nil, err
 	}
 
-	args, err := buildMp3ConversionCommand(tempDir, cleanURL)
+	title, output, err := executeAudioExtractionProcess(ytdlp, args, progressCallback, processCallback)
 	if err != nil {
-		return nil, err
-	}
-
-	title, stdoutLines, err := executeMp3ConversionProcess(args, progressCallback)
-	if err != nil {
-		validationErr := validateMp3ConversionResult(err, stdoutLines)
+		validationErr := validateAudioExtractionResult(err, output)
 		if validationErr != nil {
 			return nil, validationErr
 		}
 	}
 
-	return locateMp3ConversionResult(tempDir, title)
-}
+	tags, cover := prepareTags(cfg, workspace, title, req)
+	if req.Chapters == consts.CHAPTER_MODE_SPLIT {
+		// Every chapter is a track of an album named after the video.
+		album := tags
+		album.Title, album.Track = "", ""
+		if req.Tags == nil || req.Tags.Album == "" {
+			album.Album = tags.Title
+		}
+		if result, err := finishChapters(deps, workspace, title, &album, cover, progressCallback, processCallback); result != nil || err != nil {
+			return result, err
+		}
+	}
 
-func prepareMp3ConversionEnvironment(url string) (string, string, error) {
-	cleanURL, err := cleanYouTubeURL(url)
+	result, err := locateAudioExtractionResult(workspace, title)
 	if err != nil {
-		return "", "", fmt.Errorf(consts.ERR_INVALID_YOUTUBE_URL, err)
+		return nil, err
 	}
-
-	tempDir := filepath.Join(os.TempDir(), consts.TEMP_DIR)
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", "", fmt.Errorf(consts.ERR_CREATE_TEMP_DIR, err)
+	if err := tagAudio(deps, result.FilePath, tags, cover, progressCallback, processCallback); err != nil {
+		return nil, err
 	}
-
-	return cleanURL, tempDir, nil
+	return result, nil
 }
 
-func buildMp3ConversionCommand(tempDir, cleanURL string) ([]string, error) {
+func buildAudioExtractionCommand(cfg *config.Config, deps *dependencies.Resolver, tempDir string, req models.DownloadRequest) ([]string, error) {
 	outputPath := filepath.Join(tempDir, consts.YT_DLP_OUTPUT_FORMAT)
 
-	ffmpegPath, err := getFFmpegPath()
+	ffmpegPath, err := deps.FFmpegPath()
 	if err != nil {
-		log.Printf(consts.WARNING_FFMPEG_NOT_FOUND_MP3, err)
+		log.Printf(consts.WARNING_FFMPEG_MISSING_AUDIO, err)
 	}
 
 	args := []string{"-o", outputPath}
-	args = append(args, consts.YT_DLP_MP3_ARGS...)
-	args = append(args, cleanURL)
+	args = append(args, cfg.YtDlp.AudioArgs...)
+	args = append(args, audioArgs(resolveAudioOptions(req))...)
+	if req.AudioFormatID != "" {
+		args = append(args, consts.FORMAT_FLAG, req.AudioFormatID)
+	}
+	args = append(args, consts.YT_DLP_TAGGING_ARGS...)
+	args = append(args, networkArgs(cfg, req.URL)...)
+	args = append(args, consts.YT_DLP_PROGRESS_ARGS...)
+	args = append(args, chapterArgs(tempDir, req.Chapters)...)
+	args = append(args, req.URL)
 
 	if ffmpegPath != "" {
 		args = append(args, consts.FFMPEG_LOCATION_FLAG, ffmpegPath)
@@ -68,131 +76,74 @@ func buildMp3ConversionCommand(tempDir, cleanURL string) ([]string, error) {
 	return args, nil
 }
 
-func executeMp3ConversionProcess(args []string, progressCallback ProgressCallback) (string, []string, error) {
-	ytDlpPath, err := getYtDlpPath()
-	if err != nil {
-		return "", nil, fmt.Errorf(consts.ERR_START_YT_DLP_MP3, err)
-	}
-
-	log.Printf(consts.LOG_CONVERTING_TO_MP3_PATH, ytDlpPath)
-
-	cmd := exec.Command(ytDlpPath, args...)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return "", nil, fmt.Errorf(consts.ERR_CREATE_STDOUT_PIPE_MP3, err)
-	}
-
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return "", nil, fmt.Errorf(consts.ERR_CREATE_STDERR_PIPE_MP3, err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return "", nil, fmt.Errorf(consts.ERR_START_MP3_CONVERSION, err)
-	}
-
-	go handleStderrOutput(stderr)
-
-	scanner := bufio.NewScanner(stdout)
-	title, stdoutLines := handleMp3ProcessOutput(scanner, progressCallback)
-
-	err = cmd.Wait()
-	return title, stdoutLines, err
+// audioOutput keeps what validateAudioExtractionResult needs from yt-dlp's
+// stdout rather than every progress line of a long extraction: whether it
+// stopped at the download limit, and the last lines.
+type audioOutput struct {
+	stoppedAtLimit bool
+	tail           []string
 }
 
-func handleStderrOutput(stderr io.ReadCloser) {
-	stderrScanner := bufio.NewScanner(stderr)
-	for stderrScanner.Scan() {
-		line := stderrScanner.Text()
-		log.Printf(consts.LOG_YT_DLP_MP3_STDERR, line)
+func (o *audioOutput) add(line string) {
+	if strings.Contains(line, consts.YT_DLP_MAX_DOWNLOADS_REACHED) || strings.Contains(line, consts.YT_DLP_ALREADY_DOWNLOADED) {
+		o.stoppedAtLimit = true
+	}
+	o.tail = append(o.tail, line)
+	if len(o.tail) > consts.AUDIO_OUTPUT_TAIL_LINES {
+		o.tail = o.tail[1:]
 	}
 }
 
-func handleMp3ProcessOutput(scanner *bufio.Scanner, progressCallback ProgressCallback) (string, []string) {
-	progressRegex1 := regexp.MustCompile(consts.YT_DLP_PROGRESS_REGEX_WITH_SPEED)
-	progressRegex2 := regexp.MustCompile(consts.YT_DLP_PROGRESS_REGEX_SIMPLE)
-	titleRegex := regexp.MustCompile(consts.YT_DLP_TITLE_REGEX)
-
-	var title, filename string
-	var stdoutLines []string
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		stdoutLines = append(stdoutLines, line)
+func executeAudioExtractionProcess(ytdlp YtDlpClient, args []string, progressCallback ProgressCallback, processCallback ProcessCallback) (string, *audioOutput, error) {
+	var title string
+	output := &audioOutput{}
+	err := ytdlp.Run(args, func(line string) {
+		output.add(line)
 		log.Printf(consts.LOG_YT_DLP_STDOUT, line)
 
-		if matches := titleRegex.FindStringSubmatch(line); len(matches) > 1 {
-			filename = filepath.Base(matches[1])
-			title = strings.TrimSuffix(filename, filepath.Ext(filename))
+		if destination, ok := destinationTitle(line); ok {
+			title = destination
 		}
 
-		parseMp3Progress(line, progressRegex1, progressRegex2, progressCallback)
-	}
+		reportProgress(line, progressCallback, consts.MSG_AUDIO_EXTRACT_COMPLETED)
+	}, processCallback)
 
-	return title, stdoutLines
+	return title, output, err
 }
 
-func parseMp3Progress(line string, progressRegex1, progressRegex2 *regexp.Regexp, progressCallback ProgressCallback) {
-	if progressCallback == nil {
-		return
-	}
+func validateAudioExtractionResult(err error, output *audioOutput) error {
+	log.Printf(consts.LOG_CMD_WAIT_FAILED_OUTPUT, err, output.tail)
 
-	if matches := progressRegex1.FindStringSubmatch(line); len(matches) > 3 {
-		progress := parseFloat(matches[1])
-		speed := matches[2]
-		eta := matches[3]
-		progressCallback(progress, speed, eta, consts.MSG_STARTING_MP3_CONVERSION)
-	} else if matches := progressRegex2.FindStringSubmatch(line); len(matches) > 1 {
-		progress := parseFloat(matches[1])
-		progressCallback(progress, "", "", consts.MSG_STARTING_MP3_CONVERSION)
+	var processErr *ProcessError
+	if !errors.As(err, &processErr) {
+		return ClassifyError(err)
 	}
+	log.Printf(consts.LOG_EXIT_CODE_STDERR, processErr.ExitCode, processErr.Stderr)
 
-	if strings.Contains(line, consts.YT_DLP_DOWNLOAD_100_PERCENT) || strings.Contains(line, consts.YT_DLP_ALREADY_DOWNLOADED) {
-		progressCallback(100, "", "", consts.MSG_MP3_CONVERSION_COMPLETE)
+	if processErr.ExitCode == consts.YT_DLP_EXIT_CODE_MAX_DOWNLOADS && output.stoppedAtLimit {
+		log.Printf(consts.LOG_EXIT_CODE_101_SUCCESS)
+		return nil
 	}
 
-	if strings.Contains(line, consts.YT_DLP_FFMPEG_TAG) {
-		progressCallback(100, "", "", consts.MSG_CONVERTING_TO_MP3)
+	// yt-dlp reports some failures for audio on stdout.
+	jobErr := classifyOutput(strings.Join(output.tail, "\n")+"\n"+processErr.Stderr, err)
+	if jobErr.Code != consts.ERROR_CODE_UNKNOWN {
+		return jobErr
 	}
-}
-
-func validateMp3ConversionResult(err error, stdoutLines []string) error {
-	log.Printf(consts.LOG_CMD_WAIT_FAILED_OUTPUT, err, stdoutLines)
-
-	if exitError, ok := err.(*exec.ExitError); ok {
-		stderrOutput := string(exitError.Stderr)
-		log.Printf(consts.LOG_EXIT_CODE_STDERR, exitError.ExitCode(), stderrOutput)
-
-		fullOutput := strings.Join(stdoutLines, "\n")
-
-		if exitError.ExitCode() == 101 && (strings.Contains(fullOutput, consts.YT_DLP_MAX_DOWNLOADS_REACHED) || strings.Contains(fullOutput, consts.YT_DLP_ALREADY_DOWNLOADED)) {
-			log.Printf(consts.LOG_EXIT_CODE_101_SUCCESS)
-			return nil
-		}
-
-		if strings.Contains(fullOutput, consts.YT_DLP_VIDEO_UNAVAILABLE) || strings.Contains(stderrOutput, consts.YT_DLP_VIDEO_UNAVAILABLE) {
-			return fmt.Errorf(consts.ERR_VIDEO_UNAVAILABLE)
-		}
-
-		if strings.Contains(fullOutput, consts.YT_DLP_FORBIDDEN_403) || strings.Contains(fullOutput, consts.YT_DLP_FORBIDDEN_TEXT) || strings.Contains(stderrOutput, consts.YT_DLP_FORBIDDEN_403) || strings.Contains(stderrOutput, consts.YT_DLP_FORBIDDEN_TEXT) {
-			return fmt.Errorf(consts.ERR_YOUTUBE_BLOCKED)
-		}
-
-		if strings.Contains(fullOutput, consts.YT_DLP_SIGN_IN_REQUIRED) || strings.Contains(stderrOutput, consts.YT_DLP_SIGN_IN_REQUIRED) {
-			return fmt.Errorf(consts.ERR_VIDEO_RESTRICTED)
-		}
-
-		if stderrOutput != "" {
-			return fmt.Errorf(consts.MP3_CONVERSION_FAILED_PREFIX, stderrOutput)
-		}
-
-		return fmt.Errorf(consts.MP3_CONVERSION_FAILED_EXIT_CODE, exitError.ExitCode(), strings.Join(stdoutLines[len(stdoutLines)-5:], "; "))
+	if stderrOutput := errorLines(processErr.Stderr); stderrOutput != "" {
+		jobErr.Message = stderrOutput
+		return jobErr
 	}
 
-	return fmt.Errorf(consts.MP3_CONVERSION_FAILED_GENERIC, err)
+	lastLines := output.tail
+	if len(lastLines) > consts.AUDIO_FAILURE_OUTPUT_LINES {
+		lastLines = lastLines[len(lastLines)-consts.AUDIO_FAILURE_OUTPUT_LINES:]
+	}
+	jobErr.Message = fmt.Sprintf(consts.ERR_YT_DLP_EXIT_CODE, processErr.ExitCode, strings.Join(lastLines, "; "))
+	return jobErr
 }
 
-func locateMp3ConversionResult(tempDir, title string) (*YtDlpResult, error) {
+func locateAudioExtractionResult(tempDir, title string) (*YtDlpResult, error) {
 	log.Printf(consts.LOG_PROCESS_COMPLETED_LOOKING, tempDir)
 
 	if files, err := filepath.Glob(filepath.Join(tempDir, "*")); err == nil {
@@ -201,7 +152,7 @@ func locateMp3ConversionResult(tempDir, title string) (*YtDlpResult, error) {
 
 	convertedFile, err := findDownloadedFile(tempDir)
 	if err != nil {
-		return nil, fmt.Errorf(consts.ERR_FIND_MP3_FILE, err)
+		return nil, fmt.Errorf(consts.ERR_FIND_AUDIO_FILE, err)
 	}
 
 	return &YtDlpResult{
@@ -0,0 +1,112 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"testing"
+)
+
+func TestAudioExtractionErrors(t *testing.T) {
+	limitThenProgress := []string{"[download] Maximum number of downloads reached, stopping due to --max-downloads"}
+	for i := 0; i < consts.AUDIO_OUTPUT_TAIL_LINES*2; i++ {
+		limitThenProgress = append(limitThenProgress, fmt.Sprintf("[download] %d%%", i))
+	}
+
+	tests := []struct {
+		name      string
+		script    fakeScript
+		wantTitle string
+		wantErr   string
+	}{
+		{
+			name: "success",
+			script: fakeScript{Stdout: []string{
+				"[download] Destination: /tmp/job/Song.webm",
+				"[ExtractAudio] Destination: /tmp/job/Song.mp3",
+			}},
+			wantTitle: "Song",
+		},
+		{
+			name: "exit code 101 after the download limit",
+			script: fakeScript{
+				Stdout: []string{
+					"[download] Destination: /tmp/job/Song.webm",
+					"[download] Maximum number of downloads reached, stopping due to --max-downloads",
+				},
+				ExitCode: consts.YT_DLP_EXIT_CODE_MAX_DOWNLOADS,
+			},
+			wantTitle: "Song",
+		},
+		{
+			name:   "exit code 101 with the limit out of the kept lines",
+			script: fakeScript{Stdout: limitThenProgress, ExitCode: consts.YT_DLP_EXIT_CODE_MAX_DOWNLOADS},
+		},
+		{
+			name: "exit code 101 for a file already there",
+			script: fakeScript{
+				Stdout:   []string{"[download] /tmp/job/Song.webm has already been downloaded"},
+				ExitCode: consts.YT_DLP_EXIT_CODE_MAX_DOWNLOADS,
+			},
+		},
+		{
+			name: "exit code 101 for anything else",
+			script: fakeScript{
+				Stdout:   []string{"[youtube] abc: Downloading webpage"},
+				ExitCode: consts.YT_DLP_EXIT_CODE_MAX_DOWNLOADS,
+			},
+			wantErr: fmt.Sprintf(consts.ERR_YT_DLP_EXIT_CODE, consts.YT_DLP_EXIT_CODE_MAX_DOWNLOADS, "[youtube] abc: Downloading webpage"),
+		},
+		{
+			name: "only the last stdout lines",
+			script: fakeScript{
+				Stdout:   []string{"1", "2", "3", "4", "5", "6", "7"},
+				ExitCode: 1,
+			},
+			wantErr: fmt.Sprintf(consts.ERR_YT_DLP_EXIT_CODE, 1, "3; 4; 5; 6; 7"),
+		},
+		{
+			name:    "no output",
+			script:  fakeScript{ExitCode: 1},
+			wantErr: fmt.Sprintf(consts.ERR_YT_DLP_EXIT_CODE, 1, ""),
+		},
+		{
+			name: "unavailable on stdout",
+			script: fakeScript{
+				Stdout:   []string{"ERROR: [youtube] abc: Video unavailable"},
+				ExitCode: 1,
+			},
+			wantErr: consts.ERR_VIDEO_UNAVAILABLE,
+		},
+		{
+			name:    "forbidden on stderr",
+			script:  fakeScript{Stderr: []string{"ERROR: HTTP Error 403: Forbidden"}, ExitCode: 1},
+			wantErr: consts.ERR_FORBIDDEN,
+		},
+		{
+			name:    "sign in on stderr",
+			script:  fakeScript{Stderr: []string{"ERROR: [youtube] abc: Sign in to confirm you're not a bot"}, ExitCode: 1},
+			wantErr: consts.ERR_VIDEO_LOGIN_REQUIRED,
+		},
+		{
+			name:    "other failure",
+			script:  fakeScript{Stderr: []string{"ERROR: Postprocessing: audio conversion failed"}, ExitCode: 1},
+			wantErr: "ERROR: Postprocessing: audio conversion failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, output, err := executeAudioExtractionProcess(newFakeYtDlp(t, tt.script), nil, nil, nil)
+			if len(output.tail) > consts.AUDIO_OUTPUT_TAIL_LINES {
+				t.Errorf("kept %d stdout lines, want at most %d", len(output.tail), consts.AUDIO_OUTPUT_TAIL_LINES)
+			}
+			if err != nil {
+				err = validateAudioExtractionResult(err, output)
+			}
+			checkError(t, err, tt.wantErr)
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+		})
+	}
+}
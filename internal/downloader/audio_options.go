@@ -0,0 +1,113 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var audioQualityRegex = regexp.MustCompile(consts.AUDIO_QUALITY_REGEX)
+
+// resolveAudioOptions fills in the defaults of an audio extraction: MP3 at
+// the best VBR level. A bare bitrate such as "320" is read as kbit/s, and
+// quality is dropped for formats it does not apply to.
+func resolveAudioOptions(req models.DownloadRequest) models.AudioOptions {
+	var opts models.AudioOptions
+	if req.Audio != nil {
+		opts = *req.Audio
+	}
+	opts.Format = strings.ToLower(strings.TrimSpace(opts.Format))
+	if opts.Format == "" {
+		opts.Format = consts.AUDIO_FORMAT_MP3
+	}
+	if opts.Quality == "" {
+		opts.Quality = req.Quality
+	}
+	opts.Quality = strings.TrimSpace(opts.Quality)
+
+	switch {
+	case opts.Format == consts.AUDIO_FORMAT_ORIGINAL || containsString(consts.AUDIO_LOSSLESS_FORMATS, opts.Format):
+		opts.Quality = ""
+	case opts.Quality == "" || strings.EqualFold(opts.Quality, consts.BEST_QUALITY):
+		opts.Quality = consts.AUDIO_QUALITY_BEST
+	default:
+		if kbps, err := strconv.Atoi(opts.Quality); err == nil && kbps > 10 {
+			opts.Quality += "K"
+		}
+	}
+	return opts
+}
+
+func validateAudioOptions(opts models.AudioOptions) error {
+	if opts.Format == consts.AUDIO_FORMAT_ORIGINAL {
+		if opts.SampleRate != 0 || opts.Channels != 0 {
+			return fmt.Errorf(consts.ERR_ORIGINAL_AUDIO_OPTIONS)
+		}
+		return nil
+	}
+	if _, ok := consts.AUDIO_FORMATS[opts.Format]; !ok {
+		return fmt.Errorf(consts.ERR_INVALID_AUDIO_FORMAT, opts.Format)
+	}
+	if opts.Quality != "" && !audioQualityRegex.MatchString(opts.Quality) {
+		return fmt.Errorf(consts.ERR_INVALID_AUDIO_QUALITY, opts.Quality)
+	}
+	if opts.SampleRate != 0 {
+		rates := consts.AUDIO_SAMPLE_RATES
+		if opts.Format == consts.AUDIO_FORMAT_OPUS {
+			rates = consts.OPUS_SAMPLE_RATES
+		}
+		if !containsInt(rates, opts.SampleRate) {
+			return fmt.Errorf(consts.ERR_INVALID_SAMPLE_RATE, opts.SampleRate, opts.Format)
+		}
+	}
+	if opts.Channels < 0 || opts.Channels > consts.AUDIO_MAX_CHANNELS {
+		return fmt.Errorf(consts.ERR_INVALID_CHANNELS)
+	}
+	return nil
+}
+
+// audioArgs returns the yt-dlp options for resolved audio options. Sample
+// rate and channels are handed to ffmpeg through the ExtractAudio
+// postprocessor.
+func audioArgs(opts models.AudioOptions) []string {
+	format := consts.YT_DLP_AUDIO_FORMAT_BEST
+	if opts.Format != consts.AUDIO_FORMAT_ORIGINAL {
+		format = consts.AUDIO_FORMATS[opts.Format]
+	}
+	args := []string{consts.EXTRACT_AUDIO_FLAG, consts.AUDIO_FORMAT_FLAG, format}
+	if opts.Quality != "" {
+		args = append(args, consts.AUDIO_QUALITY_FLAG, opts.Quality)
+	}
+
+	var ffmpegArgs []string
+	if opts.SampleRate != 0 {
+		ffmpegArgs = append(ffmpegArgs, consts.FFMPEG_SAMPLE_RATE_FLAG, strconv.Itoa(opts.SampleRate))
+	}
+	if opts.Channels != 0 {
+		ffmpegArgs = append(ffmpegArgs, consts.FFMPEG_CHANNELS_FLAG, strconv.Itoa(opts.Channels))
+	}
+	if len(ffmpegArgs) > 0 {
+		args = append(args, consts.POSTPROCESSOR_ARGS_FLAG, fmt.Sprintf(consts.EXTRACT_AUDIO_PP_ARGS, strings.Join(ffmpegArgs, " ")))
+	}
+	return args
+}
+
+// audioLabel describes the options in the job's quality column.
+func audioLabel(opts models.AudioOptions) string {
+	if opts.Quality == "" {
+		return opts.Format
+	}
+	return fmt.Sprintf(consts.AUDIO_LABEL_FORMAT, opts.Format, opts.Quality)
+}
+
+func containsInt(values []int, value int) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+)
+
+var chapterNameRegex = regexp.MustCompile(consts.CHAPTER_NAME_REGEX)
+
+// chapterArgs returns the yt-dlp options for a chapter mode. Split chapters
+// are written to their own directory in the workspace next to the full file,
+// which yt-dlp keeps.
+func chapterArgs(workspace, mode string) []string {
+	switch mode {
+	case consts.CHAPTER_MODE_EMBED:
+		return []string{consts.EMBED_CHAPTERS_FLAG}
+	case consts.CHAPTER_MODE_SPLIT:
+		output := consts.YT_DLP_CHAPTER_OUTPUT_TYPE + filepath.Join(workspace, consts.CHAPTERS_DIR, consts.YT_DLP_CHAPTER_OUTPUT_FORMAT)
+		return []string{consts.SPLIT_CHAPTERS_FLAG, "-o", output}
+	}
+	return nil
+}
+
+func validateChapterMode(req models.DownloadRequest) error {
+	switch req.Chapters {
+	case "", consts.CHAPTER_MODE_EMBED:
+	case consts.CHAPTER_MODE_SPLIT:
+		if req.Clip != nil {
+			return fmt.Errorf(consts.ERR_CHAPTERS_WITH_CLIP)
+		}
+	default:
+		return fmt.Errorf(consts.ERR_INVALID_CHAPTER_MODE, req.Chapters)
+	}
+	return nil
+}
+
+func extractChapters(info *ytDlpInfo) []models.Chapter {
+	chapters := make([]models.Chapter, 0, len(info.Chapters))
+	for _, chapter := range info.Chapters {
+		chapters = append(chapters, models.Chapter{Title: chapter.Title, Start: chapter.StartTime, End: chapter.EndTime})
+	}
+	return chapters
+}
+
+// finishChapters tags the files yt-dlp split a download into as tracks of an
+// album named after the video, and returns them in order. Audio jobs pass
+// the tags shared by every track and a cover. It returns no result when the
+// video had no chapters to split, so the caller can keep the whole file
+// instead.
+func finishChapters(deps *dependencies.Resolver, workspace, title string, album *models.AudioTags, cover string, progressCallback ProgressCallback, processCallback ProcessCallback) (*YtDlpResult, error) {
+	files := findMediaFiles(filepath.Join(workspace, consts.CHAPTERS_DIR))
+	if len(files) == 0 {
+		log.Printf(consts.WARNING_NO_CHAPTERS, title)
+		return nil, nil
+	}
+
+	ffmpegPath, err := deps.FFmpegPath()
+	if err != nil {
+		return nil, fmt.Errorf(consts.ERR_CHAPTERS_NEED_FFMPEG, err)
+	}
+
+	for i, file := range files {
+		if err := tagChapter(ffmpegPath, file, i+1, len(files), album, cover, processCallback); err != nil {
+			return nil, err
+		}
+		if progressCallback != nil {
+			progressCallback(models.ProgressUpdate{
+				Phase:    consts.PHASE_SPLIT_CHAPTERS,
+				Progress: float64(i+1) / float64(len(files)) * 100,
+				Message:  fmt.Sprintf(consts.MSG_PHASE_TAG_CHAPTERS, i+1, len(files)),
+			})
+		}
+	}
+
+	return &YtDlpResult{
+		Title:    title,
+		FilePath: files[0],
+		Sidecars: append(files[1:], findSubtitleFiles(workspace)...),
+		TempDir:  workspace,
+		Success:  true,
+	}, nil
+}
+
+// tagChapter sets the title, album and track number of a chapter file on
+// top of the album's tags. Without an album name the video title from the
+// file name is used.
+func tagChapter(ffmpegPath, file string, track, total int, album *models.AudioTags, cover string, processCallback ProcessCallback) error {
+	var tags models.AudioTags
+	if album != nil {
+		tags = *album
+	}
+	videoTitle := fileStem(filepath.Base(file))
+	if matches := chapterNameRegex.FindStringSubmatch(videoTitle); matches != nil {
+		videoTitle, tags.Title = matches[1], matches[3]
+	}
+	if tags.Album == "" {
+		tags.Album = videoTitle
+	}
+	tags.Track = fmt.Sprintf(consts.TAG_TRACK_FORMAT, track, total)
+
+	return writeTags(ffmpegPath, file, tags, cover, processCallback)
+}
+
+// bundleName names the zip a job's files are delivered in: after the main
+// file, or after the video when it was split into chapters.
+func bundleName(result *YtDlpResult, req models.DownloadRequest) string {
+	if req.Chapters == consts.CHAPTER_MODE_SPLIT && result.Title != "" {
+		return result.Title
+	}
+	return fileStem(filepath.Base(result.FilePath))
+}
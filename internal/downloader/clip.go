@@ -0,0 +1,299 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/models"
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var clipNameRegex = regexp.MustCompile(consts.CLIP_NAME_REGEX)
+
+// clipSection is the part of the video a clip file was cut from, read back
+// from the name yt-dlp gave it. An open end is +Inf.
+type clipSection struct {
+	path  string
+	title string
+	start float64
+	end   float64
+}
+
+// clipArgs returns the yt-dlp options that download only the requested
+// sections. Chapter titles are matched exactly.
+func clipArgs(clip *models.ClipOptions) []string {
+	if clip == nil {
+		return nil
+	}
+
+	var args []string
+	for _, clipRange := range clip.Ranges {
+		start, _ := parseTimestamp(clipRange.Start)
+		end := consts.CLIP_OPEN_END
+		if clipRange.End != "" {
+			seconds, _ := parseTimestamp(clipRange.End)
+			end = formatSeconds(seconds)
+		}
+		args = append(args, consts.DOWNLOAD_SECTIONS_FLAG, fmt.Sprintf(consts.CLIP_RANGE_FORMAT, formatSeconds(start), end))
+	}
+	for _, chapter := range clip.Chapters {
+		args = append(args, consts.DOWNLOAD_SECTIONS_FLAG, fmt.Sprintf(consts.CLIP_CHAPTER_FORMAT, regexp.QuoteMeta(chapter)))
+	}
+	if clip.Accurate {
+		args = append(args, consts.FORCE_KEYFRAMES_FLAG)
+	}
+	return args
+}
+
+func validateClipOptions(clip models.ClipOptions) error {
+	if len(clip.Ranges) == 0 && len(clip.Chapters) == 0 {
+		return fmt.Errorf(consts.ERR_CLIP_EMPTY)
+	}
+
+	for _, clipRange := range clip.Ranges {
+		start, err := parseTimestamp(clipRange.Start)
+		if err != nil {
+			return err
+		}
+		if clipRange.End == "" {
+			continue
+		}
+		end, err := parseTimestamp(clipRange.End)
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			return fmt.Errorf(consts.ERR_CLIP_RANGE, clipRange.Start, clipRange.End)
+		}
+	}
+
+	for _, chapter := range clip.Chapters {
+		if strings.TrimSpace(chapter) == "" {
+			return fmt.Errorf(consts.ERR_CLIP_EMPTY)
+		}
+	}
+	return nil
+}
+
+// parseTimestamp accepts seconds, MM:SS or HH:MM:SS, the last field with
+// optional fractional seconds. An empty timestamp is the start.
+func parseTimestamp(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	fields := strings.Split(value, consts.TIMESTAMP_SEPARATOR)
+	if len(fields) > 3 {
+		return 0, fmt.Errorf(consts.ERR_CLIP_TIMESTAMP, value)
+	}
+
+	var seconds float64
+	for i, field := range fields {
+		last := i == len(fields)-1
+		n, err := strconv.ParseFloat(field, 64)
+		if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) ||
+			(!last && strings.Contains(field, ".")) || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf(consts.ERR_CLIP_TIMESTAMP, value)
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, nil
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
+
+// finishClips checks the clips yt-dlp wrote. yt-dlp quietly downloads the
+// whole video when a format cannot be fetched in sections, so a clip that is
+// clearly longer than its section is trimmed here with ffmpeg. Open-ended
+// sections cannot be checked this way and are kept as downloaded.
+func finishClips(deps *dependencies.Resolver, workspace, title string, clip *models.ClipOptions, progressCallback ProgressCallback, processCallback ProcessCallback) (*YtDlpResult, error) {
+	var sections []clipSection
+	for _, file := range findMediaFiles(workspace) {
+		if section, ok := parseClipName(file); ok {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf(consts.ERR_FIND_DOWNLOADED_FILE, fmt.Errorf(consts.ERR_NO_VIDEO_FILE, workspace))
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].start < sections[j].start
+	})
+
+	ffprobePath, err := deps.FFprobePath()
+	if err != nil {
+		log.Printf(consts.WARNING_CLIP_NOT_CHECKED, err)
+	}
+
+	files := make([]string, 0, len(sections))
+	for i, section := range sections {
+		if ffprobePath != "" {
+			report := func(progress float64) {
+				if progressCallback != nil {
+					progressCallback(models.ProgressUpdate{
+						Phase:    consts.PHASE_CLIP,
+						Progress: progress,
+						Message:  fmt.Sprintf(consts.MSG_PHASE_CLIP, i+1, len(sections)),
+					})
+				}
+			}
+			if section.path, err = trimUncutClip(deps, ffprobePath, section, clip.Accurate, report, processCallback); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, renameClip(section))
+	}
+
+	return &YtDlpResult{
+		Title:    title,
+		FilePath: files[0],
+		Sidecars: append(files[1:], findSubtitleFiles(workspace)...),
+		TempDir:  workspace,
+		Success:  true,
+	}, nil
+}
+
+func parseClipName(file string) (clipSection, bool) {
+	matches := clipNameRegex.FindStringSubmatch(fileStem(filepath.Base(file)))
+	if matches == nil {
+		return clipSection{}, false
+	}
+
+	section := clipSection{path: file, title: matches[1], end: math.Inf(1)}
+	section.start, _ = strconv.ParseFloat(matches[2], 64)
+	if matches[3] != consts.CLIP_OPEN_END {
+		section.end, _ = strconv.ParseFloat(matches[3], 64)
+	}
+	return section, true
+}
+
+// trimUncutClip returns the path of the clip, trimmed first if yt-dlp left
+// it longer than its section.
+func trimUncutClip(deps *dependencies.Resolver, ffprobePath string, section clipSection, accurate bool, report func(float64), processCallback ProcessCallback) (string, error) {
+	duration, err := probeDuration(ffprobePath, section.path)
+	if err != nil {
+		return "", err
+	}
+	length := section.end - section.start
+	if duration <= length+consts.CLIP_DURATION_TOLERANCE {
+		return section.path, nil
+	}
+
+	ffmpegPath, err := deps.FFmpegPath()
+	if err != nil {
+		return "", fmt.Errorf(consts.ERR_CLIP_NEEDS_FFMPEG, err)
+	}
+	log.Printf(consts.LOG_TRIMMING_CLIP, filepath.Base(section.path), formatSeconds(section.start), formatSeconds(section.end))
+	return trimClip(ffmpegPath, section, accurate, math.Min(length, duration-section.start), report, processCallback)
+}
+
+func probeDuration(ffprobePath, file string) (float64, error) {
+	output, err := exec.Command(ffprobePath, append(append([]string{}, consts.FFMPEG_PROBE_DURATION_ARGS...), file)...).Output()
+	if err != nil {
+		return 0, fmt.Errorf(consts.ERR_PROBE_DURATION, filepath.Base(file), err)
+	}
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf(consts.ERR_PROBE_DURATION, filepath.Base(file), err)
+	}
+	return duration, nil
+}
+
+// trimClip cuts the section out of a full download with ffmpeg, reporting
+// progress against the expected clip length, and replaces the file with it.
+func trimClip(ffmpegPath string, section clipSection, accurate bool, length float64, report func(float64), processCallback ProcessCallback) (string, error) {
+	ext := filepath.Ext(section.path)
+	if accurate && containsString(consts.FFMPEG_CLIP_ENCODE_INCOMPATIBLE_EXTENSIONS, strings.ToLower(ext)) {
+		ext = "." + consts.CONTAINER_MKV
+	}
+	output := fileStem(section.path) + consts.CLIP_TRIM_SUFFIX + ext
+
+	args := append([]string{}, consts.FFMPEG_PROGRESS_ARGS...)
+	args = append(args, consts.FFMPEG_SEEK_FLAG, formatSeconds(section.start), consts.FFMPEG_INPUT_FLAG, section.path)
+	if !math.IsInf(section.end, 1) {
+		args = append(args, consts.FFMPEG_DURATION_FLAG, formatSeconds(section.end-section.start))
+	}
+	if accurate {
+		args = append(args, consts.FFMPEG_CLIP_ENCODE_ARGS...)
+	} else {
+		args = append(args, consts.FFMPEG_CLIP_COPY_ARGS...)
+	}
+	args = append(args, output)
+
+	if err := runFFmpegWithProgress(ffmpegPath, args, length, report, processCallback); err != nil {
+		os.Remove(output)
+		return "", fmt.Errorf(consts.ERR_TRIM_CLIP, filepath.Base(section.path), err)
+	}
+
+	trimmed := fileStem(section.path) + ext
+	os.Remove(section.path)
+	if err := os.Rename(output, trimmed); err != nil {
+		return "", fmt.Errorf(consts.ERR_TRIM_CLIP, filepath.Base(section.path), err)
+	}
+	return trimmed, nil
+}
+
+// runFFmpegWithProgress runs ffmpeg with -progress on stdout and reports how
+// far it is through length seconds of output. A zero length reports nothing.
+func runFFmpegWithProgress(ffmpegPath string, args []string, length float64, report func(float64), processCallback ProcessCallback) error {
+	cmd := exec.Command(ffmpegPath, args...)
+	configureProcess(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf(consts.ERR_CREATE_STDOUT_PIPE, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if processCallback != nil {
+		processCallback(cmd)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		value, ok := strings.CutPrefix(line, consts.FFMPEG_PROGRESS_OUT_TIME)
+		if !ok {
+			value, ok = strings.CutPrefix(line, consts.FFMPEG_PROGRESS_OUT_TIME_OLD)
+		}
+		if micros, err := strconv.ParseFloat(value, 64); ok && err == nil && length > 0 {
+			report(math.Min(micros/1e6/length*100, 100))
+		}
+	}
+
+	return cmd.Wait()
+}
+
+// renameClip names a clip after its section as HH.MM.SS, e.g.
+// "Title [00.01.30-00.02.45].mp4".
+func renameClip(section clipSection) string {
+	end := consts.CLIP_TIME_END
+	if !math.IsInf(section.end, 1) {
+		end = clipTime(section.end)
+	}
+	name := fmt.Sprintf(consts.CLIP_NAME_FORMAT, section.title, clipTime(section.start), end, filepath.Ext(section.path))
+
+	renamed := filepath.Join(filepath.Dir(section.path), name)
+	if err := os.Rename(section.path, renamed); err != nil {
+		log.Printf(consts.ERR_RENAME_CLIP, err)
+		return section.path
+	}
+	return renamed
+}
+
+func clipTime(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf(consts.CLIP_TIME_FORMAT, total/3600, total%3600/60, total%60)
+}
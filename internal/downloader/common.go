@@ -1,146 +1,107 @@
 package downloader
 
 import (
+	"Go-Utilities/internal/config"
 	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"Go-Utilities/internal/sites"
 	"fmt"
-	"log"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
+// fragmentFileRegex matches the single-format streams yt-dlp writes before
+// merging, such as "title.f137.mp4".
+var fragmentFileRegex = regexp.MustCompile(consts.YT_DLP_FRAGMENT_FILE_REGEX)
+
 type YtDlpResult struct {
 	Title    string
 	FilePath string
+	// Sidecars are files delivered alongside FilePath, such as subtitles.
+	Sidecars []string
 	TempDir  string
 	Success  bool
 	Error    string
 }
 
-type ProgressCallback func(progress float64, speed, eta, message string)
+// ProgressCallback receives progress parsed from yt-dlp. The ID and status
+// are left empty for the caller to fill in.
+type ProgressCallback func(update models.ProgressUpdate)
 
-func getYtDlpPath() (string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	ytDlpPath := filepath.Join(wd, consts.DEPENDENCIES_DIR, consts.YT_DLP_EXE_NAME)
-	if _, err := os.Stat(ytDlpPath); os.IsNotExist(err) {
-		return "", fmt.Errorf(consts.ERR_YT_DLP_NOT_FOUND, ytDlpPath)
-	}
-	return ytDlpPath, nil
-}
+// ProcessCallback receives the yt-dlp process as soon as it has started so
+// the caller can cancel, suspend or resume it.
+type ProcessCallback func(cmd *exec.Cmd)
 
-func getFFmpegPath() (string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", err
+// networkArgs returns the configured options that shape how yt-dlp talks to
+// the site, along with the site's own options, appended to every command.
+func networkArgs(cfg *config.Config, rawURL string) []string {
+	args := []string{consts.USER_AGENT_FLAG, cfg.YtDlp.UserAgent}
+	if cfg.YtDlp.GeoBypassCountry != "" {
+		args = append(args, consts.GEO_BYPASS_COUNTRY_FLAG, cfg.YtDlp.GeoBypassCountry)
 	}
-	ffmpegPath := filepath.Join(wd, consts.DEPENDENCIES_DIR, consts.FFMPEG_EXE_NAME)
-	if _, err := os.Stat(ffmpegPath); os.IsNotExist(err) {
-		return "", fmt.Errorf(consts.ERR_FFMPEG_NOT_FOUND, ffmpegPath)
+	if cfg.YtDlp.Proxy != "" {
+		args = append(args, consts.PROXY_FLAG, cfg.YtDlp.Proxy)
 	}
-	return ffmpegPath, nil
-}
-
-func TestYtDlp() error {
-	ytDlpPath, err := getYtDlpPath()
-	if err != nil {
-		return err
-	}
-	cmd := exec.Command(ytDlpPath, consts.YT_DLP_VERSION_FLAG)
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf(consts.ERR_YT_DLP_TEST_FAILED, err)
-	}
-	version := strings.TrimSpace(string(output))
-	log.Printf(consts.LOG_YT_DLP_VERSION, version)
-	if len(version) > 0 && version < consts.MIN_YTDLP_YEAR {
-		log.Printf(consts.WARNING_YT_DLP_OUTDATED)
-	}
-	return nil
+	return append(args, sites.Args(rawURL)...)
 }
 
+// findDownloadedFile picks the largest finished media file in a job
+// workspace, skipping partial downloads, fragments and written thumbnails.
 func findDownloadedFile(dir string) (string, error) {
 	files, err := filepath.Glob(filepath.Join(dir, "*"))
 	if err != nil {
 		return "", err
 	}
 
+	var bestFile string
+	var bestSize int64 = -1
 	for _, file := range files {
-		if info, err := os.Stat(file); err == nil && !info.IsDir() {
-			name := strings.ToLower(filepath.Base(file))
-			if !strings.Contains(name, consts.FRAGMENT_EXT) && !strings.Contains(name, consts.TEMP_EXT) {
-				return file, nil
-			}
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() || !isFinishedMediaFile(file) {
+			continue
 		}
-	}
-
-	return "", fmt.Errorf(consts.ERR_NO_VIDEO_FILE, dir)
-}
-
-func extractVideoID(parsedURL *url.URL) string {
-	host := strings.ToLower(parsedURL.Host)
-	
-	if strings.Contains(host, consts.YOUTU_BE_DOMAIN) {
-		return strings.TrimPrefix(parsedURL.Path, consts.URL_PATH_SEPARATOR)
-	}
-	
-	videoID := parsedURL.Query().Get(consts.URL_VIDEO_PARAM)
-	if videoID == "" {
-		if strings.Contains(parsedURL.Path, consts.YOUTUBE_EMBED_PATH) {
-			parts := strings.Split(parsedURL.Path, consts.YOUTUBE_EMBED_PATH)
-			if len(parts) > 1 {
-				videoID = strings.Split(parts[1], consts.URL_PATH_SEPARATOR)[0]
-			}
+		if info.Size() > bestSize {
+			bestFile, bestSize = file, info.Size()
 		}
 	}
-	
-	return videoID
-}
 
-func ParseYouTubeURL(inputURL string) (string, error) {
-	parsedURL, err := url.Parse(inputURL)
-	if err != nil {
-		return "", fmt.Errorf(consts.ERR_INVALID_URL, err)
+	if bestFile == "" {
+		return "", fmt.Errorf(consts.ERR_NO_VIDEO_FILE, dir)
 	}
+	return bestFile, nil
+}
 
-	host := strings.ToLower(parsedURL.Host)
-	if !strings.Contains(host, consts.YOUTUBE_DOMAIN) && !strings.Contains(host, consts.YOUTU_BE_DOMAIN) {
-		return "", fmt.Errorf(consts.ERR_NOT_YOUTUBE_URL)
-	}
+// findMediaFiles lists every finished media file in a job workspace.
+func findMediaFiles(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
 
-	videoID := extractVideoID(parsedURL)
-	if videoID == "" {
-		return "", fmt.Errorf(consts.ERR_EXTRACT_VIDEO_ID)
+	var media []string
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && !info.IsDir() && isFinishedMediaFile(file) {
+			media = append(media, file)
+		}
 	}
-
-	return fmt.Sprintf(consts.YOUTUBE_WATCH_URL, videoID), nil
+	return media
 }
 
-func cleanYouTubeURL(rawURL string) (string, error) {
-	parsedURL, err := url.Parse(rawURL)
-	if err != nil {
-		return "", err
+func isFinishedMediaFile(file string) bool {
+	name := strings.ToLower(filepath.Base(file))
+	if fragmentFileRegex.MatchString(name) || strings.Contains(name, consts.TEMP_EXT) {
+		return false
 	}
-
-	videoID := extractVideoID(parsedURL)
-	if videoID == "" {
-		return "", fmt.Errorf(consts.ERR_MISSING_V_PARAM)
+	for _, ext := range consts.NON_MEDIA_EXTENSIONS {
+		if strings.HasSuffix(name, ext) {
+			return false
+		}
 	}
-
-	return fmt.Sprintf(consts.YOUTUBE_WATCH_URL, videoID), nil
-}
-
-func parseFloat(s string) float64 {
-	var f float64
-	fmt.Sscanf(s, "%f", &f)
-	return f
+	return !isSubtitleFile(file)
 }
 
-func formatFileSize(bytes int64) string {
+// FormatFileSize renders a byte count with a binary unit, as shown in the UI.
+func FormatFileSize(bytes int64) string {
 	const unit = consts.BYTES_UNIT
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
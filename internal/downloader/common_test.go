@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindDownloadedFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]int
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "single file",
+			files: map[string]int{"Title.mp4": 10},
+			want:  "Title.mp4",
+		},
+		{
+			name:  "largest media file",
+			files: map[string]int{"Title - 1.mp4": 10, "Title - 2.mp4": 30, "Title - 3.mp4": 20},
+			want:  "Title - 2.mp4",
+		},
+		{
+			name: "skips partial and intermediate files",
+			files: map[string]int{
+				"Title.mp4":           10,
+				"Title.mp4.part":      50,
+				"Title.mp4.ytdl":      50,
+				"Title.f137.mp4":      50,
+				"Title.f140.m4a":      50,
+				"Title.temp.mp4":      50,
+				"Title.webp":          50,
+				"Title.info.json":     50,
+				"Title.description":   50,
+				"Title.en.vtt":        50,
+				"Title.live_chat.srt": 50,
+			},
+			want: "Title.mp4",
+		},
+		{
+			name:  "audio formats that start like a fragment",
+			files: map[string]int{"Song.flac": 10, "Song.jpg": 20},
+			want:  "Song.flac",
+		},
+		{
+			name:    "nothing finished",
+			files:   map[string]int{"Title.mp4.part": 10, "Title.jpg": 10},
+			wantErr: true,
+		},
+		{
+			name:    "empty workspace",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, size := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("x", size)), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := findDownloadedFile(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findDownloadedFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != filepath.Join(dir, tt.want) {
+				t.Errorf("findDownloadedFile() = %q, want %q", filepath.Base(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"errors"
+	"regexp"
+	"strings"
+	"syscall"
+)
+
+// JobError is a failure sorted into a stable code that clients can act on
+// without reading the message.
+type JobError struct {
+	Code      string
+	Message   string
+	Hint      string
+	Retryable bool
+	Err       error
+}
+
+func (e *JobError) Error() string {
+	return e.Message
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+var errorPatterns = compileErrorPatterns()
+
+func compileErrorPatterns() map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp, len(consts.ERROR_OUTPUT_PATTERNS))
+	for code, pattern := range consts.ERROR_OUTPUT_PATTERNS {
+		patterns[code] = regexp.MustCompile(`(?i)` + pattern)
+	}
+	return patterns
+}
+
+// newJobError fills in the hint for code and whether a retry may help.
+func newJobError(code, message string, err error) *JobError {
+	return &JobError{
+		Code:      code,
+		Message:   message,
+		Hint:      consts.ERROR_HINTS[code],
+		Retryable: containsString(consts.RETRYABLE_ERROR_CODES, code),
+		Err:       err,
+	}
+}
+
+// ClassifyError sorts any error into a *JobError. A yt-dlp failure is
+// classified by what yt-dlp printed, anything else by its text.
+func ClassifyError(err error) *JobError {
+	var jobErr *JobError
+	if errors.As(err, &jobErr) {
+		return jobErr
+	}
+	var processErr *ProcessError
+	if errors.As(err, &processErr) {
+		return classifyOutput(processErr.Stderr, err)
+	}
+	if errors.Is(err, syscall.ENOSPC) {
+		return newJobError(consts.ERROR_CODE_DISK_FULL, err.Error(), err)
+	}
+	return classifyText(err.Error(), err)
+}
+
+// classifyOutput classifies yt-dlp output by its ERROR lines, so warnings
+// cannot decide the code, or by all of it when there are none.
+func classifyOutput(output string, err error) *JobError {
+	return classifyText(errorLines(output), err)
+}
+
+// classifyText keeps text as the message of failures that have no message
+// of their own.
+func classifyText(text string, err error) *JobError {
+	code := consts.ERROR_CODE_UNKNOWN
+	for _, candidate := range consts.ERROR_CLASSIFICATION_ORDER {
+		if errorPatterns[candidate].MatchString(text) {
+			code = candidate
+			break
+		}
+	}
+
+	message, ok := consts.ERROR_MESSAGES[code]
+	if !ok {
+		message = text
+	}
+	if message == "" {
+		message = err.Error()
+	}
+	return newJobError(code, message, err)
+}
+
+func errorLines(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, consts.YT_DLP_ERROR_PREFIX) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return strings.TrimSpace(output)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,170 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	stderr := func(lines string) error {
+		return &ProcessError{ExitCode: 1, Stderr: lines, Err: &exec.ExitError{}}
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      string
+		wantMessage   string
+		wantRetryable bool
+	}{
+		{
+			name:        "private video",
+			err:         stderr("ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video"),
+			wantCode:    consts.ERROR_CODE_VIDEO_PRIVATE,
+			wantMessage: consts.ERR_VIDEO_PRIVATE,
+		},
+		{
+			name:        "age-restricted",
+			err:         stderr("ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users."),
+			wantCode:    consts.ERROR_CODE_AGE_RESTRICTED,
+			wantMessage: consts.ERR_VIDEO_AGE_RESTRICTED,
+		},
+		{
+			name:        "bot check",
+			err:         stderr("ERROR: [youtube] abc: Sign in to confirm you're not a bot. Use --cookies-from-browser or --cookies for the authentication."),
+			wantCode:    consts.ERROR_CODE_LOGIN_REQUIRED,
+			wantMessage: consts.ERR_VIDEO_LOGIN_REQUIRED,
+		},
+		{
+			name:        "geo-blocked",
+			err:         stderr("ERROR: [youtube] abc: Video unavailable. The uploader has not made this video available in your country"),
+			wantCode:    consts.ERROR_CODE_GEO_BLOCKED,
+			wantMessage: consts.ERR_VIDEO_GEO_BLOCKED,
+		},
+		{
+			name:        "removed",
+			err:         stderr("ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader"),
+			wantCode:    consts.ERROR_CODE_VIDEO_UNAVAILABLE,
+			wantMessage: consts.ERR_VIDEO_UNAVAILABLE,
+		},
+		{
+			name:          "rate limited",
+			err:           stderr("ERROR: [youtube] abc: HTTP Error 429: Too Many Requests"),
+			wantCode:      consts.ERROR_CODE_RATE_LIMITED,
+			wantMessage:   consts.ERR_RATE_LIMITED,
+			wantRetryable: true,
+		},
+		{
+			name:          "forbidden",
+			err:           stderr("ERROR: unable to download video data: HTTP Error 403: Forbidden"),
+			wantCode:      consts.ERROR_CODE_FORBIDDEN,
+			wantMessage:   consts.ERR_FORBIDDEN,
+			wantRetryable: true,
+		},
+		{
+			name:          "fragments",
+			err:           stderr("ERROR: fragment 4 not found, unable to continue"),
+			wantCode:      consts.ERROR_CODE_FRAGMENTS_FAILED,
+			wantMessage:   consts.ERR_FRAGMENTS_FAILED,
+			wantRetryable: true,
+		},
+		{
+			name:          "socket timeout",
+			err:           stderr("ERROR: [youtube] abc: Unable to download webpage: The read operation timed out"),
+			wantCode:      consts.ERROR_CODE_NETWORK_ERROR,
+			wantMessage:   consts.ERR_NETWORK,
+			wantRetryable: true,
+		},
+		{
+			name:        "format",
+			err:         stderr("ERROR: [youtube] abc: Requested format is not available. Use --list-formats for a list of available formats"),
+			wantCode:    consts.ERROR_CODE_FORMAT_UNAVAILABLE,
+			wantMessage: consts.ERR_FORMAT_UNAVAILABLE,
+		},
+		{
+			name:        "warnings do not decide",
+			err:         stderr("WARNING: [youtube] abc: Some formats are possibly damaged (HTTP Error 403)\nERROR: Postprocessing: Conversion failed!"),
+			wantCode:    consts.ERROR_CODE_UNKNOWN,
+			wantMessage: "ERROR: Postprocessing: Conversion failed!",
+		},
+		{
+			name:        "no output",
+			err:         &ProcessError{ExitCode: 1, Err: errors.New("exit status 1")},
+			wantCode:    consts.ERROR_CODE_UNKNOWN,
+			wantMessage: "exit status 1",
+		},
+		{
+			name:        "ffmpeg missing in yt-dlp",
+			err:         stderr("ERROR: Postprocessing: ffprobe and ffmpeg not found. Please install or provide the path using --ffmpeg-location"),
+			wantCode:    consts.ERROR_CODE_DEPENDENCY_MISSING,
+			wantMessage: "ERROR: Postprocessing: ffprobe and ffmpeg not found. Please install or provide the path using --ffmpeg-location",
+		},
+		{
+			name:        "dependency not resolved",
+			err:         fmt.Errorf(consts.ERR_CHAPTERS_NEED_FFMPEG, fmt.Errorf(consts.ERR_DEPENDENCY_NOT_FOUND, "ffmpeg", "/usr/bin/ffmpeg")),
+			wantCode:    consts.ERROR_CODE_DEPENDENCY_MISSING,
+			wantMessage: fmt.Sprintf(consts.ERR_CHAPTERS_NEED_FFMPEG, fmt.Errorf(consts.ERR_DEPENDENCY_NOT_FOUND, "ffmpeg", "/usr/bin/ffmpeg")),
+		},
+		{
+			name:        "disk full",
+			err:         &os.PathError{Op: "write", Path: "/tmp/job/Title.mp4", Err: syscall.ENOSPC},
+			wantCode:    consts.ERROR_CODE_DISK_FULL,
+			wantMessage: "write /tmp/job/Title.mp4: no space left on device",
+		},
+		{
+			name:        "disk full in yt-dlp",
+			err:         stderr("ERROR: unable to write data: [Errno 28] No space left on device"),
+			wantCode:    consts.ERROR_CODE_DISK_FULL,
+			wantMessage: "ERROR: unable to write data: [Errno 28] No space left on device",
+		},
+		{
+			name:        "already classified",
+			err:         newJobError(consts.ERROR_CODE_CANCELLED, consts.MSG_JOB_CANCELLED, nil),
+			wantCode:    consts.ERROR_CODE_CANCELLED,
+			wantMessage: consts.MSG_JOB_CANCELLED,
+		},
+		{
+			name:        "anything else",
+			err:         errors.New("invalid URL: missing scheme"),
+			wantCode:    consts.ERROR_CODE_UNKNOWN,
+			wantMessage: "invalid URL: missing scheme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if got.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", got.Code, tt.wantCode)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if got.Retryable != tt.wantRetryable {
+				t.Errorf("Retryable = %v, want %v", got.Retryable, tt.wantRetryable)
+			}
+			if got.Hint != consts.ERROR_HINTS[tt.wantCode] {
+				t.Errorf("Hint = %q, want %q", got.Hint, consts.ERROR_HINTS[tt.wantCode])
+			}
+			if !errors.Is(got, tt.err) && got != tt.err {
+				t.Errorf("ClassifyError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestErrorPatternsAreComplete(t *testing.T) {
+	for _, code := range consts.ERROR_CLASSIFICATION_ORDER {
+		if _, ok := errorPatterns[code]; !ok {
+			t.Errorf("no pattern for %s", code)
+		}
+	}
+	if len(consts.ERROR_CLASSIFICATION_ORDER) != len(consts.ERROR_OUTPUT_PATTERNS) {
+		t.Errorf("%d codes are classified but %d have patterns", len(consts.ERROR_CLASSIFICATION_ORDER), len(consts.ERROR_OUTPUT_PATTERNS))
+	}
+}
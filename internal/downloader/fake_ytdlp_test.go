@@ -0,0 +1,91 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The fake yt-dlp is this test binary started again to run only
+// TestFakeYtDlp. It replays a script: the files to write next to the -o
+// output template, the lines to print, how long to keep running and the
+// code to exit with.
+
+const fakeScriptEnv = "GO_UTILITIES_FAKE_YT_DLP_SCRIPT"
+
+type fakeScript struct {
+	Stdout   []string          `json:"stdout"`
+	Stderr   []string          `json:"stderr"`
+	ExitCode int               `json:"exit_code"`
+	Files    map[string]string `json:"files"`
+	Duration time.Duration     `json:"duration"`
+}
+
+type fakeRunner struct {
+	scriptPath string
+}
+
+func (r fakeRunner) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^TestFakeYtDlp$", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), fakeScriptEnv+"="+r.scriptPath)
+	return cmd
+}
+
+// newFakeYtDlp returns a client whose every run replays script.
+func newFakeYtDlp(t *testing.T, script fakeScript) YtDlpClient {
+	t.Helper()
+	data, err := json.Marshal(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "script.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	locate := func() (string, error) { return "yt-dlp", nil }
+	return NewYtDlpClient(locate, fakeRunner{scriptPath: path})
+}
+
+func TestFakeYtDlp(t *testing.T) {
+	path := os.Getenv(fakeScriptEnv)
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var script fakeScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	dir := "."
+	for i, arg := range os.Args {
+		if arg == "-o" && i+1 < len(os.Args) {
+			dir = filepath.Dir(os.Args[i+1])
+		}
+	}
+	for name, content := range script.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	for _, line := range script.Stdout {
+		fmt.Fprintln(os.Stdout, line)
+	}
+	for _, line := range script.Stderr {
+		fmt.Fprintln(os.Stderr, line)
+	}
+	time.Sleep(script.Duration)
+	os.Exit(script.ExitCode)
+}
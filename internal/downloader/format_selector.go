@@ -0,0 +1,170 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	formatIDRegex    = regexp.MustCompile(consts.FORMAT_ID_REGEX)
+	maxFileSizeRegex = regexp.MustCompile(consts.MAX_FILESIZE_REGEX)
+)
+
+// formatSelector returns the yt-dlp -f value for a video download.
+// Explicit format IDs come first, then quality preferences; otherwise the
+// quality is a height such as "720p", a format ID or "best". A video
+// format alone is merged with the best audio, if it needs any.
+func formatSelector(req models.DownloadRequest) string {
+	switch {
+	case req.VideoFormatID != "" && req.AudioFormatID != "":
+		return fmt.Sprintf(consts.FORMAT_MERGE_FORMAT, req.VideoFormatID, req.AudioFormatID)
+	case req.VideoFormatID != "":
+		return fmt.Sprintf(consts.QUALITY_CUSTOM_FORMAT, req.VideoFormatID, req.VideoFormatID, req.VideoFormatID)
+	case req.AudioFormatID != "":
+		return fmt.Sprintf(consts.FORMAT_BEST_VIDEO_WITH_AUDIO, req.AudioFormatID)
+	case req.Preferences != nil:
+		return buildFormatSelector(*req.Preferences, req.Container, qualityHeight(req.Quality))
+	}
+	return qualityFormat(req.Quality)
+}
+
+func qualityFormat(quality string) string {
+	if quality == "" || quality == consts.BEST_QUALITY {
+		return consts.QUALITY_BEST_FORMAT
+	}
+	if strings.HasSuffix(quality, consts.QUALITY_SUFFIX) {
+		heightLimit := strings.TrimSuffix(quality, consts.QUALITY_SUFFIX)
+		return fmt.Sprintf(consts.QUALITY_HEIGHT_FORMAT, heightLimit, heightLimit, heightLimit)
+	}
+	return fmt.Sprintf(consts.QUALITY_CUSTOM_FORMAT, quality, quality, quality)
+}
+
+// qualityHeight reads the height out of a quality such as "720p". It is 0
+// for "best" and anything that is not a height.
+func qualityHeight(quality string) int {
+	if !strings.HasSuffix(quality, consts.QUALITY_SUFFIX) {
+		return 0
+	}
+	height, err := strconv.Atoi(strings.TrimSuffix(quality, consts.QUALITY_SUFFIX))
+	if err != nil || height < 0 {
+		return 0
+	}
+	return height
+}
+
+// buildFormatSelector turns quality preferences into a chain of yt-dlp
+// alternatives: each preferred codec in turn, then any codec, and last the
+// best single file. Within a codec, streams that fit the container without
+// remuxing come first. The limits apply to every alternative, so the
+// download fails rather than ignore them.
+func buildFormatSelector(prefs models.QualityPreferences, container string, height int) string {
+	var limits, videoSize, audioSize, size string
+	if height > 0 {
+		limits += fmt.Sprintf(consts.FORMAT_HEIGHT_FILTER, height)
+	}
+	if prefs.MaxFPS > 0 {
+		limits += fmt.Sprintf(consts.FORMAT_FPS_FILTER, prefs.MaxFPS)
+	}
+	if prefs.ExcludeHDR {
+		limits += consts.FORMAT_SDR_FILTER
+	}
+	if prefs.MaxFileSize != "" {
+		video, audio := splitFileSize(prefs.MaxFileSize)
+		videoSize = fmt.Sprintf(consts.FORMAT_SIZE_FILTER, video, video)
+		audioSize = fmt.Sprintf(consts.FORMAT_SIZE_FILTER, audio, audio)
+		size = fmt.Sprintf(consts.FORMAT_SIZE_FILTER, prefs.MaxFileSize, prefs.MaxFileSize)
+	}
+
+	// The default download arguments merge into MP4.
+	if container == "" {
+		container = consts.CONTAINER_MP4
+	}
+	audioExt, native := consts.CONTAINER_AUDIO_EXTENSIONS[container]
+
+	codecs := make([]string, 0, len(prefs.Codecs)+1)
+	for _, codec := range prefs.Codecs {
+		codecs = append(codecs, fmt.Sprintf(consts.FORMAT_CODEC_FILTER, consts.VIDEO_CODEC_PATTERNS[codec]))
+	}
+	codecs = append(codecs, "")
+
+	var alternatives []string
+	for _, codec := range codecs {
+		video := consts.FORMAT_BEST_VIDEO + limits + codec + videoSize
+		audio := consts.FORMAT_BEST_AUDIO + audioSize
+		if native {
+			alternatives = append(alternatives, fmt.Sprintf(consts.FORMAT_MERGE_FORMAT,
+				video+fmt.Sprintf(consts.FORMAT_EXT_FILTER, container),
+				audio+fmt.Sprintf(consts.FORMAT_EXT_FILTER, audioExt)))
+		}
+		// WebM only holds its own streams.
+		if container != consts.CONTAINER_WEBM {
+			alternatives = append(alternatives, fmt.Sprintf(consts.FORMAT_MERGE_FORMAT, video, audio))
+		}
+	}
+
+	best := consts.FORMAT_BEST + limits + size
+	if container == consts.CONTAINER_WEBM {
+		best += fmt.Sprintf(consts.FORMAT_EXT_FILTER, container)
+	}
+	return strings.Join(append(alternatives, best), consts.FORMAT_ALTERNATIVE_SEPARATOR)
+}
+
+// splitFileSize divides a maximum file size, already validated, between the
+// video and audio streams of a merged file, in bytes, so the two together
+// stay within it. yt-dlp can only filter the streams one at a time.
+func splitFileSize(maxFileSize string) (video, audio string) {
+	unit := maxFileSize[len(maxFileSize)-1:]
+	number, _ := strconv.ParseFloat(strings.TrimSuffix(maxFileSize, unit), 64)
+	total := number * consts.FILESIZE_UNITS[unit]
+	audioBytes := int64(total * consts.MAX_FILESIZE_AUDIO_SHARE)
+	return strconv.FormatInt(int64(total)-audioBytes, 10), strconv.FormatInt(audioBytes, 10)
+}
+
+func validateFormatIDs(req models.DownloadRequest) error {
+	for _, id := range []string{req.VideoFormatID, req.AudioFormatID} {
+		if id != "" && !formatIDRegex.MatchString(id) {
+			return fmt.Errorf(consts.ERR_INVALID_FORMAT_ID, id)
+		}
+	}
+	return nil
+}
+
+func validatePreferences(prefs *models.QualityPreferences, quality, container string) error {
+	if prefs == nil {
+		return nil
+	}
+	if quality != "" && quality != consts.BEST_QUALITY && qualityHeight(quality) == 0 {
+		return fmt.Errorf(consts.ERR_PREFERENCES_NEED_HEIGHT, quality)
+	}
+	for i, codec := range prefs.Codecs {
+		if _, ok := consts.VIDEO_CODEC_PATTERNS[codec]; !ok {
+			return fmt.Errorf(consts.ERR_INVALID_VIDEO_CODEC, codec)
+		}
+		if containsString(prefs.Codecs[:i], codec) {
+			return fmt.Errorf(consts.ERR_DUPLICATE_VIDEO_CODEC, codec)
+		}
+		if container == consts.CONTAINER_WEBM && containsString(consts.WEBM_INCOMPATIBLE_CODECS, codec) {
+			return fmt.Errorf(consts.ERR_CODEC_NOT_IN_WEBM, strings.ToUpper(codec))
+		}
+	}
+	if prefs.MaxFPS < 0 {
+		return fmt.Errorf(consts.ERR_INVALID_MAX_FPS)
+	}
+	if prefs.MaxFileSize != "" && !maxFileSizeRegex.MatchString(prefs.MaxFileSize) {
+		return fmt.Errorf(consts.ERR_INVALID_MAX_FILESIZE, prefs.MaxFileSize)
+	}
+	return nil
+}
+
+// formatLabel describes explicit format IDs in the job's quality column
+// when the request gives no quality.
+func formatLabel(req models.DownloadRequest) string {
+	if req.VideoFormatID != "" && req.AudioFormatID != "" {
+		return fmt.Sprintf(consts.FORMAT_MERGE_FORMAT, req.VideoFormatID, req.AudioFormatID)
+	}
+	return req.VideoFormatID + req.AudioFormatID
+}
@@ -0,0 +1,194 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"strconv"
+	"testing"
+)
+
+func TestFormatSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.DownloadRequest
+		want string
+	}{
+		{
+			name: "best by default",
+			req:  models.DownloadRequest{},
+			want: consts.QUALITY_BEST_FORMAT,
+		},
+		{
+			name: "explicit best",
+			req:  models.DownloadRequest{Quality: "best"},
+			want: consts.QUALITY_BEST_FORMAT,
+		},
+		{
+			name: "height",
+			req:  models.DownloadRequest{Quality: "720p"},
+			want: "bestvideo[height<=720][ext=mp4]+bestaudio[ext=m4a]/bestvideo[height<=720]+bestaudio/best[height<=720]",
+		},
+		{
+			name: "format ID as quality",
+			req:  models.DownloadRequest{Quality: "137"},
+			want: "(137+bestaudio[ext=m4a])/(137+bestaudio)/137/best",
+		},
+		{
+			name: "video and audio format IDs",
+			req:  models.DownloadRequest{VideoFormatID: "137", AudioFormatID: "140"},
+			want: "137+140",
+		},
+		{
+			name: "video format ID only",
+			req:  models.DownloadRequest{VideoFormatID: "248"},
+			want: "(248+bestaudio[ext=m4a])/(248+bestaudio)/248/best",
+		},
+		{
+			name: "audio format ID only",
+			req:  models.DownloadRequest{AudioFormatID: "251"},
+			want: "bestvideo+251/best",
+		},
+		{
+			name: "format IDs win over preferences",
+			req: models.DownloadRequest{
+				VideoFormatID: "137",
+				AudioFormatID: "140",
+				Preferences:   &models.QualityPreferences{Codecs: []string{"av1"}},
+			},
+			want: "137+140",
+		},
+		{
+			name: "empty preferences",
+			req:  models.DownloadRequest{Preferences: &models.QualityPreferences{}},
+			want: "bestvideo[ext=mp4]+bestaudio[ext=m4a]/bestvideo+bestaudio/best",
+		},
+		{
+			name: "codec order",
+			req: models.DownloadRequest{
+				Quality:     "1080p",
+				Preferences: &models.QualityPreferences{Codecs: []string{"av1", "h264"}},
+			},
+			want: "bestvideo[height<=?1080][vcodec~='^av0?1'][ext=mp4]+bestaudio[ext=m4a]" +
+				"/bestvideo[height<=?1080][vcodec~='^av0?1']+bestaudio" +
+				"/bestvideo[height<=?1080][vcodec~='^(avc1|h264)'][ext=mp4]+bestaudio[ext=m4a]" +
+				"/bestvideo[height<=?1080][vcodec~='^(avc1|h264)']+bestaudio" +
+				"/bestvideo[height<=?1080][ext=mp4]+bestaudio[ext=m4a]" +
+				"/bestvideo[height<=?1080]+bestaudio" +
+				"/best[height<=?1080]",
+		},
+		{
+			name: "frame rate and HDR limits",
+			req: models.DownloadRequest{
+				Quality:     "2160p",
+				Container:   "mkv",
+				Preferences: &models.QualityPreferences{MaxFPS: 30, ExcludeHDR: true},
+			},
+			want: "bestvideo[height<=?2160][fps<=?30][dynamic_range=?SDR]+bestaudio" +
+				"/best[height<=?2160][fps<=?30][dynamic_range=?SDR]",
+		},
+		{
+			name: "webm container",
+			req: models.DownloadRequest{
+				Container:   "webm",
+				Preferences: &models.QualityPreferences{Codecs: []string{"vp9"}},
+			},
+			want: "bestvideo[vcodec~='^vp0?9'][ext=webm]+bestaudio[ext=webm]" +
+				"/bestvideo[ext=webm]+bestaudio[ext=webm]" +
+				"/best[ext=webm]",
+		},
+		{
+			name: "maximum file size",
+			req: models.DownloadRequest{
+				Quality:     "best",
+				Container:   "mkv",
+				Preferences: &models.QualityPreferences{MaxFileSize: "500M"},
+			},
+			want: "bestvideo[filesize<?400000000][filesize_approx<?400000000]+bestaudio[filesize<?100000000][filesize_approx<?100000000]" +
+				"/best[filesize<?500M][filesize_approx<?500M]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatSelector(tt.req); got != tt.want {
+				t.Errorf("formatSelector() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePreferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefs     *models.QualityPreferences
+		quality   string
+		container string
+		wantErr   bool
+	}{
+		{name: "none", prefs: nil, quality: "137"},
+		{name: "all set", prefs: &models.QualityPreferences{Codecs: []string{"vp9", "av1", "h264"}, MaxFPS: 60, ExcludeHDR: true, MaxFileSize: "1.5G"}, quality: "1080p"},
+		{name: "best quality", prefs: &models.QualityPreferences{}, quality: "best"},
+		{name: "format ID quality", prefs: &models.QualityPreferences{}, quality: "137", wantErr: true},
+		{name: "unknown codec", prefs: &models.QualityPreferences{Codecs: []string{"hevc"}}, wantErr: true},
+		{name: "codec listed twice", prefs: &models.QualityPreferences{Codecs: []string{"vp9", "vp9"}}, wantErr: true},
+		{name: "h264 in webm", prefs: &models.QualityPreferences{Codecs: []string{"h264"}}, container: "webm", wantErr: true},
+		{name: "av1 in webm", prefs: &models.QualityPreferences{Codecs: []string{"av1"}}, container: "webm"},
+		{name: "negative frame rate", prefs: &models.QualityPreferences{MaxFPS: -1}, wantErr: true},
+		{name: "size without unit", prefs: &models.QualityPreferences{MaxFileSize: "500"}, wantErr: true},
+		{name: "size with selector syntax", prefs: &models.QualityPreferences{MaxFileSize: "500M]/best"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePreferences(tt.prefs, tt.quality, tt.container)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePreferences() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQualityHeight(t *testing.T) {
+	tests := map[string]int{
+		"":      0,
+		"best":  0,
+		"720p":  720,
+		"2160p": 2160,
+		"137":   0,
+		"hdp":   0,
+	}
+	for quality, want := range tests {
+		if got := qualityHeight(quality); got != want {
+			t.Errorf("qualityHeight(%q) = %d, want %d", quality, got, want)
+		}
+	}
+}
+
+// A merged file is the video and audio streams together, so their limits
+// must add up to no more than the maximum file size.
+func TestSplitFileSize(t *testing.T) {
+	tests := []struct {
+		size      string
+		total     int64
+		wantVideo string
+		wantAudio string
+	}{
+		{size: "500M", total: 500000000, wantVideo: "400000000", wantAudio: "100000000"},
+		{size: "1.5G", total: 1500000000, wantVideo: "1200000000", wantAudio: "300000000"},
+		{size: "100m", total: 100 << 20, wantVideo: "83886080", wantAudio: "20971520"},
+		{size: "999k", total: 999 << 10, wantVideo: "818381", wantAudio: "204595"},
+		{size: "1K", total: 1000, wantVideo: "800", wantAudio: "200"},
+	}
+
+	for _, tt := range tests {
+		video, audio := splitFileSize(tt.size)
+		if video != tt.wantVideo || audio != tt.wantAudio {
+			t.Errorf("splitFileSize(%q) = %s, %s, want %s, %s", tt.size, video, audio, tt.wantVideo, tt.wantAudio)
+		}
+		videoBytes, _ := strconv.ParseInt(video, 10, 64)
+		audioBytes, _ := strconv.ParseInt(audio, 10, 64)
+		if videoBytes+audioBytes > tt.total {
+			t.Errorf("splitFileSize(%q) allows %d bytes merged, more than %d", tt.size, videoBytes+audioBytes, tt.total)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+// formatKind sorts a format into video, audio or both. Formats with
+// neither, such as storyboards, have no kind and are left out.
+func formatKind(format ytDlpFormat) string {
+	switch {
+	case format.isVideo() && format.hasAudio():
+		return consts.FORMAT_KIND_MUXED
+	case format.isVideo():
+		return consts.FORMAT_KIND_VIDEO
+	case format.hasAudio():
+		return consts.FORMAT_KIND_AUDIO
+	}
+	return ""
+}
+
+// formatSize returns the size of a format in bytes and whether it is
+// exact. Without a size from the site it is estimated from the bitrate.
+func formatSize(format ytDlpFormat, duration float64) (int64, bool) {
+	switch {
+	case format.Filesize > 0:
+		return int64(format.Filesize), true
+	case format.FilesizeApprox > 0:
+		return int64(format.FilesizeApprox), false
+	case format.TBR > 0 && duration > 0:
+		return int64(format.TBR * consts.KILOBIT / consts.BITS_PER_BYTE * duration), false
+	}
+	return 0, false
+}
+
+func displaySize(bytes int64, exact bool) string {
+	if bytes <= 0 {
+		return ""
+	}
+	if exact {
+		return FormatFileSize(bytes)
+	}
+	return consts.APPROX_PREFIX + FormatFileSize(bytes)
+}
+
+// mergedSize estimates the size of a merged file, which is unknown when
+// either part is.
+func mergedSize(video, audio int64) int64 {
+	if video <= 0 || audio <= 0 {
+		return 0
+	}
+	return video + audio
+}
+
+func buildMediaFormat(format ytDlpFormat, kind string, duration float64) models.MediaFormat {
+	size, exact := formatSize(format, duration)
+	media := models.MediaFormat{
+		FormatID:       format.FormatID,
+		Kind:           kind,
+		Extension:      format.Ext,
+		Note:           format.FormatNote,
+		Protocol:       format.Protocol,
+		Width:          format.Width,
+		Height:         format.Height,
+		FPS:            format.FPS,
+		DynamicRange:   format.DynamicRange,
+		Bitrate:        format.TBR,
+		VideoBitrate:   format.VBR,
+		AudioBitrate:   format.ABR,
+		SampleRate:     int(format.ASR),
+		AudioChannels:  format.AudioChannels,
+		Language:       format.Language,
+		FileSize:       displaySize(size, exact),
+		FileSizeBytes:  size,
+		FileSizeApprox: size > 0 && !exact,
+	}
+	if kind != consts.FORMAT_KIND_AUDIO {
+		media.Resolution = formatResolution(format)
+		media.VCodec = format.VCodec
+	}
+	if kind != consts.FORMAT_KIND_VIDEO {
+		media.ACodec = format.ACodec
+	}
+	return media
+}
+
+func formatResolution(format ytDlpFormat) string {
+	if format.Height > 0 {
+		return fmt.Sprintf(consts.RESOLUTION_FORMAT, format.Height)
+	}
+	return consts.RESOLUTION_UNKNOWN
+}
+
+// qualityLabel names what sets a format apart at its resolution, such as
+// "1080p60 HDR10". Frame rates up to 30 and SDR go unmentioned.
+func qualityLabel(format ytDlpFormat) string {
+	label := formatResolution(format)
+	if fps := int(math.Round(format.FPS)); fps > consts.HIGH_FPS_THRESHOLD {
+		label = fmt.Sprintf(consts.FPS_LABEL_FORMAT, label, fps)
+	}
+	if format.DynamicRange != "" && format.DynamicRange != consts.DYNAMIC_RANGE_SDR {
+		label = fmt.Sprintf(consts.HDR_LABEL_FORMAT, label, format.DynamicRange)
+	}
+	return label
+}
+
+// mergedContainer is the container yt-dlp merges a video and an audio
+// format into when the request does not name one.
+func mergedContainer(video, audio ytDlpFormat) string {
+	if containsString(consts.MERGE_COMPATIBLE_AUDIO[video.Ext], audio.Ext) {
+		return video.Ext
+	}
+	return consts.CONTAINER_MKV
+}
+
+// bestAudioFor picks the audio to merge with a video format: the best one
+// that fits its container, or the best one overall. yt-dlp lists formats
+// from worst to best.
+func bestAudioFor(video ytDlpFormat, audio []ytDlpFormat) (ytDlpFormat, bool) {
+	for i := len(audio) - 1; i >= 0; i-- {
+		if mergedContainer(video, audio[i]) == video.Ext {
+			return audio[i], true
+		}
+	}
+	if len(audio) == 0 {
+		return ytDlpFormat{}, false
+	}
+	return audio[len(audio)-1], true
+}
+
+// processVideoFormats fills in every format, every video and audio pair,
+// and the recommended list.
+func processVideoFormats(info *ytDlpInfo, videoInfo *models.VideoInfo) {
+	if len(info.Formats) == 0 {
+		return
+	}
+
+	log.Printf(consts.LOG_AVAILABLE_FORMATS_TOTAL, len(info.Formats))
+
+	var video, audio []ytDlpFormat
+	for i, format := range info.Formats {
+		if i < 10 {
+			logFormatDetails(i, format)
+		}
+
+		kind := formatKind(format)
+		if kind == "" {
+			continue
+		}
+		videoInfo.AllFormats = append(videoInfo.AllFormats, buildMediaFormat(format, kind, info.Duration))
+		switch kind {
+		case consts.FORMAT_KIND_AUDIO:
+			audio = append(audio, format)
+		default:
+			video = append(video, format)
+		}
+	}
+
+	videoInfo.Combinations = buildCombinations(video, audio, info.Duration)
+	videoInfo.Formats = recommendFormats(video, audio, info.Duration)
+}
+
+func buildCombinations(video, audio []ytDlpFormat, duration float64) []models.FormatCombination {
+	combinations := []models.FormatCombination{}
+	for _, v := range video {
+		if v.hasAudio() {
+			continue
+		}
+		videoSize, _ := formatSize(v, duration)
+		for _, a := range audio {
+			audioSize, _ := formatSize(a, duration)
+			combination := models.FormatCombination{
+				VideoFormatID: v.FormatID,
+				AudioFormatID: a.FormatID,
+				Extension:     mergedContainer(v, a),
+			}
+			combination.FileSizeBytes = mergedSize(videoSize, audioSize)
+			combination.FileSize = displaySize(combination.FileSizeBytes, false)
+			combinations = append(combinations, combination)
+		}
+	}
+	return combinations
+}
+
+// recommendFormats keeps the format yt-dlp ranks best for each resolution,
+// frame rate and dynamic range, paired with the best audio for it, highest
+// resolution first.
+func recommendFormats(video, audio []ytDlpFormat, duration float64) []models.VideoFormat {
+	best := make(map[string]ytDlpFormat)
+	var labels []string
+	for _, format := range video {
+		label := qualityLabel(format)
+		if _, exists := best[label]; !exists {
+			labels = append(labels, label)
+		}
+		best[label] = format
+	}
+
+	recommended := make([]models.VideoFormat, 0, len(labels))
+	for _, label := range labels {
+		format := best[label]
+		log.Printf(consts.LOG_FOUND_VIDEO_FORMAT, label, format.FormatID)
+
+		size, exact := formatSize(format, duration)
+		videoFormat := models.VideoFormat{
+			FormatID:     format.FormatID,
+			Resolution:   formatResolution(format),
+			Extension:    format.Ext,
+			Quality:      label,
+			FPS:          format.FPS,
+			DynamicRange: format.DynamicRange,
+			VCodec:       format.VCodec,
+		}
+		if format.hasAudio() {
+			videoFormat.ACodec = format.ACodec
+		} else {
+			if a, ok := bestAudioFor(format, audio); ok {
+				audioSize, _ := formatSize(a, duration)
+				videoFormat.AudioFormatID = a.FormatID
+				videoFormat.Extension = mergedContainer(format, a)
+				videoFormat.ACodec = a.ACodec
+				size, exact = mergedSize(size, audioSize), false
+			}
+		}
+		videoFormat.FileSize = displaySize(size, exact)
+		recommended = append(recommended, videoFormat)
+	}
+
+	sort.SliceStable(recommended, func(i, j int) bool {
+		hi := extractResolutionNumber(recommended[i].Resolution)
+		hj := extractResolutionNumber(recommended[j].Resolution)
+		if hi != hj {
+			return hi > hj
+		}
+		return recommended[i].FPS > recommended[j].FPS
+	})
+	return recommended
+}
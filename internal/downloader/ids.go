@@ -0,0 +1,46 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// newJobID returns the prefix followed by a ULID: a 48-bit millisecond
+// timestamp and 80 random bits in Crockford base32, so IDs are unique across
+// concurrent requests and still sort by creation time.
+func newJobID(format string) string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic(fmt.Sprintf(consts.ERR_GENERATE_JOB_ID, err))
+	}
+	return fmt.Sprintf(format, encodeCrockford(id))
+}
+
+// isJobID reports whether name is an ID newJobID could have made.
+func isJobID(name string) bool {
+	for _, format := range consts.JOB_ID_FORMATS {
+		encoded, ok := strings.CutPrefix(name, strings.TrimSuffix(format, "%s"))
+		if ok && len(encoded) == consts.JOB_ID_LENGTH && strings.Trim(encoded, consts.CROCKFORD_ALPHABET) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeCrockford(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	encoded := make([]byte, consts.JOB_ID_LENGTH)
+	for i := consts.JOB_ID_LENGTH - 1; i >= 0; i-- {
+		encoded[i] = consts.CROCKFORD_ALPHABET[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(encoded)
+}
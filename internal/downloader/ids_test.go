@@ -0,0 +1,34 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"testing"
+)
+
+func TestIsJobID(t *testing.T) {
+	for _, format := range consts.JOB_ID_FORMATS {
+		if id := newJobID(format); !isJobID(id) {
+			t.Errorf("isJobID(%q) = false for a new job ID", id)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "dl_01HZX3Y4Z5A6B7C8D9E0F1G2H3", want: true},
+		{name: "pl_01HZX3Y4Z5A6B7C8D9E0F1G2H3", want: true},
+		{name: "01HZX3Y4Z5A6B7C8D9E0F1G2H3", want: false},
+		{name: "dl_01HZX3Y4Z5A6B7C8D9E0F1G2H", want: false},
+		{name: "dl_01HZX3Y4Z5A6B7C8D9E0F1G2HU", want: false},
+		{name: "dl_01hzx3y4z5a6b7c8d9e0f1g2h3", want: false},
+		{name: "video_01HZX3Y4Z5A6B7C8D9E0F1G2H3", want: false},
+		{name: "systemd-private-abc", want: false},
+		{name: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := isJobID(tt.name); got != tt.want {
+			t.Errorf("isJobID(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Jobs still in the journal when the application starts were cut off by a
+// shutdown or a crash. They wait with their workspaces until they are
+// resumed or discarded, or the retention period runs out. A resumed job is
+// queued again under its own ID and workspace, so yt-dlp continues the
+// partial files it left there.
+
+// loadInterrupted takes over the jobs the previous run left in the journal
+// and marks them interrupted in the history.
+func (m *Manager) loadInterrupted() {
+	now := time.Now()
+	var found []journalEntry
+	for _, entry := range m.journal.entries() {
+		if entry.InterruptedAt.IsZero() {
+			entry.InterruptedAt = now
+			found = append(found, entry)
+		}
+		m.interrupted[entry.ID] = entry
+		m.setHistoryStatus(entry.ID, consts.STATUS_INTERRUPTED)
+	}
+	m.journal.save(found...)
+	m.settleInterruptedPlaylists()
+
+	if len(m.interrupted) > 0 {
+		log.Printf(consts.LOG_INTERRUPTED_JOBS_FOUND, len(m.interrupted))
+	}
+}
+
+// GetInterruptedJobs lists the interrupted jobs, oldest first. A playlist
+// is listed along with its interrupted items.
+func (m *Manager) GetInterruptedJobs() []models.InterruptedJob {
+	m.mu.RLock()
+	entries := make([]journalEntry, 0, len(m.interrupted))
+	for _, entry := range m.interrupted {
+		entries = append(entries, entry)
+	}
+	m.mu.RUnlock()
+	sortJournalEntries(entries)
+
+	jobs := []models.InterruptedJob{}
+	partial := make(map[string]int64)
+	for _, entry := range entries {
+		if entry.Type != consts.JOB_TYPE_PLAYLIST {
+			size := workspaceSize(filepath.Join(m.cfg.Paths.TempDir, entry.ID))
+			partial[entry.ID] += size
+			partial[entry.ParentID] += size
+		}
+	}
+	for _, entry := range entries {
+		jobs = append(jobs, models.InterruptedJob{
+			ID:            entry.ID,
+			Type:          entry.Type,
+			URL:           entry.URL,
+			Title:         m.interruptedTitle(entry),
+			Quality:       entry.Quality,
+			ParentID:      entry.ParentID,
+			PartialBytes:  partial[entry.ID],
+			CreatedAt:     entry.CreatedAt,
+			InterruptedAt: entry.InterruptedAt,
+		})
+	}
+	return jobs
+}
+
+// ResumeInterrupted queues the named interrupted jobs again, or all of them
+// when ids is empty.
+func (m *Manager) ResumeInterrupted(ids []string) error {
+	m.mu.Lock()
+	entries, err := m.takeInterrupted(ids)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		log.Printf(consts.LOG_RESUMING_INTERRUPTED, entry.ID)
+		m.registerJob(m.restoreDownload(entry))
+		if entry.ParentID != "" {
+			m.attachToPlaylist(entry.ParentID, entry.ID)
+		}
+		m.enqueue(entry.ID, entry.Priority, m.jobRunner(entry.ID, entry.Type, entry.Request))
+	}
+	m.settleInterruptedPlaylists()
+	return nil
+}
+
+// DiscardInterrupted removes the named interrupted jobs and their
+// workspaces, or all of them when ids is empty.
+func (m *Manager) DiscardInterrupted(ids []string) error {
+	m.mu.Lock()
+	entries, err := m.takeInterrupted(ids)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		m.discardInterrupted(entry)
+	}
+	m.settleInterruptedPlaylists()
+	return nil
+}
+
+func (m *Manager) expireInterrupted(now time.Time) {
+	retention := time.Duration(m.cfg.Downloads.InterruptedRetention)
+
+	m.mu.RLock()
+	var expired []string
+	for id, entry := range m.interrupted {
+		if entry.Type != consts.JOB_TYPE_PLAYLIST && now.Sub(entry.InterruptedAt) > retention {
+			expired = append(expired, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	if len(expired) > 0 {
+		if err := m.DiscardInterrupted(expired); err != nil {
+			log.Printf(consts.LOG_JOB_CONTROL_FAILED, err)
+		}
+	}
+}
+
+// takeInterrupted removes the named jobs, or all of them, from the
+// interrupted jobs and returns them oldest first. A playlist stands for its
+// items; it stays until settleInterruptedPlaylists finds it empty. It must
+// be called with m.mu held.
+func (m *Manager) takeInterrupted(ids []string) ([]journalEntry, error) {
+	if len(ids) == 0 {
+		for id := range m.interrupted {
+			ids = append(ids, id)
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range ids {
+		entry, ok := m.interrupted[id]
+		if !ok {
+			return nil, fmt.Errorf(consts.ERR_JOB_NOT_INTERRUPTED, id)
+		}
+		if entry.Type != consts.JOB_TYPE_PLAYLIST {
+			selected[id] = true
+			continue
+		}
+		for childID, child := range m.interrupted {
+			if child.ParentID == id {
+				selected[childID] = true
+			}
+		}
+	}
+
+	entries := make([]journalEntry, 0, len(selected))
+	for id := range selected {
+		entries = append(entries, m.interrupted[id])
+		delete(m.interrupted, id)
+	}
+	sortJournalEntries(entries)
+	return entries, nil
+}
+
+// settleInterruptedPlaylists drops the playlists that have no interrupted
+// items left. A playlist none of whose items was resumed is discarded.
+func (m *Manager) settleInterruptedPlaylists() {
+	m.mu.Lock()
+	var discarded []journalEntry
+	for id, entry := range m.interrupted {
+		if entry.Type != consts.JOB_TYPE_PLAYLIST || m.hasInterruptedItems(id) {
+			continue
+		}
+		delete(m.interrupted, id)
+		if _, resumed := m.downloads[id]; !resumed {
+			discarded = append(discarded, entry)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, entry := range discarded {
+		m.discardInterrupted(entry)
+	}
+}
+
+// hasInterruptedItems must be called with m.mu held.
+func (m *Manager) hasInterruptedItems(parentID string) bool {
+	for _, entry := range m.interrupted {
+		if entry.ParentID == parentID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) discardInterrupted(entry journalEntry) {
+	log.Printf(consts.LOG_DISCARDING_INTERRUPTED, entry.ID)
+	dir := filepath.Join(m.cfg.Paths.TempDir, entry.ID)
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf(consts.ERR_REMOVE_TEMP_FILE, dir, err)
+	}
+	m.journal.remove(entry.ID)
+	m.setHistoryStatus(entry.ID, consts.STATUS_CANCELLED)
+}
+
+// restoreDownload rebuilds a job from its journal entry, keeping the
+// retries and attempts its history recorded.
+func (m *Manager) restoreDownload(entry journalEntry) *Download {
+	download := newDownload(entry.ID, entry.Type, entry.URL, entry.Quality)
+	download.Title = m.interruptedTitle(entry)
+	download.ParentID = entry.ParentID
+	download.request = entry.Request
+	download.CreatedAt = entry.CreatedAt
+	if entry.SaveDir != "" {
+		download.saveTarget = &directorySaveTarget{dir: entry.SaveDir}
+	}
+	if historyEntry, ok := m.history.Get(entry.ID); ok {
+		download.Retries = historyEntry.Retries
+		download.Attempts = historyEntry.Attempts
+	}
+	return download
+}
+
+// attachToPlaylist adds a resumed item to its playlist, which is registered
+// again with the first of its items to be resumed.
+func (m *Manager) attachToPlaylist(parentID, childID string) {
+	m.mu.Lock()
+	if parent, ok := m.downloads[parentID]; ok {
+		parent.Children = append(parent.Children, childID)
+		entry := parent.journalEntry()
+		m.mu.Unlock()
+		m.journal.save(entry)
+		return
+	}
+	entry, ok := m.interrupted[parentID]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	parent := m.restoreDownload(entry)
+	parent.Children = []string{childID}
+	m.registerJob(parent)
+}
+
+// interruptedTitle prefers the title the history learned while the job ran.
+func (m *Manager) interruptedTitle(entry journalEntry) string {
+	if historyEntry, ok := m.history.Get(entry.ID); ok && historyEntry.Title != "" {
+		return historyEntry.Title
+	}
+	return entry.Title
+}
+
+// setHistoryStatus records the new status of a job that is not in memory,
+// unless its history entry already shows it finished.
+func (m *Manager) setHistoryStatus(id, status string) {
+	entry, ok := m.history.Get(id)
+	if !ok || isTerminalStatus(entry.Status) {
+		return
+	}
+	entry.Status = status
+	entry.UpdatedAt = time.Now()
+	if isTerminalStatus(status) {
+		completedAt := entry.UpdatedAt
+		entry.CompletedAt = &completedAt
+		entry.ErrorCode = consts.ERROR_CODE_CANCELLED
+	}
+	m.saveHistory(entry)
+}
+
+func sortJournalEntries(entries []journalEntry) {
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].CreatedAt.Equal(entries[b].CreatedAt) {
+			return entries[a].ID < entries[b].ID
+		}
+		return entries[a].CreatedAt.Before(entries[b].CreatedAt)
+	})
+}
+
+// workspaceSize is how many bytes a job left in its workspace.
+func workspaceSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
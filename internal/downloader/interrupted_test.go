@@ -0,0 +1,274 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/history"
+	"Go-Utilities/internal/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJobJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), consts.JOURNAL_FILE_NAME)
+	journal, err := openJobJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := journalEntry{ID: "first", Type: consts.JOB_TYPE_VIDEO, URL: "https://example.com/1", Request: models.DownloadRequest{URL: "https://example.com/1", Quality: "720p"}, CreatedAt: created}
+	second := journalEntry{ID: "second", Type: consts.JOB_TYPE_AUDIO, URL: "https://example.com/2", CreatedAt: created.Add(time.Second)}
+	gone := journalEntry{ID: "gone", Type: consts.JOB_TYPE_VIDEO, CreatedAt: created}
+	journal.save(second, first, gone)
+	journal.remove("gone")
+
+	journal.close()
+	journal.save(journalEntry{ID: "after-close"})
+	journal.remove("first")
+
+	reopened, err := openJobJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.entries(); !reflect.DeepEqual(got, []journalEntry{first, second}) {
+		t.Errorf("reopened journal = %+v, want %+v", got, []journalEntry{first, second})
+	}
+}
+
+func TestInterruptedJobs(t *testing.T) {
+	cfg := config.Default()
+	cfg.Paths.TempDir = t.TempDir()
+	cfg.Paths.DataDir = t.TempDir()
+
+	created := time.Now().Add(-time.Hour)
+	journal, err := openJobJournal(filepath.Join(cfg.Paths.DataDir, consts.JOURNAL_FILE_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal.save(
+		journalEntry{ID: "video", Type: consts.JOB_TYPE_VIDEO, URL: "https://example.com/v", CreatedAt: created},
+		journalEntry{ID: "playlist", Type: consts.JOB_TYPE_PLAYLIST, URL: "https://example.com/p", Title: "Playlist", Children: []string{"one", "two"}, CreatedAt: created.Add(time.Second)},
+		journalEntry{ID: "one", Type: consts.JOB_TYPE_AUDIO, URL: "https://example.com/1", ParentID: "playlist", SaveDir: "/music/Playlist", CreatedAt: created.Add(2 * time.Second)},
+		journalEntry{ID: "two", Type: consts.JOB_TYPE_AUDIO, URL: "https://example.com/2", ParentID: "playlist", CreatedAt: created.Add(3 * time.Second)},
+	)
+	writeWorkspaceFile(t, cfg, "video", 5)
+	writeWorkspaceFile(t, cfg, "one", 3)
+	writeWorkspaceFile(t, cfg, "two", 4)
+
+	store := history.NewMemoryStore()
+	store.Save(models.HistoryEntry{ID: "video", Title: "Video", Status: consts.STATUS_DOWNLOADING, Retries: 2, CreatedAt: created})
+	store.Save(models.HistoryEntry{ID: "one", Status: consts.STATUS_COMPLETED, CreatedAt: created})
+
+	m := &Manager{
+		downloads:   make(map[string]*Download),
+		history:     store,
+		cfg:         cfg,
+		journal:     journal,
+		interrupted: make(map[string]journalEntry),
+	}
+	m.loadInterrupted()
+
+	wantJobs := map[string]models.InterruptedJob{
+		"video":    {ID: "video", Title: "Video", PartialBytes: 5},
+		"playlist": {ID: "playlist", Title: "Playlist", PartialBytes: 7},
+		"one":      {ID: "one", ParentID: "playlist", PartialBytes: 3},
+		"two":      {ID: "two", ParentID: "playlist", PartialBytes: 4},
+	}
+	jobs := m.GetInterruptedJobs()
+	if len(jobs) != len(wantJobs) || jobs[0].ID != "video" || jobs[3].ID != "two" {
+		t.Fatalf("GetInterruptedJobs() = %+v", jobs)
+	}
+	for _, job := range jobs {
+		want := wantJobs[job.ID]
+		if job.Title != want.Title || job.ParentID != want.ParentID || job.PartialBytes != want.PartialBytes || job.InterruptedAt.IsZero() {
+			t.Errorf("interrupted job %s = %+v, want %+v", job.ID, job, want)
+		}
+	}
+	if entry, _ := store.Get("video"); entry.Status != consts.STATUS_INTERRUPTED {
+		t.Errorf("history status of an interrupted job = %s", entry.Status)
+	}
+	if entry, _ := store.Get("one"); entry.Status != consts.STATUS_COMPLETED {
+		t.Errorf("history status of a finished job changed to %s", entry.Status)
+	}
+
+	if err := m.ResumeInterrupted([]string{"missing"}); err == nil {
+		t.Error("ResumeInterrupted() of an unknown job succeeded")
+	}
+
+	// Resuming one item brings its playlist back with it.
+	if err := m.ResumeInterrupted([]string{"one"}); err != nil {
+		t.Fatal(err)
+	}
+	one, ok := m.downloads["one"]
+	if !ok || len(m.queue) != 1 || m.queue[0] != one {
+		t.Fatalf("resumed job was not queued: %+v", m.queue)
+	}
+	if target, ok := one.saveTarget.(*directorySaveTarget); !ok || target.dir != "/music/Playlist" {
+		t.Errorf("resumed job save target = %+v", one.saveTarget)
+	}
+	if playlist, ok := m.downloads["playlist"]; !ok || !reflect.DeepEqual(playlist.Children, []string{"one"}) {
+		t.Errorf("resumed playlist = %+v", playlist)
+	}
+
+	// Discarding the playlist discards the items it has left; the playlist
+	// itself carries on with its resumed item.
+	if err := m.DiscardInterrupted([]string{"playlist"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Paths.TempDir, "two")); !os.IsNotExist(err) {
+		t.Errorf("workspace of a discarded job was kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Paths.TempDir, "one")); err != nil {
+		t.Errorf("workspace of a resumed job was removed: %v", err)
+	}
+
+	m.expireInterrupted(time.Now().Add(time.Duration(cfg.Downloads.InterruptedRetention) + time.Minute))
+	if jobs := m.GetInterruptedJobs(); len(jobs) != 0 {
+		t.Errorf("interrupted jobs left = %+v", jobs)
+	}
+	if entry, _ := store.Get("video"); entry.Status != consts.STATUS_CANCELLED || entry.ErrorCode != consts.ERROR_CODE_CANCELLED {
+		t.Errorf("history of an expired job = %+v", entry)
+	}
+
+	journaled := make(map[string]bool)
+	for _, entry := range journal.entries() {
+		journaled[entry.ID] = true
+	}
+	if !reflect.DeepEqual(journaled, map[string]bool{"playlist": true, "one": true}) {
+		t.Errorf("journaled jobs = %v, want the resumed playlist and item", journaled)
+	}
+}
+
+func writeWorkspaceFile(t *testing.T, cfg *config.Config, id string, size int) {
+	t.Helper()
+	dir := filepath.Join(cfg.Paths.TempDir, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".part"), make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdownKeepsRunningJobs(t *testing.T) {
+	cfg := config.Default()
+	cfg.Paths.TempDir = t.TempDir()
+	cfg.Paths.DataDir = t.TempDir()
+	journalPath := filepath.Join(cfg.Paths.DataDir, consts.JOURNAL_FILE_NAME)
+	historyPath := filepath.Join(cfg.Paths.DataDir, consts.HISTORY_FILE_NAME)
+
+	start := func(ytdlp YtDlpClient) *Manager {
+		t.Helper()
+		journal, err := openJobJournal(journalPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store, err := history.Open(historyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := &Manager{
+			downloads:     make(map[string]*Download),
+			history:       store,
+			maxConcurrent: 1,
+			cfg:           cfg,
+			deps:          dependencies.NewResolver(cfg),
+			ytdlp:         ytdlp,
+			stopJanitor:   make(chan struct{}),
+			journal:       journal,
+			interrupted:   make(map[string]journalEntry),
+		}
+		m.loadInterrupted()
+		return m
+	}
+
+	m := start(newFakeYtDlp(t, fakeScript{Duration: time.Minute}))
+	req := models.DownloadRequest{URL: "https://example.com/v", Quality: consts.BEST_QUALITY}
+	id := newJobID(consts.DOWNLOAD_ID_FORMAT)
+	done := make(chan struct{})
+	m.registerDownload(id, consts.JOB_TYPE_VIDEO, req.Quality, req)
+	m.enqueue(id, 0, func() {
+		m.download(id, req)
+		close(done)
+	})
+
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		m.mu.RLock()
+		running := m.downloads[id].cmd != nil
+		m.mu.RUnlock()
+		if running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the download never started")
+		}
+	}
+	m.Shutdown()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the download kept running after the shutdown")
+	}
+
+	restarted := start(nil)
+	defer restarted.history.Close()
+
+	jobs := restarted.GetInterruptedJobs()
+	if len(jobs) != 1 || jobs[0].ID != id {
+		t.Fatalf("interrupted jobs after a restart = %+v, want the running job", jobs)
+	}
+	if entry, _ := restarted.history.Get(id); entry.Status != consts.STATUS_INTERRUPTED {
+		t.Errorf("history status after a restart = %s, want %s", entry.Status, consts.STATUS_INTERRUPTED)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Paths.TempDir, id)); err != nil {
+		t.Errorf("workspace of the running job was removed: %v", err)
+	}
+}
+
+func TestShutdownForgetsJobsCompletingMeanwhile(t *testing.T) {
+	cfg := config.Default()
+	cfg.Paths.TempDir = t.TempDir()
+	journal, err := openJobJournal(filepath.Join(t.TempDir(), consts.JOURNAL_FILE_NAME))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{
+		downloads:     make(map[string]*Download),
+		history:       history.NewMemoryStore(),
+		maxConcurrent: 1,
+		cfg:           cfg,
+		stopJanitor:   make(chan struct{}),
+		journal:       journal,
+		interrupted:   make(map[string]journalEntry),
+	}
+
+	// The job is past yt-dlp, saving its file, when the shutdown comes.
+	id := newJobID(consts.DOWNLOAD_ID_FORMAT)
+	started := make(chan struct{})
+	m.registerDownload(id, consts.JOB_TYPE_VIDEO, "", models.DownloadRequest{URL: "https://example.com/v"})
+	m.enqueue(id, 0, func() {
+		if _, err := m.createWorkspace(id); err != nil {
+			t.Error(err)
+		}
+		close(started)
+		for !m.isCancelled(id) {
+			time.Sleep(time.Millisecond)
+		}
+		m.updateStatus(id, consts.STATUS_COMPLETED, 100, "", "", "")
+		m.releaseWorkspace(id)
+	})
+	<-started
+	m.Shutdown()
+
+	if entries := journal.entries(); len(entries) != 0 {
+		t.Errorf("journal after a shutdown = %+v, want the completed job gone", entries)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Paths.TempDir, id)); !os.IsNotExist(err) {
+		t.Errorf("workspace of the completed job was kept: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// jobJournal keeps the definition of every job that has not finished, so
+// jobs cut off by a shutdown or a crash can be found and resumed on the next
+// start. It only ever holds the jobs in flight and is rewritten whole on
+// every change. A journal without a path lives in memory only.
+type jobJournal struct {
+	path   string
+	jobs   map[string]journalEntry
+	closed bool
+	mu     sync.Mutex
+}
+
+// journalEntry is everything needed to queue a job again. A playlist is
+// journaled for its title and items; SaveDir is the folder of its items.
+type journalEntry struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	URL       string                 `json:"url"`
+	Title     string                 `json:"title,omitempty"`
+	Quality   string                 `json:"quality,omitempty"`
+	Priority  int                    `json:"priority,omitempty"`
+	ParentID  string                 `json:"parent_id,omitempty"`
+	Children  []string               `json:"children,omitempty"`
+	SaveDir   string                 `json:"save_dir,omitempty"`
+	Request   models.DownloadRequest `json:"request"`
+	CreatedAt time.Time              `json:"created_at"`
+	// InterruptedAt is set when the job is found after a restart.
+	InterruptedAt time.Time `json:"interrupted_at,omitempty"`
+}
+
+func newMemoryJournal() *jobJournal {
+	return &jobJournal{jobs: make(map[string]journalEntry)}
+}
+
+func openJobJournal(path string) (*jobJournal, error) {
+	journal := &jobJournal{path: path, jobs: make(map[string]journalEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(consts.ERR_JOURNAL_READ, path, err)
+	}
+
+	var entries []journalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf(consts.ERR_JOURNAL_READ, path, err)
+	}
+	for _, entry := range entries {
+		journal.jobs[entry.ID] = entry
+	}
+	return journal, nil
+}
+
+// save adds or replaces entries. A nil journal, as in tests, ignores it.
+func (j *jobJournal) save(entries ...journalEntry) {
+	if j == nil || len(entries) == 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return
+	}
+	for _, entry := range entries {
+		j.jobs[entry.ID] = entry
+	}
+	j.write()
+}
+
+func (j *jobJournal) remove(ids ...string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return
+	}
+	changed := false
+	for _, id := range ids {
+		if _, ok := j.jobs[id]; ok {
+			delete(j.jobs, id)
+			changed = true
+		}
+	}
+	if changed {
+		j.write()
+	}
+}
+
+// entries returns the journaled jobs, oldest first.
+func (j *jobJournal) entries() []journalEntry {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]journalEntry, 0, len(j.jobs))
+	for _, entry := range j.jobs {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].CreatedAt.Before(entries[b].CreatedAt)
+	})
+	return entries
+}
+
+// close stops recording, so the jobs stopped by a shutdown stay journaled
+// as they were rather than as cancelled.
+func (j *jobJournal) close() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.closed = true
+	j.mu.Unlock()
+}
+
+// write must be called with j.mu held. The file is replaced in one rename
+// so a crash cannot leave half a journal behind.
+func (j *jobJournal) write() {
+	if j.path == "" {
+		return
+	}
+	if err := j.writeFile(); err != nil {
+		log.Printf(consts.WARNING_JOURNAL_SAVE_FAILED, err)
+	}
+}
+
+func (j *jobJournal) writeFile() error {
+	entries := make([]journalEntry, 0, len(j.jobs))
+	for _, entry := range j.jobs {
+		entries = append(entries, entry)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf(consts.ERR_JOURNAL_WRITE, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf(consts.ERR_JOURNAL_WRITE, err)
+	}
+	tempPath := j.path + consts.TEMP_EXT
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf(consts.ERR_JOURNAL_WRITE, err)
+	}
+	if err := os.Rename(tempPath, j.path); err != nil {
+		return fmt.Errorf(consts.ERR_JOURNAL_WRITE, err)
+	}
+	return nil
+}
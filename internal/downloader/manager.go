@@ -1,8 +1,12 @@
 package downloader
 
 import (
+	"Go-Utilities/internal/config"
 	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/history"
 	"Go-Utilities/internal/models"
+	"Go-Utilities/internal/sites"
 	"fmt"
 	"log"
 	"os"
@@ -14,61 +18,240 @@ import (
 )
 
 type Manager struct {
-	downloads   map[string]*Download
-	subscribers []chan models.ProgressUpdate
-	mu          sync.RWMutex
+	downloads     map[string]*Download
+	subscribers   map[*Subscription]struct{}
+	history       *history.Store
+	queue         []*Download
+	running       int
+	maxConcurrent int
+	cfg           *config.Config
+	deps          *dependencies.Resolver
+	ytdlp         YtDlpClient
+	saveTarget    SaveTarget
+	sites         *sites.Registry
+	fileRetention time.Duration
+	stopJanitor   chan struct{}
+	// journal records the jobs in flight; interrupted holds the ones a
+	// previous run left unfinished until they are resumed or discarded.
+	journal     *jobJournal
+	interrupted map[string]journalEntry
+	stopping    bool
+	// jobs counts the jobs whose runner has not returned yet.
+	jobs sync.WaitGroup
+	mu   sync.RWMutex
+	// playlistMu orders playlist recomputations so a stale snapshot from one
+	// item can never be published after a newer one.
+	playlistMu sync.Mutex
 }
 
 type Download struct {
-	ID       string
-	URL      string
-	Title    string
-	Status   string
-	Progress float64
-	Speed    string
-	ETA      string
+	ID          string
+	Type        string
+	URL         string
+	Title       string
+	Quality     string
+	FilePath    string
+	Error       string
+	ErrorCode   string
+	Status      string
+	Progress    float64
+	Speed       string
+	ETA         string
+	Phase       string
+	Priority    int
+	Workspace   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+	// ParentID is the playlist job an item belongs to; Children lists the
+	// items of a playlist job in playlist order.
+	ParentID string
+	Children []string
+	Retries  int
+	// Attempt is the attempt running now; Attempts are the earlier ones
+	// that failed and were retried automatically.
+	Attempt  int
+	Attempts []models.JobAttempt
+
+	run          func()
+	request      models.DownloadRequest
+	saveTarget   SaveTarget
+	retainedFile string
+	// retainedUntil is when the janitor deletes an unfetched retained file.
+	retainedUntil time.Time
+	// fileReaders counts the requests reading the retained file; a release
+	// asked for while any are left waits for the last one to finish.
+	fileReaders    int
+	releasePending bool
+	cmd            *exec.Cmd
+	activeStatus   string
+	maxAttempts    int
+	paused         bool
+	cancelled      bool
+	finished       bool
+	// stoppedByShutdown marks the jobs a shutdown cancelled; unless they
+	// still complete, they are kept in the journal for the next run.
+	stoppedByShutdown bool
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		downloads:   make(map[string]*Download),
-		subscribers: []chan models.ProgressUpdate{},
+func NewManager(cfg *config.Config, deps *dependencies.Resolver, historyStore *history.Store, saveTarget SaveTarget) *Manager {
+	m := &Manager{
+		downloads:     make(map[string]*Download),
+		subscribers:   make(map[*Subscription]struct{}),
+		history:       historyStore,
+		maxConcurrent: cfg.Downloads.MaxConcurrentJobs,
+		cfg:           cfg,
+		deps:          deps,
+		ytdlp:         NewYtDlpClient(deps.YtDlpPath, execRunner{}),
+		saveTarget:    saveTarget,
+		sites:         sites.NewRegistry(cfg.Sites.Allow, cfg.Sites.Deny),
+		fileRetention: time.Duration(cfg.Downloads.FileRetention),
+		stopJanitor:   make(chan struct{}),
+		interrupted:   make(map[string]journalEntry),
 	}
+
+	journal, err := openJobJournal(filepath.Join(cfg.Paths.DataDir, consts.JOURNAL_FILE_NAME))
+	if err != nil {
+		log.Printf(consts.WARNING_JOURNAL_UNAVAILABLE, err)
+		journal = newMemoryJournal()
+	}
+	m.journal = journal
+	m.loadInterrupted()
+
+	go m.runFileJanitor()
+	return m
 }
 
-func (m *Manager) TestYtDlp() error {
-	return TestYtDlp()
+func (m *Manager) GetHistory(query models.HistoryQuery) models.HistoryPage {
+	return m.history.Query(query)
 }
 
-func (m *Manager) StartDownload(url, quality string) string {
-	downloadID := fmt.Sprintf(consts.DOWNLOAD_ID_FORMAT, time.Now().Unix())
-	go m.download(downloadID, url, quality)
-	return downloadID
+func (m *Manager) StartDownload(req models.DownloadRequest) (string, error) {
+	url, err := m.sites.NormalizeVideo(req.URL)
+	if err != nil {
+		return "", err
+	}
+	if err := validateDownloadOptions(req); err != nil {
+		return "", err
+	}
+	if err := validateMaxAttempts(req.MaxAttempts); err != nil {
+		return "", err
+	}
+	if err := m.requireFFmpeg(req); err != nil {
+		return "", err
+	}
+	req.URL = url
+	if req.Quality == "" {
+		req.Quality = formatLabel(req)
+	}
+
+	downloadID := newJobID(consts.DOWNLOAD_ID_FORMAT)
+	m.registerDownload(downloadID, consts.JOB_TYPE_VIDEO, req.Quality, req)
+	m.enqueue(downloadID, req.Priority, func() {
+		m.download(downloadID, req)
+	})
+	return downloadID, nil
 }
 
-func (m *Manager) StartMp3Convert(url string) string {
-	downloadID := fmt.Sprintf(consts.MP3_ID_FORMAT, time.Now().Unix())
-	go m.convertToMp3(downloadID, url)
-	return downloadID
+func (m *Manager) StartAudioExtract(req models.DownloadRequest) (string, error) {
+	url, err := m.sites.NormalizeVideo(req.URL)
+	if err != nil {
+		return "", err
+	}
+	if err := validateChapterMode(req); err != nil {
+		return "", err
+	}
+	opts := resolveAudioOptions(req)
+	if err := validateAudioOptions(opts); err != nil {
+		return "", err
+	}
+	if err := validateTags(req.Tags); err != nil {
+		return "", err
+	}
+	if req.VideoFormatID != "" {
+		return "", fmt.Errorf(consts.ERR_VIDEO_FORMAT_FOR_AUDIO)
+	}
+	if err := validateFormatIDs(req); err != nil {
+		return "", err
+	}
+	if err := validateMaxAttempts(req.MaxAttempts); err != nil {
+		return "", err
+	}
+	if err := m.requireFFmpeg(req); err != nil {
+		return "", err
+	}
+	req.URL = url
+	req.Audio = &opts
+
+	downloadID := newJobID(consts.AUDIO_ID_FORMAT)
+	m.registerDownload(downloadID, consts.JOB_TYPE_AUDIO, audioLabel(opts), req)
+	m.enqueue(downloadID, req.Priority, func() {
+		m.extractAudio(downloadID, req)
+	})
+	return downloadID, nil
 }
 
-func (m *Manager) download(id, url, quality string) {
-	m.mu.Lock()
-	m.downloads[id] = &Download{
-		ID:     id,
-		URL:    url,
-		Status: consts.STATUS_STARTING,
+// requireFFmpeg rejects options that cannot work without ffmpeg up front
+// rather than after the download.
+func (m *Manager) requireFFmpeg(req models.DownloadRequest) error {
+	if req.Clip == nil && req.Chapters == "" {
+		return nil
 	}
-	m.mu.Unlock()
+	if _, err := m.deps.FFmpegPath(); err != nil {
+		if req.Clip != nil {
+			return fmt.Errorf(consts.ERR_CLIP_NEEDS_FFMPEG, err)
+		}
+		return fmt.Errorf(consts.ERR_CHAPTERS_NEED_FFMPEG, err)
+	}
+	return nil
+}
+
+// StartSubtitles queues a job that fetches only the requested subtitles.
+func (m *Manager) StartSubtitles(req models.DownloadRequest) (string, error) {
+	url, err := m.sites.NormalizeVideo(req.URL)
+	if err != nil {
+		return "", err
+	}
+	if req.Subtitles == nil {
+		return "", fmt.Errorf(consts.ERR_NO_SUBTITLE_LANGUAGES)
+	}
+	if err := validateSubtitleOptions(*req.Subtitles, ""); err != nil {
+		return "", err
+	}
+	if err := validateMaxAttempts(req.MaxAttempts); err != nil {
+		return "", err
+	}
+	req.URL = url
+
+	downloadID := newJobID(consts.SUBTITLES_ID_FORMAT)
+	m.registerDownload(downloadID, consts.JOB_TYPE_SUBTITLES, "", req)
+	m.enqueue(downloadID, req.Priority, func() {
+		m.downloadSubtitles(downloadID, req)
+	})
+	return downloadID, nil
+}
+
+func (m *Manager) download(id string, req models.DownloadRequest) {
+	workspace, err := m.createWorkspace(id)
+	if err != nil {
+		m.failJob(id, consts.ERR_DOWNLOAD_FAILED, err)
+		return
+	}
+	defer m.releaseWorkspace(id)
 
 	m.updateStatus(id, consts.STATUS_DOWNLOADING, 0, "", "", consts.MSG_STARTING_DOWNLOAD)
 
-	result, err := ExecuteDownload(url, quality, func(progress float64, speed, eta, message string) {
-		m.updateStatus(id, consts.STATUS_DOWNLOADING, progress, speed, eta, message)
+	result, err := m.executeWithRetries(id, consts.STATUS_DOWNLOADING, req, func(cfg *config.Config) (*YtDlpResult, error) {
+		return ExecuteDownload(cfg, m.deps, m.ytdlp, workspace, req, m.progressCallback(id, consts.STATUS_DOWNLOADING), m.processCallback(id))
 	})
 
+	if m.isCancelled(id) {
+		m.finishCancelled(id)
+		return
+	}
+
 	if err != nil {
-		m.updateStatus(id, consts.STATUS_ERROR, 0, "", "", fmt.Sprintf(consts.ERR_DOWNLOAD_FAILED, err.Error()))
+		m.failJob(id, consts.ERR_DOWNLOAD_FAILED, err)
 		return
 	}
 
@@ -80,49 +263,55 @@ func (m *Manager) download(id, url, quality string) {
 		m.mu.Unlock()
 	}
 
-	newFileName := m.addResolutionToFilename(result.FilePath, quality)
+	// Clips are already named after their section.
+	newFileName := result.FilePath
+	if req.Clip == nil {
+		newFileName = m.addResolutionToFilename(result.FilePath, req.Quality)
+	}
 	if newFileName != result.FilePath {
 		if err := os.Rename(result.FilePath, newFileName); err != nil {
 			log.Printf(consts.ERR_RENAME_FILE, err)
 		} else {
+			result.Sidecars = renameSidecars(result.FilePath, newFileName, result.Sidecars)
 			result.FilePath = newFileName
 		}
 	}
 
-	finalPath, err := m.openFilePicker(result.FilePath)
+	saved, err := m.saveFiles(id, result, bundleName(result, req))
 	if err != nil {
-		m.updateStatus(id, consts.STATUS_ERROR, 0, "", "", fmt.Sprintf(consts.ERR_SAVE_FILE, err))
+		m.failJob(id, consts.ERR_SAVE_FILE, err)
 		return
 	}
 
-	m.updateStatus(id, consts.STATUS_COMPLETED, 100, "", "", fmt.Sprintf(consts.MSG_SAVED_AS, filepath.Base(finalPath)))
-
-	log.Printf(consts.LOG_OPENING_FILE_EXPLORER)
-	m.openFileExplorer(filepath.Dir(finalPath))
+	m.completeJob(id, saved, consts.MSG_SAVED_AS)
 }
 
-func (m *Manager) convertToMp3(id, url string) {
-	m.mu.Lock()
-	m.downloads[id] = &Download{
-		ID:     id,
-		URL:    url,
-		Status: consts.STATUS_STARTING,
+func (m *Manager) extractAudio(id string, req models.DownloadRequest) {
+	workspace, err := m.createWorkspace(id)
+	if err != nil {
+		m.failJob(id, consts.AUDIO_EXTRACT_FAILED, err)
+		return
 	}
-	m.mu.Unlock()
+	defer m.releaseWorkspace(id)
 
-	m.updateStatus(id, consts.STATUS_CONVERTING, 0, "", "", consts.MSG_STARTING_MP3_CONVERSION)
+	m.updateStatus(id, consts.STATUS_CONVERTING, 0, "", "", consts.MSG_EXTRACTING_AUDIO)
 
-	result, err := ExecuteMp3Conversion(url, func(progress float64, speed, eta, message string) {
-		m.updateStatus(id, consts.STATUS_CONVERTING, progress, speed, eta, message)
+	result, err := m.executeWithRetries(id, consts.STATUS_CONVERTING, req, func(cfg *config.Config) (*YtDlpResult, error) {
+		return ExecuteAudioExtraction(cfg, m.deps, m.ytdlp, workspace, req, m.progressCallback(id, consts.STATUS_CONVERTING), m.processCallback(id))
 	})
 
+	if m.isCancelled(id) {
+		m.finishCancelled(id)
+		return
+	}
+
 	if err != nil {
-		m.updateStatus(id, consts.STATUS_ERROR, 0, "", "", fmt.Sprintf(consts.MP3_CONVERSION_FAILED, err))
+		m.failJob(id, consts.AUDIO_EXTRACT_FAILED, err)
 		return
 	}
 
 	if result == nil {
-		m.updateStatus(id, consts.STATUS_ERROR, 0, "", "", "MP3 conversion failed: no result returned")
+		m.failJob(id, consts.AUDIO_EXTRACT_FAILED, fmt.Errorf(consts.ERR_NO_AUDIO_RESULT))
 		return
 	}
 
@@ -134,131 +323,449 @@ func (m *Manager) convertToMp3(id, url string) {
 		m.mu.Unlock()
 	}
 
-	finalPath, err := m.openFilePicker(result.FilePath)
+	saved, err := m.saveFiles(id, result, bundleName(result, req))
 	if err != nil {
-		m.updateStatus(id, consts.STATUS_ERROR, 0, "", "", fmt.Sprintf(consts.ERR_SAVE_MP3_FILE, err))
+		m.failJob(id, consts.ERR_SAVE_AUDIO_FILE, err)
 		return
 	}
 
-	m.updateStatus(id, consts.STATUS_COMPLETED, 100, "", "", fmt.Sprintf(consts.MSG_MP3_SAVED_AS, filepath.Base(finalPath)))
+	m.completeJob(id, saved, consts.MSG_AUDIO_SAVED_AS)
 }
 
-func (m *Manager) GetVideoInfo(url string) (*models.VideoInfo, error) {
-	return GetVideoInfo(url)
-}
+func (m *Manager) downloadSubtitles(id string, req models.DownloadRequest) {
+	workspace, err := m.createWorkspace(id)
+	if err != nil {
+		m.failJob(id, consts.ERR_SUBTITLES_FAILED, err)
+		return
+	}
+	defer m.releaseWorkspace(id)
+
+	m.updateStatus(id, consts.STATUS_DOWNLOADING, 0, "", "", consts.MSG_STARTING_SUBTITLES)
+
+	result, err := m.executeWithRetries(id, consts.STATUS_DOWNLOADING, req, func(cfg *config.Config) (*YtDlpResult, error) {
+		return ExecuteSubtitleDownload(cfg, m.deps, m.ytdlp, workspace, req, m.progressCallback(id, consts.STATUS_DOWNLOADING), m.processCallback(id))
+	})
+
+	if m.isCancelled(id) {
+		m.finishCancelled(id)
+		return
+	}
+
+	if err != nil {
+		m.failJob(id, consts.ERR_SUBTITLES_FAILED, err)
+		return
+	}
+
+	m.mu.Lock()
+	if download, ok := m.downloads[id]; ok {
+		download.Title = result.Title
+	}
+	m.mu.Unlock()
+
+	saved, err := m.saveFiles(id, result, result.Title)
+	if err != nil {
+		m.failJob(id, consts.ERR_SAVE_SUBTITLES, err)
+		return
+	}
 
-func (m *Manager) ParseYouTubeURL(inputURL string) (string, error) {
-	return ParseYouTubeURL(inputURL)
+	m.completeJob(id, saved, consts.MSG_SUBTITLES_SAVED_AS)
 }
 
-func (m *Manager) updateStatus(id, status string, progress float64, speed, eta, message string) {
+// completeJob records where the file went. Deferred saves keep the job
+// workspace alive so the file can still be fetched by the browser.
+func (m *Manager) completeJob(id string, saved SaveResult, savedMessage string) {
 	m.mu.Lock()
 	if download, ok := m.downloads[id]; ok {
-		download.Status = status
-		download.Progress = progress
-		download.Speed = speed
-		download.ETA = eta
+		download.FilePath = saved.Path
+		if saved.Deferred {
+			download.FilePath = jobFileURL(id)
+			download.retainedFile = saved.Path
+			download.retainedUntil = time.Now().Add(m.fileRetention)
+		}
 	}
 	m.mu.Unlock()
 
 	update := models.ProgressUpdate{
 		ID:       id,
-		Progress: progress,
-		Speed:    speed,
-		ETA:      eta,
-		Status:   status,
-		Message:  message,
+		Progress: 100,
+		Status:   consts.STATUS_COMPLETED,
+		Message:  fmt.Sprintf(savedMessage, filepath.Base(saved.Path)),
 	}
+	if saved.Deferred {
+		update.FileURL = jobFileURL(id)
+		update.Message = consts.MSG_READY_TO_FETCH
+	}
+	m.publishUpdate(update)
 
-	log.Printf(consts.LOG_BROADCASTING_UPDATE, update)
-	m.broadcast(update)
+	if saved.Reveal {
+		log.Printf(consts.LOG_OPENING_FILE_EXPLORER)
+		openFileExplorer(filepath.Dir(saved.Path))
+	}
 }
 
-func (m *Manager) broadcast(update models.ProgressUpdate) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+func (m *Manager) registerDownload(id, jobType, quality string, req models.DownloadRequest) {
+	download := newDownload(id, jobType, req.URL, quality)
+	download.request = req
+	m.registerJob(download)
+}
 
-	log.Printf(consts.LOG_BROADCASTING_SUBSCRIBERS, len(m.subscribers))
-	for i, ch := range m.subscribers {
-		select {
-		case ch <- update:
-			log.Printf(consts.LOG_SENT_UPDATE, i)
-		default:
-			log.Printf(consts.LOG_SUBSCRIBER_CHANNEL_FULL, i)
-		}
+func newDownload(id, jobType, url, quality string) *Download {
+	now := time.Now()
+	return &Download{
+		ID:        id,
+		Type:      jobType,
+		URL:       url,
+		Quality:   quality,
+		Status:    consts.STATUS_QUEUED,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 }
 
-func (m *Manager) SubscribeToUpdates() <-chan models.ProgressUpdate {
-	ch := make(chan models.ProgressUpdate, 100)
+func (m *Manager) registerJob(download *Download) {
+	id := download.ID
 	m.mu.Lock()
-	m.subscribers = append(m.subscribers, ch)
+	m.downloads[id] = download
+	entry := download.historyEntry()
+	journalEntry := download.journalEntry()
 	m.mu.Unlock()
-	return ch
+
+	m.saveHistory(entry)
+	// Other jobs are journaled once they are queued.
+	if download.Type == consts.JOB_TYPE_PLAYLIST {
+		m.journal.save(journalEntry)
+	}
 }
 
-func (m *Manager) addResolutionToFilename(filePath, quality string) string {
-	dir := filepath.Dir(filePath)
-	filename := filepath.Base(filePath)
-	ext := filepath.Ext(filename)
-	nameWithoutExt := strings.TrimSuffix(filename, ext)
+func (m *Manager) saveHistory(entry models.HistoryEntry) {
+	if err := m.history.Save(entry); err != nil {
+		log.Printf(consts.WARNING_HISTORY_SAVE_FAILED, err)
+	}
+}
 
-	if quality != "" && quality != consts.BEST_QUALITY && !strings.Contains(nameWithoutExt, quality) {
-		newFilename := fmt.Sprintf("%s [%s]%s", nameWithoutExt, quality, ext)
-		return filepath.Join(dir, newFilename)
+// journalEntry must be called while holding the manager lock.
+func (d *Download) journalEntry() journalEntry {
+	entry := journalEntry{
+		ID:        d.ID,
+		Type:      d.Type,
+		URL:       d.URL,
+		Title:     d.Title,
+		Quality:   d.Quality,
+		Priority:  d.Priority,
+		ParentID:  d.ParentID,
+		Children:  d.Children,
+		Request:   d.request,
+		CreatedAt: d.CreatedAt,
+	}
+	if target, ok := d.saveTarget.(*directorySaveTarget); ok {
+		entry.SaveDir = target.dir
 	}
+	return entry
+}
 
-	return filePath
+// historyEntry must be called while holding the manager lock.
+func (d *Download) historyEntry() models.HistoryEntry {
+	return models.HistoryEntry{
+		ID:          d.ID,
+		Type:        d.Type,
+		URL:         d.URL,
+		Title:       d.Title,
+		Quality:     d.Quality,
+		FilePath:    d.FilePath,
+		Status:      d.Status,
+		Error:       d.Error,
+		ErrorCode:   d.ErrorCode,
+		ParentID:    d.ParentID,
+		Retries:     d.Retries,
+		Attempts:    d.Attempts,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+		CompletedAt: d.CompletedAt,
+	}
 }
 
-func (m *Manager) copyFile(source, dest string) error {
-	sourceFile, err := os.Open(source)
+func (m *Manager) GetVideoInfo(url string) (*models.VideoInfo, error) {
+	parsedURL, err := m.sites.NormalizeVideo(url)
 	if err != nil {
+		return nil, err
+	}
+	return GetVideoInfo(m.cfg, m.ytdlp, parsedURL)
+}
+
+func (m *Manager) CancelDownload(id string) error {
+	m.mu.Lock()
+	if m.queueIndex(id) >= 0 {
+		m.mu.Unlock()
+		return m.RemoveQueuedJob(id)
+	}
+	download, ok := m.downloads[id]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_NOT_FOUND, id)
+	}
+	if download.Type == consts.JOB_TYPE_PLAYLIST {
+		m.mu.Unlock()
+		return m.cancelPlaylist(id)
+	}
+	if download.finished || download.cancelled {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_FINISHED, id)
+	}
+	download.cancelled = true
+	cmd := download.cmd
+	m.mu.Unlock()
+
+	log.Printf(consts.LOG_CANCELLING_JOB, id)
+
+	// Without a process the job is still starting up; processCallback kills
+	// the process as soon as it is attached.
+	if cmd != nil {
+		return killProcessTree(cmd)
+	}
+	return nil
+}
+
+func (m *Manager) PauseDownload(id string) error {
+	m.mu.Lock()
+	download, err := m.runningDownload(id)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	if download.paused {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_ALREADY_PAUSED, id)
+	}
+	if err := suspendProcessTree(download.cmd); err != nil {
+		m.mu.Unlock()
 		return err
 	}
-	defer sourceFile.Close()
+	download.paused = true
+	download.activeStatus = download.Status
+	progress := download.Progress
+	m.mu.Unlock()
 
-	destFile, err := os.Create(dest)
+	log.Printf(consts.LOG_PAUSING_JOB, id)
+	m.updateStatus(id, consts.STATUS_PAUSED, progress, "", "", consts.MSG_JOB_PAUSED)
+	return nil
+}
+
+func (m *Manager) ResumeDownload(id string) error {
+	m.mu.Lock()
+	download, err := m.runningDownload(id)
 	if err != nil {
+		m.mu.Unlock()
 		return err
 	}
-	defer destFile.Close()
+	if !download.paused {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_NOT_PAUSED, id)
+	}
+	if err := resumeProcessTree(download.cmd); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	download.paused = false
+	status := download.activeStatus
+	progress := download.Progress
+	m.mu.Unlock()
 
-	_, err = destFile.ReadFrom(sourceFile)
-	return err
+	log.Printf(consts.LOG_RESUMING_JOB, id)
+	m.updateStatus(id, status, progress, "", "", consts.MSG_JOB_RESUMED)
+	return nil
 }
 
-func (m *Manager) openFilePicker(sourceFile string) (string, error) {
-	filename := filepath.Base(sourceFile)
+// runningDownload must be called with m.mu held.
+func (m *Manager) runningDownload(id string) (*Download, error) {
+	download, ok := m.downloads[id]
+	if !ok {
+		return nil, fmt.Errorf(consts.ERR_JOB_NOT_FOUND, id)
+	}
+	if download.finished || download.cancelled {
+		return nil, fmt.Errorf(consts.ERR_JOB_FINISHED, id)
+	}
+	if download.cmd == nil {
+		return nil, fmt.Errorf(consts.ERR_JOB_NOT_RUNNING, id)
+	}
+	return download, nil
+}
 
-	psScript := fmt.Sprintf(consts.POWERSHELL_FILE_PICKER_SCRIPT,
-		consts.FILE_PICKER_FILTER,
-		filename,
-		consts.SAVE_DIALOG_TITLE,
-		filepath.Ext(filename),
-		consts.CANCELLED_MESSAGE)
+func (m *Manager) processCallback(id string) ProcessCallback {
+	return func(cmd *exec.Cmd) {
+		m.mu.Lock()
+		download, ok := m.downloads[id]
+		if ok {
+			download.cmd = cmd
+		}
+		cancelled := ok && download.cancelled
+		m.mu.Unlock()
 
-	cmd := exec.Command(consts.POWERSHELL_COMMAND, consts.COMMAND_FLAG, psScript)
-	output, err := cmd.Output()
-	if err != nil {
-		log.Printf(consts.POWERSHELL_FAILED, err)
-		return "", fmt.Errorf(consts.ERR_SAVE_FILE_PICKER, err)
+		if cancelled {
+			if err := killProcessTree(cmd); err != nil {
+				log.Printf(consts.LOG_JOB_CONTROL_FAILED, err)
+			}
+		}
+	}
+}
+
+func (m *Manager) detachProcess(id string) {
+	m.mu.Lock()
+	if download, ok := m.downloads[id]; ok {
+		download.cmd = nil
+		download.paused = false
+	}
+	m.mu.Unlock()
+}
+
+// progressCallback drops output that is still buffered after the job was
+// paused or cancelled so it cannot overwrite that state.
+func (m *Manager) progressCallback(id, status string) ProgressCallback {
+	return func(update models.ProgressUpdate) {
+		m.mu.RLock()
+		download, ok := m.downloads[id]
+		skip := !ok || download.paused || download.cancelled
+		if ok && download.Attempt > 1 {
+			update.Attempt = download.Attempt
+			update.MaxAttempts = download.maxAttempts
+		}
+		m.mu.RUnlock()
+
+		if !skip {
+			update.ID = id
+			update.Status = status
+			m.publishUpdate(update)
+		}
 	}
+}
 
-	selectedPath := strings.TrimSpace(string(output))
-	if selectedPath == consts.CANCELLED_MESSAGE || selectedPath == "" {
-		os.Remove(sourceFile)
-		return "", fmt.Errorf(consts.ERR_SAVE_CANCELLED)
+func (m *Manager) isCancelled(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	download, ok := m.downloads[id]
+	return ok && download.cancelled
+}
+
+// finishCancelled ends a cancelled job. A job stopped by a shutdown is left
+// unfinished in the history and the journal, so the next run finds it
+// interrupted.
+func (m *Manager) finishCancelled(id string) {
+	m.mu.RLock()
+	stopped := false
+	progress := 0.0
+	if download, ok := m.downloads[id]; ok {
+		stopped = download.stoppedByShutdown
+		progress = download.Progress
 	}
+	m.mu.RUnlock()
 
-	if err := m.copyFile(sourceFile, selectedPath); err != nil {
-		return "", fmt.Errorf(consts.ERR_SAVE_FILE_PICKER, err)
+	if stopped {
+		log.Printf(consts.LOG_JOB_STOPPED_AT_SHUTDOWN, id)
+		return
 	}
 
-	os.Remove(sourceFile)
-	return selectedPath, nil
+	m.updateStatus(id, consts.STATUS_CANCELLED, progress, "", "", consts.MSG_JOB_CANCELLED)
+}
+
+// keptForResume reports whether a job stopped by a shutdown ended without
+// completing, so the next run can resume it. It must be called with m.mu
+// held.
+func (d *Download) keptForResume() bool {
+	return d.stoppedByShutdown && d.Status != consts.STATUS_COMPLETED
+}
+
+func isTerminalStatus(status string) bool {
+	return status == consts.STATUS_COMPLETED || status == consts.STATUS_ERROR || status == consts.STATUS_CANCELLED
+}
+
+// failJob ends a job with err sorted into an error code. format says what
+// failed, such as consts.ERR_DOWNLOAD_FAILED.
+func (m *Manager) failJob(id, format string, err error) {
+	jobErr := ClassifyError(err)
+	m.publishUpdate(models.ProgressUpdate{
+		ID:        id,
+		Status:    consts.STATUS_ERROR,
+		Message:   fmt.Sprintf(format, jobErr.Message),
+		Code:      jobErr.Code,
+		Retryable: jobErr.Retryable,
+		Hint:      jobErr.Hint,
+	})
+}
+
+func (m *Manager) updateStatus(id, status string, progress float64, speed, eta, message string) {
+	m.publishUpdate(models.ProgressUpdate{
+		ID:       id,
+		Progress: progress,
+		Speed:    speed,
+		ETA:      eta,
+		Status:   status,
+		Message:  message,
+	})
 }
 
-func (m *Manager) openFileExplorer(path string) {
-	exec.Command(consts.EXPLORER_COMMAND, path).Start()
+func (m *Manager) publishUpdate(update models.ProgressUpdate) {
+	var entry *models.HistoryEntry
+	finished := false
+
+	if update.Status == consts.STATUS_CANCELLED && update.Code == "" {
+		update.Code = consts.ERROR_CODE_CANCELLED
+	}
+
+	m.mu.Lock()
+	if download, ok := m.downloads[update.ID]; ok {
+		update.ParentID = download.ParentID
+		// Only state transitions are persisted; progress ticks stay in memory.
+		if download.Status != update.Status {
+			download.UpdatedAt = time.Now()
+			if update.Status == consts.STATUS_ERROR || update.Status == consts.STATUS_CANCELLED {
+				download.ErrorCode = update.Code
+			}
+			if update.Status == consts.STATUS_ERROR {
+				download.Error = update.Message
+			}
+			if isTerminalStatus(update.Status) {
+				completedAt := download.UpdatedAt
+				download.CompletedAt = &completedAt
+				finished = true
+			}
+			historyEntry := download.historyEntry()
+			historyEntry.Status = update.Status
+			entry = &historyEntry
+		}
+		download.Status = update.Status
+		download.finished = isTerminalStatus(update.Status)
+		download.Progress = update.Progress
+		download.Speed = update.Speed
+		download.ETA = update.ETA
+		download.Phase = update.Phase
+		finished = finished && !download.keptForResume()
+	}
+	m.mu.Unlock()
+
+	if entry != nil {
+		m.saveHistory(*entry)
+	}
+	if finished {
+		m.journal.remove(update.ID)
+	}
+
+	log.Printf(consts.LOG_BROADCASTING_UPDATE, update)
+	m.broadcast(update)
+
+	if update.ParentID != "" {
+		m.updatePlaylist(update.ParentID)
+	}
+}
+
+func (m *Manager) addResolutionToFilename(filePath, quality string) string {
+	dir := filepath.Dir(filePath)
+	filename := filepath.Base(filePath)
+	ext := filepath.Ext(filename)
+	nameWithoutExt := strings.TrimSuffix(filename, ext)
+
+	if quality != "" && quality != consts.BEST_QUALITY && !strings.Contains(nameWithoutExt, quality) {
+		newFilename := fmt.Sprintf("%s [%s]%s", nameWithoutExt, quality, ext)
+		return filepath.Join(dir, newFilename)
+	}
+
+	return filePath
 }
@@ -0,0 +1,97 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// flatPlaylist is the part of yt-dlp's --flat-playlist JSON we use.
+type flatPlaylist struct {
+	ID       string      `json:"id"`
+	Title    string      `json:"title"`
+	Uploader string      `json:"uploader"`
+	Channel  string      `json:"channel"`
+	Entries  []flatEntry `json:"entries"`
+}
+
+type flatEntry struct {
+	ID         string  `json:"id"`
+	URL        string  `json:"url"`
+	WebpageURL string  `json:"webpage_url"`
+	IEKey      string  `json:"ie_key"`
+	Title      string  `json:"title"`
+	Duration   float64 `json:"duration"`
+}
+
+// GetPlaylistInfo lists the entries of a playlist or channel without
+// fetching each video. The URL must already be normalized by the site
+// registry.
+func GetPlaylistInfo(cfg *config.Config, ytdlp YtDlpClient, parsedURL string) (*models.PlaylistInfo, error) {
+	args := append([]string{}, cfg.YtDlp.PlaylistArgs...)
+	args = append(args, networkArgs(cfg, parsedURL)...)
+	args = append(args, parsedURL)
+	output, err := ytdlp.Output(args)
+	if err != nil {
+		return nil, validateVideoInfoError(err)
+	}
+
+	var playlist flatPlaylist
+	if err := json.Unmarshal(output, &playlist); err != nil {
+		return nil, fmt.Errorf(consts.ERR_PARSE_PLAYLIST, err)
+	}
+
+	return buildPlaylistInfo(playlist, parsedURL), nil
+}
+
+func buildPlaylistInfo(playlist flatPlaylist, parsedURL string) *models.PlaylistInfo {
+	info := &models.PlaylistInfo{
+		ID:        playlist.ID,
+		Title:     playlist.Title,
+		Uploader:  playlist.Uploader,
+		ParsedURL: parsedURL,
+		Entries:   []models.PlaylistEntry{},
+	}
+	if info.Uploader == "" {
+		info.Uploader = playlist.Channel
+	}
+
+	for _, entry := range playlist.Entries {
+		entryURL := playlistEntryURL(entry)
+		if entryURL == "" {
+			continue
+		}
+
+		item := models.PlaylistEntry{
+			Index: len(info.Entries) + 1,
+			ID:    entry.ID,
+			URL:   entryURL,
+			Title: entry.Title,
+		}
+		if entry.Duration > 0 {
+			item.Duration = fmt.Sprintf(consts.DURATION_FORMAT, int(entry.Duration)/60, int(entry.Duration)%60)
+		}
+		info.Entries = append(info.Entries, item)
+	}
+
+	return info
+}
+
+// playlistEntryURL prefers the page URL yt-dlp reports for an entry. Entries listed
+// by ID alone are turned into a URL by the extractor that listed them, and
+// skipped when that extractor is unknown.
+func playlistEntryURL(entry flatEntry) string {
+	if entry.WebpageURL != "" {
+		return entry.WebpageURL
+	}
+	if strings.Contains(entry.URL, consts.URL_PATH_SEPARATOR) {
+		return entry.URL
+	}
+	if format, ok := consts.PLAYLIST_ENTRY_URL_FORMATS[entry.IEKey]; ok && entry.ID != "" {
+		return fmt.Sprintf(format, entry.ID)
+	}
+	return ""
+}
@@ -0,0 +1,332 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// A playlist job is a parent that never runs itself. Each selected entry is
+// an ordinary child job with its own status, retries and final path; the
+// parent's status and progress are recomputed whenever a child changes.
+
+func (m *Manager) GetPlaylistInfo(url string) (*models.PlaylistInfo, error) {
+	parsedURL, err := m.sites.NormalizePlaylist(url)
+	if err != nil {
+		return nil, err
+	}
+	return GetPlaylistInfo(m.cfg, m.ytdlp, parsedURL)
+}
+
+// StartPlaylist registers the playlist job and queues every selected entry
+// as a child job. The playlist and each entry must pass the site lists.
+func (m *Manager) StartPlaylist(req models.PlaylistDownloadRequest) (models.JobStatus, error) {
+	if len(req.Entries) == 0 {
+		return models.JobStatus{}, fmt.Errorf(consts.ERR_PLAYLIST_EMPTY)
+	}
+	playlistURL, err := m.sites.NormalizePlaylist(req.URL)
+	if err != nil {
+		return models.JobStatus{}, err
+	}
+	if err := validateMaxAttempts(req.MaxAttempts); err != nil {
+		return models.JobStatus{}, err
+	}
+
+	itemType := req.Type
+	if itemType == "" {
+		itemType = consts.JOB_TYPE_VIDEO
+	}
+	var idFormat, quality string
+	var audio *models.AudioOptions
+	switch itemType {
+	case consts.JOB_TYPE_VIDEO:
+		if err := validatePreferences(req.Preferences, req.Quality, ""); err != nil {
+			return models.JobStatus{}, err
+		}
+		idFormat, quality = consts.DOWNLOAD_ID_FORMAT, req.Quality
+	case consts.JOB_TYPE_AUDIO, consts.JOB_TYPE_MP3:
+		// The playlist's quality is a video resolution; audio items only
+		// take their quality from the audio options.
+		opts := resolveAudioOptions(models.DownloadRequest{Audio: req.Audio})
+		if err := validateAudioOptions(opts); err != nil {
+			return models.JobStatus{}, err
+		}
+		itemType, idFormat, quality, audio = consts.JOB_TYPE_AUDIO, consts.AUDIO_ID_FORMAT, audioLabel(opts), &opts
+	default:
+		return models.JobStatus{}, fmt.Errorf(consts.ERR_UNKNOWN_JOB_TYPE, req.Type)
+	}
+
+	parentID := newJobID(consts.PLAYLIST_ID_FORMAT)
+	parent := newDownload(parentID, consts.JOB_TYPE_PLAYLIST, playlistURL, quality)
+	parent.Title = req.Title
+	target := m.playlistSaveTarget(req.Title, parentID)
+
+	children := make([]*Download, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		entryURL, err := m.sites.NormalizeVideo(entry.URL)
+		if err != nil {
+			return models.JobStatus{}, err
+		}
+		child := newDownload(newJobID(idFormat), itemType, entryURL, quality)
+		child.Title = entry.Title
+		child.ParentID = parentID
+		child.saveTarget = target
+		child.request = models.DownloadRequest{URL: entryURL, Quality: req.Quality, Audio: audio, Preferences: req.Preferences, MaxAttempts: req.MaxAttempts}
+		parent.Children = append(parent.Children, child.ID)
+		children = append(children, child)
+	}
+
+	// Every item is registered before the first one can start, so the
+	// playlist is never recomputed from a partial list.
+	m.registerJob(parent)
+	for _, child := range children {
+		m.registerJob(child)
+	}
+	log.Printf(consts.LOG_PLAYLIST_STARTED, parentID, len(children))
+
+	for _, child := range children {
+		m.enqueue(child.ID, req.Priority, m.jobRunner(child.ID, child.Type, child.request))
+	}
+
+	return m.GetJob(parentID)
+}
+
+func (m *Manager) jobRunner(id, jobType string, req models.DownloadRequest) func() {
+	switch jobType {
+	case consts.JOB_TYPE_AUDIO, consts.JOB_TYPE_MP3:
+		return func() { m.extractAudio(id, req) }
+	case consts.JOB_TYPE_SUBTITLES:
+		return func() { m.downloadSubtitles(id, req) }
+	}
+	return func() { m.download(id, req) }
+}
+
+// playlistSaveTarget keeps playlist items out of save dialogs: unless the
+// browser fetches them, they go to a folder named after the playlist.
+func (m *Manager) playlistSaveTarget(title, id string) SaveTarget {
+	if _, ok := m.saveTarget.(*browserSaveTarget); ok {
+		return m.saveTarget
+	}
+	return &directorySaveTarget{dir: filepath.Join(m.cfg.Paths.OutputDir, folderName(title, id))}
+}
+
+func (m *Manager) saveTargetFor(id string) SaveTarget {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if download, ok := m.downloads[id]; ok && download.saveTarget != nil {
+		return download.saveTarget
+	}
+	return m.saveTarget
+}
+
+// folderName turns a playlist title into a directory name that is valid on
+// every platform, falling back to the job ID.
+func folderName(title, fallback string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(consts.INVALID_FILENAME_CHARS, r) {
+			return '_'
+		}
+		return r
+	}, title)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// updatePlaylist publishes the playlist's aggregate state. Finished items
+// count as done towards the progress; once every item has finished the
+// playlist fails if any item failed.
+func (m *Manager) updatePlaylist(id string) {
+	m.playlistMu.Lock()
+	defer m.playlistMu.Unlock()
+
+	m.mu.RLock()
+	parent, ok := m.downloads[id]
+	if !ok || len(parent.Children) == 0 {
+		m.mu.RUnlock()
+		return
+	}
+
+	total := len(parent.Children)
+	var completed, failed, cancelled, queued int
+	var progress float64
+	for _, childID := range parent.Children {
+		child := m.downloads[childID]
+		switch child.Status {
+		case consts.STATUS_COMPLETED:
+			completed++
+			progress += 100
+		case consts.STATUS_ERROR:
+			failed++
+			progress += 100
+		case consts.STATUS_CANCELLED:
+			cancelled++
+			progress += 100
+		case consts.STATUS_QUEUED:
+			queued++
+		default:
+			progress += child.Progress
+		}
+	}
+	m.mu.RUnlock()
+
+	finished := completed + failed + cancelled
+	update := models.ProgressUpdate{
+		ID:             id,
+		Progress:       progress / float64(total),
+		Message:        fmt.Sprintf(consts.MSG_PLAYLIST_PROGRESS, finished, total),
+		TotalItems:     total,
+		CompletedItems: completed,
+		FailedItems:    failed,
+	}
+	switch {
+	case queued == total:
+		update.Status = consts.STATUS_QUEUED
+	case finished < total:
+		update.Status = consts.STATUS_DOWNLOADING
+	case failed > 0:
+		update.Status = consts.STATUS_ERROR
+		update.Message = fmt.Sprintf(consts.MSG_PLAYLIST_ITEMS_FAILED, failed, total)
+	case completed == 0:
+		update.Status = consts.STATUS_CANCELLED
+	default:
+		update.Status = consts.STATUS_COMPLETED
+	}
+
+	m.publishUpdate(update)
+}
+
+// cancelPlaylist cancels every unfinished item. Queued items go first so the
+// dispatcher cannot start them while the running ones are being stopped.
+func (m *Manager) cancelPlaylist(id string) error {
+	m.mu.RLock()
+	var queued, active []string
+	for _, childID := range m.downloads[id].Children {
+		if m.queueIndex(childID) >= 0 {
+			queued = append(queued, childID)
+		} else {
+			active = append(active, childID)
+		}
+	}
+	m.mu.RUnlock()
+
+	cancelled := 0
+	for _, childID := range append(queued, active...) {
+		if err := m.CancelDownload(childID); err == nil {
+			cancelled++
+		}
+	}
+	if cancelled == 0 {
+		return fmt.Errorf(consts.ERR_JOB_FINISHED, id)
+	}
+	return nil
+}
+
+// RetryDownload queues a failed or cancelled job again. For a playlist it
+// retries every item that failed or was cancelled.
+func (m *Manager) RetryDownload(id string) error {
+	m.mu.RLock()
+	download, ok := m.downloads[id]
+	if !ok {
+		m.mu.RUnlock()
+		return fmt.Errorf(consts.ERR_JOB_NOT_FOUND, id)
+	}
+	if download.Type != consts.JOB_TYPE_PLAYLIST {
+		m.mu.RUnlock()
+		return m.retryJob(id)
+	}
+
+	var retryable []string
+	for _, childID := range download.Children {
+		if m.downloads[childID].retryable() {
+			retryable = append(retryable, childID)
+		}
+	}
+	m.mu.RUnlock()
+
+	if len(retryable) == 0 {
+		return fmt.Errorf(consts.ERR_NOTHING_TO_RETRY, id)
+	}
+	for _, childID := range retryable {
+		if err := m.retryJob(childID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) retryJob(id string) error {
+	m.mu.Lock()
+	download := m.downloads[id]
+	if !download.retryable() {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_NOT_RETRYABLE, id, download.Status)
+	}
+	download.Retries++
+	download.cancelled = false
+	download.finished = false
+	download.Error = ""
+	download.ErrorCode = ""
+	download.Attempt = 0
+	download.CompletedAt = nil
+	retries := download.Retries
+	priority := download.Priority
+	run := download.run
+	m.mu.Unlock()
+
+	log.Printf(consts.LOG_RETRYING_JOB, id, retries)
+	m.updateStatus(id, consts.STATUS_QUEUED, 0, "", "", fmt.Sprintf(consts.MSG_JOB_RETRYING, retries))
+	m.enqueue(id, priority, run)
+	return nil
+}
+
+// retryable must be called with m.mu held.
+func (d *Download) retryable() bool {
+	return d.finished && d.run != nil && (d.Status == consts.STATUS_ERROR || d.Status == consts.STATUS_CANCELLED)
+}
+
+// GetJob returns a snapshot of a job, including the items of a playlist.
+func (m *Manager) GetJob(id string) (models.JobStatus, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	download, ok := m.downloads[id]
+	if !ok {
+		return models.JobStatus{}, fmt.Errorf(consts.ERR_JOB_NOT_FOUND, id)
+	}
+
+	status := download.jobStatus()
+	for _, childID := range download.Children {
+		status.Children = append(status.Children, m.downloads[childID].jobStatus())
+	}
+	return status, nil
+}
+
+// jobStatus must be called while holding the manager lock.
+func (d *Download) jobStatus() models.JobStatus {
+	return models.JobStatus{
+		ID:          d.ID,
+		Type:        d.Type,
+		URL:         d.URL,
+		Title:       d.Title,
+		Quality:     d.Quality,
+		Status:      d.Status,
+		Progress:    d.Progress,
+		FilePath:    d.FilePath,
+		Error:       d.Error,
+		ErrorCode:   d.ErrorCode,
+		ParentID:    d.ParentID,
+		Retries:     d.Retries,
+		Attempts:    d.Attempts,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+		CompletedAt: d.CompletedAt,
+	}
+}
@@ -0,0 +1,76 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/history"
+	"Go-Utilities/internal/models"
+	"Go-Utilities/internal/sites"
+	"reflect"
+	"testing"
+)
+
+func TestBuildPlaylistInfo(t *testing.T) {
+	playlist := flatPlaylist{
+		ID:      "PL1",
+		Title:   "Mixed",
+		Channel: "Channel",
+		Entries: []flatEntry{
+			{ID: "abc123", URL: "abc123", IEKey: "Youtube", Title: "By ID", Duration: 125},
+			{ID: "456", URL: "https://vimeo.com/456", IEKey: "Vimeo"},
+			{ID: "789", URL: "789", WebpageURL: "https://www.dailymotion.com/video/789", IEKey: "Dailymotion"},
+			{ID: "track", URL: "track", IEKey: "Soundcloud"},
+			{Title: "No URL"},
+		},
+	}
+
+	info := buildPlaylistInfo(playlist, "https://www.youtube.com/playlist?list=PL1")
+
+	if info.Uploader != "Channel" {
+		t.Errorf("uploader = %q, want the channel", info.Uploader)
+	}
+	var urls []string
+	for i, entry := range info.Entries {
+		if entry.Index != i+1 {
+			t.Errorf("entry %q has index %d, want %d", entry.URL, entry.Index, i+1)
+		}
+		urls = append(urls, entry.URL)
+	}
+	want := []string{
+		"https://www.youtube.com/watch?v=abc123",
+		"https://vimeo.com/456",
+		"https://www.dailymotion.com/video/789",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("entry URLs = %v, want %v", urls, want)
+	}
+	if info.Entries[0].Duration != "2:05" {
+		t.Errorf("duration = %q, want 2:05", info.Entries[0].Duration)
+	}
+}
+
+func TestStartPlaylistChecksPlaylistSite(t *testing.T) {
+	entries := []models.PlaylistEntry{{URL: "https://www.youtube.com/watch?v=abc123"}}
+
+	tests := []struct {
+		name  string
+		url   string
+		allow []string
+		deny  []string
+	}{
+		{name: "denied playlist site", url: "https://soundcloud.com/artist/sets/album", deny: []string{"soundcloud.com"}},
+		{name: "playlist site not allowed", url: "https://soundcloud.com/artist/sets/album", allow: []string{"youtube.com"}},
+		{name: "invalid playlist URL", url: "ftp://www.youtube.com/playlist?list=PL1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{downloads: make(map[string]*Download), history: history.NewMemoryStore(), cfg: config.Default(), sites: sites.NewRegistry(tt.allow, tt.deny)}
+			if _, err := m.StartPlaylist(models.PlaylistDownloadRequest{URL: tt.url, Entries: entries}); err == nil {
+				t.Fatal("StartPlaylist() succeeded")
+			}
+			if len(m.downloads) != 0 {
+				t.Errorf("StartPlaylist() registered %d jobs", len(m.downloads))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess places the child in its own process group so that
+// yt-dlp and the ffmpeg processes it spawns can be signalled together.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessTree(cmd *exec.Cmd) error {
+	if err := signalProcessGroup(cmd, syscall.SIGKILL); err != nil {
+		return fmt.Errorf(consts.ERR_KILL_PROCESS, err)
+	}
+	return nil
+}
+
+func suspendProcessTree(cmd *exec.Cmd) error {
+	if err := signalProcessGroup(cmd, syscall.SIGSTOP); err != nil {
+		return fmt.Errorf(consts.ERR_SUSPEND_PROCESS, err)
+	}
+	return nil
+}
+
+func resumeProcessTree(cmd *exec.Cmd) error {
+	if err := signalProcessGroup(cmd, syscall.SIGCONT); err != nil {
+		return fmt.Errorf(consts.ERR_RESUME_PROCESS, err)
+	}
+	return nil
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.Process == nil {
+		return syscall.ESRCH
+	}
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	if err != nil {
+		return err
+	}
+	return syscall.Kill(-pgid, sig)
+}
@@ -0,0 +1,97 @@
+//go:build windows
+
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	ntdll              = syscall.NewLazyDLL(consts.NTDLL_DLL)
+	procSuspendProcess = ntdll.NewProc(consts.NT_SUSPEND_PROCESS_PROC)
+	procResumeProcess  = ntdll.NewProc(consts.NT_RESUME_PROCESS_PROC)
+)
+
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
+
+// killProcessTree relies on taskkill /T, which also terminates the ffmpeg
+// children yt-dlp starts for merging and post-processing.
+func killProcessTree(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return fmt.Errorf(consts.ERR_KILL_PROCESS, syscall.ESRCH)
+	}
+	pid := strconv.Itoa(cmd.Process.Pid)
+	if err := exec.Command(consts.TASKKILL_COMMAND, "/T", "/F", "/PID", pid).Run(); err != nil {
+		return fmt.Errorf(consts.ERR_KILL_PROCESS, err)
+	}
+	return nil
+}
+
+func suspendProcessTree(cmd *exec.Cmd) error {
+	if err := forEachProcessInTree(cmd, procSuspendProcess); err != nil {
+		return fmt.Errorf(consts.ERR_SUSPEND_PROCESS, err)
+	}
+	return nil
+}
+
+func resumeProcessTree(cmd *exec.Cmd) error {
+	if err := forEachProcessInTree(cmd, procResumeProcess); err != nil {
+		return fmt.Errorf(consts.ERR_RESUME_PROCESS, err)
+	}
+	return nil
+}
+
+func forEachProcessInTree(cmd *exec.Cmd, proc *syscall.LazyProc) error {
+	if cmd.Process == nil {
+		return syscall.ESRCH
+	}
+
+	pids, err := processTree(uint32(cmd.Process.Pid))
+	if err != nil {
+		return err
+	}
+
+	for _, pid := range pids {
+		handle, err := syscall.OpenProcess(consts.PROCESS_SUSPEND_RESUME, false, pid)
+		if err != nil {
+			continue
+		}
+		proc.Call(uintptr(handle))
+		syscall.CloseHandle(handle)
+	}
+	return nil
+}
+
+func processTree(root uint32) ([]uint32, error) {
+	snapshot, err := syscall.CreateToolhelp32Snapshot(consts.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.CloseHandle(snapshot)
+
+	children := make(map[uint32][]uint32)
+	var entry syscall.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = syscall.Process32First(snapshot, &entry); err == nil; err = syscall.Process32Next(snapshot, &entry) {
+		children[entry.ParentProcessID] = append(children[entry.ParentProcessID], entry.ProcessID)
+	}
+
+	pids := []uint32{root}
+	seen := map[uint32]bool{root: true}
+	for i := 0; i < len(pids); i++ {
+		for _, child := range children[pids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				pids = append(pids, child)
+			}
+		}
+	}
+	return pids, nil
+}
@@ -0,0 +1,167 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// yt-dlp renders missing template fields as a bare NA, which is not valid JSON.
+var progressNARegex = regexp.MustCompile(consts.YT_DLP_PROGRESS_NA_REGEX)
+
+type downloadProgressLine struct {
+	Progress struct {
+		Status             string  `json:"status"`
+		DownloadedBytes    float64 `json:"downloaded_bytes"`
+		TotalBytes         float64 `json:"total_bytes"`
+		TotalBytesEstimate float64 `json:"total_bytes_estimate"`
+		Speed              float64 `json:"speed"`
+		ETA                float64 `json:"eta"`
+		FragmentIndex      int     `json:"fragment_index"`
+		FragmentCount      int     `json:"fragment_count"`
+	} `json:"progress"`
+	VCodec   string `json:"vcodec"`
+	ACodec   string `json:"acodec"`
+	FormatID string `json:"format_id"`
+}
+
+type postprocessProgressLine struct {
+	Status        string `json:"status"`
+	Postprocessor string `json:"postprocessor"`
+}
+
+// parseProgressLine turns one line emitted through YT_DLP_PROGRESS_ARGS into
+// a progress update. It reports false for any other output.
+func parseProgressLine(line string) (models.ProgressUpdate, bool) {
+	line = strings.TrimSpace(line)
+
+	if payload, ok := strings.CutPrefix(line, consts.YT_DLP_DOWNLOAD_PROGRESS_PREFIX); ok {
+		var parsed downloadProgressLine
+		if err := json.Unmarshal(sanitizeProgressJSON(payload), &parsed); err != nil {
+			return models.ProgressUpdate{}, false
+		}
+		return buildDownloadProgress(parsed), true
+	}
+
+	if payload, ok := strings.CutPrefix(line, consts.YT_DLP_POSTPROCESS_PROGRESS_PREFIX); ok {
+		var parsed postprocessProgressLine
+		if err := json.Unmarshal(sanitizeProgressJSON(payload), &parsed); err != nil {
+			return models.ProgressUpdate{}, false
+		}
+		phase := postprocessorPhase(parsed.Postprocessor)
+		return models.ProgressUpdate{
+			Progress: 100,
+			Phase:    phase,
+			Message:  phaseMessage(phase),
+		}, true
+	}
+
+	return models.ProgressUpdate{}, false
+}
+
+// reportProgress forwards structured progress lines and the plain-text
+// "already downloaded" notice, which yt-dlp prints instead of any progress.
+func reportProgress(line string, progressCallback ProgressCallback, completeMessage string) {
+	if progressCallback == nil {
+		return
+	}
+
+	if update, ok := parseProgressLine(line); ok {
+		progressCallback(update)
+		return
+	}
+
+	if strings.Contains(line, consts.YT_DLP_ALREADY_DOWNLOADED) {
+		progressCallback(models.ProgressUpdate{Progress: 100, Message: completeMessage})
+	}
+}
+
+func sanitizeProgressJSON(payload string) []byte {
+	return []byte(progressNARegex.ReplaceAllString(strings.TrimSpace(payload), consts.YT_DLP_PROGRESS_NA_REPLACEMENT))
+}
+
+func buildDownloadProgress(parsed downloadProgressLine) models.ProgressUpdate {
+	p := parsed.Progress
+	total := p.TotalBytes
+	if total <= 0 {
+		total = p.TotalBytesEstimate
+	}
+
+	update := models.ProgressUpdate{
+		Phase:           downloadPhase(parsed.VCodec, parsed.ACodec),
+		SpeedBytes:      p.Speed,
+		ETASeconds:      int(p.ETA),
+		DownloadedBytes: int64(p.DownloadedBytes),
+		TotalBytes:      int64(total),
+		FragmentIndex:   p.FragmentIndex,
+		FragmentCount:   p.FragmentCount,
+	}
+
+	switch {
+	case p.Status == consts.YT_DLP_PROGRESS_STATUS_FINISHED:
+		update.Progress = 100
+	case total > 0:
+		update.Progress = p.DownloadedBytes / total * 100
+	case p.FragmentCount > 0:
+		update.Progress = float64(p.FragmentIndex) / float64(p.FragmentCount) * 100
+	}
+
+	if p.Speed > 0 {
+		update.Speed = FormatFileSize(int64(p.Speed)) + consts.SPEED_SUFFIX
+	}
+	if p.ETA > 0 {
+		update.ETA = formatETA(int(p.ETA))
+	}
+	update.Message = phaseMessage(update.Phase)
+
+	return update
+}
+
+func downloadPhase(vcodec, acodec string) string {
+	if vcodec == consts.CODEC_NONE && acodec != "" && acodec != consts.CODEC_NONE {
+		return consts.PHASE_DOWNLOAD_AUDIO
+	}
+	return consts.PHASE_DOWNLOAD_VIDEO
+}
+
+func postprocessorPhase(postprocessor string) string {
+	switch postprocessor {
+	case consts.YT_DLP_PP_MERGER:
+		return consts.PHASE_MERGE
+	case consts.YT_DLP_PP_EXTRACT_AUDIO:
+		return consts.PHASE_EXTRACT_AUDIO
+	case consts.YT_DLP_PP_METADATA, consts.YT_DLP_PP_EMBED_THUMBNAIL:
+		return consts.PHASE_EMBED_METADATA
+	case consts.YT_DLP_PP_SPLIT_CHAPTERS:
+		return consts.PHASE_SPLIT_CHAPTERS
+	}
+	return consts.PHASE_POSTPROCESS
+}
+
+func phaseMessage(phase string) string {
+	switch phase {
+	case consts.PHASE_DOWNLOAD_VIDEO:
+		return consts.MSG_PHASE_DOWNLOAD_VIDEO
+	case consts.PHASE_DOWNLOAD_AUDIO:
+		return consts.MSG_PHASE_DOWNLOAD_AUDIO
+	case consts.PHASE_MERGE:
+		return consts.MSG_PHASE_MERGE
+	case consts.PHASE_EXTRACT_AUDIO:
+		return consts.MSG_PHASE_EXTRACT_AUDIO
+	case consts.PHASE_EMBED_METADATA:
+		return consts.MSG_PHASE_EMBED_METADATA
+	case consts.PHASE_SPLIT_CHAPTERS:
+		return consts.MSG_PHASE_SPLIT_CHAPTERS
+	}
+	return consts.MSG_PHASE_POSTPROCESS
+}
+
+func formatETA(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf(consts.ETA_HOURS_FORMAT, seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf(consts.DURATION_FORMAT, seconds/60, seconds%60)
+}
@@ -0,0 +1,152 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"testing"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   models.ProgressUpdate
+		wantOK bool
+	}{
+		{
+			name: "video bytes",
+			line: `[goutil:download] {"progress":{"status":"downloading","downloaded_bytes":5242880,"total_bytes":10485760,"speed":1048576,"eta":5},"vcodec":"avc1.640028","acodec":"none","format_id":"137"}`,
+			want: models.ProgressUpdate{
+				Progress:        50,
+				Phase:           consts.PHASE_DOWNLOAD_VIDEO,
+				Message:         consts.MSG_PHASE_DOWNLOAD_VIDEO,
+				Speed:           "1.0 MB/s",
+				ETA:             "0:05",
+				SpeedBytes:      1048576,
+				ETASeconds:      5,
+				DownloadedBytes: 5242880,
+				TotalBytes:      10485760,
+			},
+			wantOK: true,
+		},
+		{
+			name: "audio with estimated size",
+			line: `[goutil:download] {"progress":{"status":"downloading","downloaded_bytes":250,"total_bytes":NA,"total_bytes_estimate":1000,"speed":NA,"eta":NA},"vcodec":"none","acodec":"opus","format_id":"251"}`,
+			want: models.ProgressUpdate{
+				Progress:        25,
+				Phase:           consts.PHASE_DOWNLOAD_AUDIO,
+				Message:         consts.MSG_PHASE_DOWNLOAD_AUDIO,
+				DownloadedBytes: 250,
+				TotalBytes:      1000,
+			},
+			wantOK: true,
+		},
+		{
+			name: "fragments without size",
+			line: `[goutil:download] {"progress":{"status":"downloading","downloaded_bytes":NA,"total_bytes":NA,"fragment_index":3,"fragment_count":12,"eta":3725},"vcodec":"avc1","acodec":"mp4a.40.2","format_id":"95"}`,
+			want: models.ProgressUpdate{
+				Progress:      25,
+				Phase:         consts.PHASE_DOWNLOAD_VIDEO,
+				Message:       consts.MSG_PHASE_DOWNLOAD_VIDEO,
+				ETA:           "1:02:05",
+				ETASeconds:    3725,
+				FragmentIndex: 3,
+				FragmentCount: 12,
+			},
+			wantOK: true,
+		},
+		{
+			name: "finished",
+			line: `[goutil:download] {"progress":{"status":"finished","downloaded_bytes":NA,"total_bytes":NA},"vcodec":"vp9","acodec":"none","format_id":"248"}`,
+			want: models.ProgressUpdate{
+				Progress: 100,
+				Phase:    consts.PHASE_DOWNLOAD_VIDEO,
+				Message:  consts.MSG_PHASE_DOWNLOAD_VIDEO,
+			},
+			wantOK: true,
+		},
+		{
+			name: "merger",
+			line: `[goutil:postprocess] {"status":"started","postprocessor":"Merger"}`,
+			want: models.ProgressUpdate{
+				Progress: 100,
+				Phase:    consts.PHASE_MERGE,
+				Message:  consts.MSG_PHASE_MERGE,
+			},
+			wantOK: true,
+		},
+		{
+			name: "unknown postprocessor",
+			line: `[goutil:postprocess] {"status":"started","postprocessor":"MoveFiles"}`,
+			want: models.ProgressUpdate{
+				Progress: 100,
+				Phase:    consts.PHASE_POSTPROCESS,
+				Message:  consts.MSG_PHASE_POSTPROCESS,
+			},
+			wantOK: true,
+		},
+		{
+			name: "plain output",
+			line: "[youtube] dQw4w9WgXcQ: Downloading webpage",
+		},
+		{
+			name: "broken JSON",
+			line: `[goutil:download] {"progress":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseProgressLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseProgressLine() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("parseProgressLine() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []models.ProgressUpdate
+	}{
+		{
+			name: "already downloaded",
+			line: "[download] /tmp/job/Title.mp4 has already been downloaded",
+			want: []models.ProgressUpdate{{Progress: 100, Message: consts.MSG_DOWNLOAD_COMPLETE}},
+		},
+		{
+			name: "progress line",
+			line: `[goutil:postprocess] {"status":"started","postprocessor":"ExtractAudio"}`,
+			want: []models.ProgressUpdate{{Progress: 100, Phase: consts.PHASE_EXTRACT_AUDIO, Message: consts.MSG_PHASE_EXTRACT_AUDIO}},
+		},
+		{
+			name: "other output",
+			line: "[info] Downloading 1 format(s): 137+140",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []models.ProgressUpdate
+			reportProgress(tt.line, func(update models.ProgressUpdate) {
+				got = append(got, update)
+			}, consts.MSG_DOWNLOAD_COMPLETE)
+			if len(got) != len(tt.want) {
+				t.Fatalf("reportProgress() sent %d updates, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("update %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	// Without a callback the line is ignored.
+	reportProgress(`[goutil:postprocess] {"status":"started","postprocessor":"Merger"}`, nil, consts.MSG_DOWNLOAD_COMPLETE)
+}
@@ -0,0 +1,211 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+)
+
+// enqueue places a registered job behind every queued job of equal or higher
+// priority, so jobs of the same priority run in FIFO order.
+func (m *Manager) enqueue(id string, priority int, run func()) {
+	m.mu.Lock()
+	download, ok := m.downloads[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	download.Priority = priority
+	download.run = run
+	m.queue = insertByPriority(m.queue, download)
+	entry := download.journalEntry()
+	m.mu.Unlock()
+
+	m.journal.save(entry)
+
+	m.broadcastQueuePositions()
+	m.dispatch()
+}
+
+// dispatch starts queued jobs until the concurrency limit is reached.
+func (m *Manager) dispatch() {
+	m.mu.Lock()
+	started := false
+	for m.running < m.maxConcurrent && len(m.queue) > 0 {
+		download := m.queue[0]
+		m.queue = m.queue[1:]
+		m.running++
+		m.jobs.Add(1)
+		started = true
+		go m.runJob(download.ID, download.run)
+	}
+	m.mu.Unlock()
+
+	if started {
+		m.broadcastQueuePositions()
+	}
+}
+
+func (m *Manager) runJob(id string, run func()) {
+	log.Printf(consts.LOG_JOB_DEQUEUED, id)
+	defer func() {
+		m.mu.Lock()
+		m.running--
+		m.mu.Unlock()
+		m.jobs.Done()
+		m.dispatch()
+	}()
+	run()
+}
+
+func (m *Manager) SetMaxConcurrent(max int) error {
+	if max < 1 || max > consts.MAX_CONCURRENT_JOBS_LIMIT {
+		return fmt.Errorf(consts.ERR_INVALID_CONCURRENCY, max, consts.MAX_CONCURRENT_JOBS_LIMIT)
+	}
+
+	m.mu.Lock()
+	m.maxConcurrent = max
+	m.mu.Unlock()
+
+	log.Printf(consts.LOG_CONCURRENCY_CHANGED, max)
+	m.dispatch()
+	return nil
+}
+
+func (m *Manager) GetQueue() models.QueueStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := models.QueueStatus{
+		Running:       m.running,
+		MaxConcurrent: m.maxConcurrent,
+		Queued:        []models.QueuedJob{},
+	}
+	for i, download := range m.queue {
+		status.Queued = append(status.Queued, models.QueuedJob{
+			ID:       download.ID,
+			Type:     download.Type,
+			URL:      download.URL,
+			Title:    download.Title,
+			Priority: download.Priority,
+			Position: i + 1,
+		})
+	}
+	return status
+}
+
+// MoveQueuedJob moves a job to a 1-based queue position. The job takes over
+// the priority of the job it displaces so later insertions keep the order.
+func (m *Manager) MoveQueuedJob(id string, position int) error {
+	m.mu.Lock()
+	index := m.queueIndex(id)
+	if index < 0 {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_NOT_QUEUED, id)
+	}
+	if position < 1 || position > len(m.queue) {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_INVALID_QUEUE_POSITION, position, len(m.queue))
+	}
+
+	download := m.queue[index]
+	m.queue = append(m.queue[:index], m.queue[index+1:]...)
+	target := position - 1
+	if target < len(m.queue) {
+		download.Priority = m.queue[target].Priority
+	} else if target > 0 {
+		download.Priority = m.queue[target-1].Priority
+	}
+	m.queue = append(m.queue[:target], append([]*Download{download}, m.queue[target:]...)...)
+	m.mu.Unlock()
+
+	m.broadcastQueuePositions()
+	return nil
+}
+
+func (m *Manager) SetJobPriority(id string, priority int) error {
+	m.mu.Lock()
+	index := m.queueIndex(id)
+	if index < 0 {
+		m.mu.Unlock()
+		return fmt.Errorf(consts.ERR_JOB_NOT_QUEUED, id)
+	}
+
+	download := m.queue[index]
+	m.queue = append(m.queue[:index], m.queue[index+1:]...)
+	download.Priority = priority
+	m.queue = insertByPriority(m.queue, download)
+	m.mu.Unlock()
+
+	m.broadcastQueuePositions()
+	return nil
+}
+
+func (m *Manager) RemoveQueuedJob(id string) error {
+	m.mu.Lock()
+	removed := m.removeFromQueue(id)
+	if removed {
+		m.downloads[id].cancelled = true
+	}
+	m.mu.Unlock()
+
+	if !removed {
+		return fmt.Errorf(consts.ERR_JOB_NOT_QUEUED, id)
+	}
+
+	log.Printf(consts.LOG_JOB_REMOVED_FROM_QUEUE, id)
+	m.updateStatus(id, consts.STATUS_CANCELLED, 0, "", "", consts.MSG_JOB_CANCELLED)
+	m.broadcastQueuePositions()
+	return nil
+}
+
+// removeFromQueue must be called with m.mu held.
+func (m *Manager) removeFromQueue(id string) bool {
+	index := m.queueIndex(id)
+	if index < 0 {
+		return false
+	}
+	m.queue = append(m.queue[:index], m.queue[index+1:]...)
+	return true
+}
+
+// queueIndex must be called with m.mu held.
+func (m *Manager) queueIndex(id string) int {
+	for i, download := range m.queue {
+		if download.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Manager) broadcastQueuePositions() {
+	m.mu.RLock()
+	updates := make([]models.ProgressUpdate, 0, len(m.queue))
+	for i, download := range m.queue {
+		updates = append(updates, models.ProgressUpdate{
+			ID:       download.ID,
+			ParentID: download.ParentID,
+			Status:   consts.STATUS_QUEUED,
+			Position: i + 1,
+			Message:  fmt.Sprintf(consts.MSG_QUEUED_POSITION, i+1, len(m.queue)),
+		})
+	}
+	m.mu.RUnlock()
+
+	for _, update := range updates {
+		m.broadcast(update)
+	}
+}
+
+func insertByPriority(queue []*Download, download *Download) []*Download {
+	index := len(queue)
+	for i, queued := range queue {
+		if download.Priority > queued.Priority {
+			index = i
+			break
+		}
+	}
+	return append(queue[:index], append([]*Download{download}, queue[index:]...)...)
+}
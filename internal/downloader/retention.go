@@ -0,0 +1,108 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Files saved through the browser save target stay in their job workspace
+// until the browser fetches them or the retention period runs out.
+
+func jobFileURL(id string) string {
+	return fmt.Sprintf(consts.JOB_FILE_URL_FORMAT, id)
+}
+
+// JobFileURL returns the URL a job's file will be served from, or an empty
+// string when the active save target does not hand files to the browser.
+func (m *Manager) JobFileURL(id string) string {
+	if _, ok := m.saveTarget.(*browserSaveTarget); !ok {
+		return ""
+	}
+	return jobFileURL(id)
+}
+
+// OpenJobFile returns the retained file of a finished job and counts the
+// caller as one of its readers. The caller must call CloseJobFile when it
+// is done reading.
+func (m *Manager) OpenJobFile(id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	download, ok := m.downloads[id]
+	if !ok {
+		return "", fmt.Errorf(consts.ERR_JOB_NOT_FOUND, id)
+	}
+	if download.retainedFile == "" {
+		return "", fmt.Errorf(consts.ERR_JOB_FILE_UNAVAILABLE, id)
+	}
+	download.fileReaders++
+	return download.retainedFile, nil
+}
+
+// CloseJobFile ends a read started by OpenJobFile. delivered reports that
+// the read sent the whole file, after which the file is released.
+func (m *Manager) CloseJobFile(id string, delivered bool) {
+	m.mu.Lock()
+	pending := false
+	if download, ok := m.downloads[id]; ok {
+		download.fileReaders--
+		pending = download.releasePending
+	}
+	m.mu.Unlock()
+
+	if delivered || pending {
+		m.ReleaseJobFile(id)
+	}
+}
+
+// ReleaseJobFile removes a retained file with its workspace, or marks it to
+// be removed once the requests still reading it are done.
+func (m *Manager) ReleaseJobFile(id string) {
+	m.mu.Lock()
+	download, ok := m.downloads[id]
+	if ok && download.fileReaders > 0 {
+		download.releasePending = true
+		m.mu.Unlock()
+		return
+	}
+	if ok {
+		download.retainedFile = ""
+		download.releasePending = false
+	}
+	m.mu.Unlock()
+
+	m.removeWorkspace(id)
+}
+
+func (m *Manager) runFileJanitor() {
+	ticker := time.NewTicker(consts.FILE_JANITOR_INTERVAL_SECONDS * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopJanitor:
+			return
+		case now := <-ticker.C:
+			m.expireRetainedFiles(now)
+			m.expireInterrupted(now)
+		}
+	}
+}
+
+func (m *Manager) expireRetainedFiles(now time.Time) {
+	m.mu.RLock()
+	var expired []string
+	for id, download := range m.downloads {
+		if download.retainedFile != "" && now.After(download.retainedUntil) {
+			expired = append(expired, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, id := range expired {
+		log.Printf(consts.LOG_JOB_FILE_EXPIRED, id)
+		m.ReleaseJobFile(id)
+	}
+}
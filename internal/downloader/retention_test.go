@@ -0,0 +1,80 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/history"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJobFileReaders(t *testing.T) {
+	tests := []struct {
+		name string
+		// release runs with a full read and a range read open; true closes
+		// the full read as delivered, false stands for the janitor.
+		delivered bool
+	}{
+		{name: "delivered while a range read is open", delivered: true},
+		{name: "expired while reads are open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, workspace := newRetainedJob(t)
+
+			for i := 0; i < 2; i++ {
+				if _, err := m.OpenJobFile("job"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.delivered {
+				m.CloseJobFile("job", true)
+			} else {
+				m.ReleaseJobFile("job")
+				m.CloseJobFile("job", false)
+			}
+
+			if _, err := os.Stat(workspace); err != nil {
+				t.Fatalf("workspace was removed while a read was open: %v", err)
+			}
+
+			m.CloseJobFile("job", false)
+			if _, err := os.Stat(workspace); !os.IsNotExist(err) {
+				t.Errorf("workspace was kept after the last read: %v", err)
+			}
+			if _, err := m.OpenJobFile("job"); err == nil {
+				t.Error("OpenJobFile() of a released file succeeded")
+			}
+		})
+	}
+}
+
+func TestJobFileRangeReadKeepsFile(t *testing.T) {
+	m, _ := newRetainedJob(t)
+	if _, err := m.OpenJobFile("job"); err != nil {
+		t.Fatal(err)
+	}
+	m.CloseJobFile("job", false)
+
+	if _, err := m.OpenJobFile("job"); err != nil {
+		t.Errorf("file was released after a partial read: %v", err)
+	}
+}
+
+// newRetainedJob returns a manager holding a finished job "job" whose file
+// waits in its workspace for the browser.
+func newRetainedJob(t *testing.T) (*Manager, string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Paths.TempDir = t.TempDir()
+	m := &Manager{downloads: make(map[string]*Download), history: history.NewMemoryStore(), cfg: cfg}
+
+	writeWorkspaceFile(t, cfg, "job", 10)
+	download := newDownload("job", consts.JOB_TYPE_VIDEO, "https://example.com/v", "")
+	download.Workspace = filepath.Join(cfg.Paths.TempDir, "job")
+	download.retainedFile = filepath.Join(download.Workspace, "job.part")
+	m.registerJob(download)
+	return m, download.Workspace
+}
@@ -0,0 +1,198 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// retryPolicy is how often and how patiently one job is retried.
+type retryPolicy struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	strategies   []string
+}
+
+func newRetryPolicy(cfg config.RetryConfig, maxAttempts int) retryPolicy {
+	policy := retryPolicy{
+		maxAttempts:  cfg.MaxAttempts,
+		initialDelay: time.Duration(cfg.InitialDelay),
+		maxDelay:     time.Duration(cfg.MaxDelay),
+		strategies:   cfg.Strategies,
+	}
+	if maxAttempts > 0 {
+		policy.maxAttempts = maxAttempts
+	}
+	return policy
+}
+
+func validateMaxAttempts(maxAttempts int) error {
+	if maxAttempts < 0 || maxAttempts > consts.MAX_ATTEMPTS_LIMIT {
+		return fmt.Errorf(consts.ERR_INVALID_ATTEMPTS, maxAttempts, consts.MAX_ATTEMPTS_LIMIT)
+	}
+	return nil
+}
+
+// delay is the wait after a failed attempt: the initial delay doubled for
+// every attempt before it, capped at the maximum, less a random part of up
+// to half so that jobs failing together do not all retry together. random
+// returns a number in [0, 1).
+func (p retryPolicy) delay(attempt int, random func() float64) time.Duration {
+	delay := p.initialDelay
+	for i := 1; i < attempt && delay < p.maxDelay; i++ {
+		delay *= consts.RETRY_BACKOFF_FACTOR
+	}
+	if delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+	return delay - time.Duration(random()*float64(delay/2))
+}
+
+// strategiesFor returns the strategies of an attempt: none for the first,
+// then one more for every attempt after it.
+func (p retryPolicy) strategiesFor(attempt int) []string {
+	count := attempt - 1
+	if count > len(p.strategies) {
+		count = len(p.strategies)
+	}
+	return p.strategies[:count]
+}
+
+// attemptConfig returns cfg with the yt-dlp options of strategies added to
+// every job's arguments, less the options they replace. cfg itself is shared
+// and left alone.
+func attemptConfig(cfg *config.Config, strategies []string) *config.Config {
+	if len(strategies) == 0 {
+		return cfg
+	}
+	var extra []string
+	removed := make(map[string]bool)
+	for _, strategy := range strategies {
+		extra = append(extra, consts.RETRY_STRATEGY_ARGS[strategy]...)
+		for _, arg := range consts.RETRY_STRATEGY_REMOVED_ARGS[strategy] {
+			removed[arg] = true
+		}
+	}
+
+	attempt := *cfg
+	attempt.YtDlp.DownloadArgs = strategyArgs(cfg.YtDlp.DownloadArgs, removed, extra)
+	attempt.YtDlp.AudioArgs = strategyArgs(cfg.YtDlp.AudioArgs, removed, extra)
+	attempt.YtDlp.SubtitleArgs = strategyArgs(cfg.YtDlp.SubtitleArgs, removed, extra)
+	return &attempt
+}
+
+// strategyArgs returns a copy of args without the removed flags, followed
+// by extra. Only flags without a value are ever removed.
+func strategyArgs(args []string, removed map[string]bool, extra []string) []string {
+	result := make([]string, 0, len(args)+len(extra))
+	for _, arg := range args {
+		if !removed[arg] {
+			result = append(result, arg)
+		}
+	}
+	return append(result, extra...)
+}
+
+// executeWithRetries runs execute until it succeeds, fails for a reason a
+// retry cannot fix, runs out of attempts or the job is cancelled. Between
+// attempts the job is "retrying" and counts down to the next one, which
+// reuses the workspace so yt-dlp can resume partial downloads. status is
+// the job's status while an attempt runs.
+func (m *Manager) executeWithRetries(id, status string, req models.DownloadRequest, execute func(cfg *config.Config) (*YtDlpResult, error)) (*YtDlpResult, error) {
+	policy := newRetryPolicy(m.cfg.Downloads.Retry, req.MaxAttempts)
+
+	for attempt := 1; ; attempt++ {
+		m.startAttempt(id, attempt, policy.maxAttempts)
+		strategies := policy.strategiesFor(attempt)
+		result, err := execute(attemptConfig(m.cfg, strategies))
+		m.detachProcess(id)
+
+		if err == nil || m.isCancelled(id) {
+			return result, err
+		}
+		jobErr := ClassifyError(err)
+		if !jobErr.Retryable || attempt >= policy.maxAttempts {
+			return nil, jobErr
+		}
+
+		delay := policy.delay(attempt, rand.Float64)
+		log.Printf(consts.LOG_ATTEMPT_FAILED, attempt, policy.maxAttempts, id, jobErr.Code, delay)
+		m.recordAttempt(id, attempt, strategies, jobErr)
+		if !m.waitToRetry(id, attempt, policy.maxAttempts, jobErr, delay) {
+			return nil, nil
+		}
+		m.publishUpdate(models.ProgressUpdate{
+			ID:          id,
+			Status:      status,
+			Message:     fmt.Sprintf(consts.MSG_STARTING_ATTEMPT, attempt+1, policy.maxAttempts),
+			Attempt:     attempt + 1,
+			MaxAttempts: policy.maxAttempts,
+		})
+	}
+}
+
+func (m *Manager) startAttempt(id string, attempt, maxAttempts int) {
+	m.mu.Lock()
+	if download, ok := m.downloads[id]; ok {
+		download.Attempt = attempt
+		download.maxAttempts = maxAttempts
+	}
+	m.mu.Unlock()
+}
+
+func (m *Manager) recordAttempt(id string, attempt int, strategies []string, jobErr *JobError) {
+	m.mu.Lock()
+	if download, ok := m.downloads[id]; ok {
+		download.Attempts = append(download.Attempts, models.JobAttempt{
+			Attempt:    attempt,
+			Code:       jobErr.Code,
+			Error:      jobErr.Message,
+			Strategies: strategies,
+			FailedAt:   time.Now(),
+		})
+	}
+	m.mu.Unlock()
+}
+
+// waitToRetry publishes the countdown to the next attempt once a second.
+// It returns false when the job was cancelled in the meantime.
+func (m *Manager) waitToRetry(id string, attempt, maxAttempts int, jobErr *JobError, delay time.Duration) bool {
+	m.mu.RLock()
+	progress := 0.0
+	if download, ok := m.downloads[id]; ok {
+		progress = download.Progress
+	}
+	m.mu.RUnlock()
+
+	deadline := time.Now().Add(delay)
+	for {
+		if m.isCancelled(id) {
+			return false
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return true
+		}
+
+		seconds := int(math.Ceil(remaining.Seconds()))
+		m.publishUpdate(models.ProgressUpdate{
+			ID:          id,
+			Progress:    progress,
+			Status:      consts.STATUS_RETRYING,
+			Message:     fmt.Sprintf(consts.MSG_RETRYING_IN, attempt, maxAttempts, jobErr.Message, seconds),
+			Code:        jobErr.Code,
+			Retryable:   jobErr.Retryable,
+			Hint:        jobErr.Hint,
+			Attempt:     attempt,
+			MaxAttempts: maxAttempts,
+			RetryIn:     seconds,
+		})
+		time.Sleep(remaining - time.Duration(seconds-1)*time.Second)
+	}
+}
@@ -0,0 +1,256 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/history"
+	"Go-Utilities/internal/models"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{initialDelay: 5 * time.Second, maxDelay: time.Minute}
+
+	tests := []struct {
+		attempt int
+		random  float64
+		want    time.Duration
+	}{
+		{attempt: 1, random: 0, want: 5 * time.Second},
+		{attempt: 2, random: 0, want: 10 * time.Second},
+		{attempt: 3, random: 0, want: 20 * time.Second},
+		{attempt: 4, random: 0, want: 40 * time.Second},
+		{attempt: 5, random: 0, want: time.Minute},
+		{attempt: 9, random: 0, want: time.Minute},
+		{attempt: 1, random: 0.5, want: 3750 * time.Millisecond},
+		{attempt: 5, random: 0.999, want: 30030 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		got := policy.delay(tt.attempt, func() float64 { return tt.random })
+		if got != tt.want {
+			t.Errorf("delay(%d, %v) = %v, want %v", tt.attempt, tt.random, got, tt.want)
+		}
+	}
+}
+
+func TestAttemptConfig(t *testing.T) {
+	cfg := config.Default()
+	policy := newRetryPolicy(cfg.Downloads.Retry, 5)
+
+	playerClient := []string{consts.EXTRACTOR_ARGS_FLAG, consts.RETRY_PLAYER_CLIENTS}
+	lowerFormat := []string{consts.FORMAT_SORT_FLAG, consts.RETRY_LOWER_FORMAT_SORT}
+	tests := []struct {
+		removed []string
+		extra   []string
+	}{
+		{},
+		{extra: playerClient},
+		{extra: append(append([]string{}, playerClient...), lowerFormat...)},
+		{removed: []string{consts.FORCE_IPV4_FLAG}, extra: append(append(append([]string{}, playerClient...), lowerFormat...), consts.FORCE_IPV6_FLAG)},
+		{removed: []string{consts.FORCE_IPV4_FLAG}, extra: append(append(append([]string{}, playerClient...), lowerFormat...), consts.FORCE_IPV6_FLAG)},
+	}
+	for i, tt := range tests {
+		attempt := attemptConfig(cfg, policy.strategiesFor(i+1))
+		checks := map[string][2][]string{
+			"download": {cfg.YtDlp.DownloadArgs, attempt.YtDlp.DownloadArgs},
+			"audio":    {cfg.YtDlp.AudioArgs, attempt.YtDlp.AudioArgs},
+			"subtitle": {cfg.YtDlp.SubtitleArgs, attempt.YtDlp.SubtitleArgs},
+		}
+		for name, args := range checks {
+			base, got := args[0], args[1]
+			var want []string
+			for _, arg := range base {
+				if !containsString(tt.removed, arg) {
+					want = append(want, arg)
+				}
+			}
+			want = append(want, tt.extra...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("attempt %d %s args = %q, want %q", i+1, name, got, want)
+			}
+		}
+	}
+	if !reflect.DeepEqual(cfg.YtDlp.DownloadArgs, consts.YT_DLP_DOWNLOAD_ARGS) {
+		t.Errorf("attemptConfig() changed the shared config: %q", cfg.YtDlp.DownloadArgs)
+	}
+}
+
+// Every default attempt must run yt-dlp differently from the ones before it,
+// or a retry only repeats a failure. The args are compared as sets, since
+// repeating an option yt-dlp already has changes nothing.
+func TestDefaultRetryStrategiesChangeEveryAttempt(t *testing.T) {
+	cfg := config.Default()
+	policy := newRetryPolicy(cfg.Downloads.Retry, len(cfg.Downloads.Retry.Strategies)+1)
+
+	seen := make(map[string]int)
+	for attempt := 1; attempt <= policy.maxAttempts; attempt++ {
+		args := attemptConfig(cfg, policy.strategiesFor(attempt))
+		for name, list := range map[string][]string{
+			"download": args.YtDlp.DownloadArgs,
+			"audio":    args.YtDlp.AudioArgs,
+			"subtitle": args.YtDlp.SubtitleArgs,
+		} {
+			options := make(map[string]bool)
+			for _, arg := range list {
+				options[arg] = true
+			}
+			var unique []string
+			for arg := range options {
+				unique = append(unique, arg)
+			}
+			sort.Strings(unique)
+			key := name + "\x00" + strings.Join(unique, "\x00")
+			if previous, ok := seen[key]; ok {
+				t.Errorf("attempt %d runs with the same %s args as attempt %d: %q", attempt, name, previous, list)
+			}
+			seen[key] = attempt
+		}
+	}
+}
+
+func TestExecuteWithRetries(t *testing.T) {
+	forbidden := &ProcessError{ExitCode: 1, Stderr: "ERROR: unable to download video data: HTTP Error 403: Forbidden"}
+	private := &ProcessError{ExitCode: 1, Stderr: "ERROR: [youtube] abc: Private video"}
+	success := &YtDlpResult{Title: "Title", Success: true}
+
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		outcomes     []error
+		wantAttempts int
+		wantCode     string
+		wantRetrying int
+	}{
+		{
+			name:         "succeeds first time",
+			outcomes:     []error{nil},
+			wantAttempts: 1,
+		},
+		{
+			name:         "succeeds after transient failures",
+			outcomes:     []error{forbidden, forbidden, nil},
+			wantAttempts: 3,
+			wantRetrying: 2,
+		},
+		{
+			name:         "runs out of attempts",
+			outcomes:     []error{forbidden, forbidden, forbidden},
+			wantAttempts: 3,
+			wantCode:     consts.ERROR_CODE_FORBIDDEN,
+			wantRetrying: 2,
+		},
+		{
+			name:         "job override",
+			maxAttempts:  1,
+			outcomes:     []error{forbidden},
+			wantAttempts: 1,
+			wantCode:     consts.ERROR_CODE_FORBIDDEN,
+		},
+		{
+			name:         "not retryable",
+			outcomes:     []error{private},
+			wantAttempts: 1,
+			wantCode:     consts.ERROR_CODE_VIDEO_PRIVATE,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Downloads.Retry.InitialDelay = config.Duration(time.Millisecond)
+			cfg.Downloads.Retry.MaxDelay = config.Duration(time.Millisecond)
+			m := &Manager{downloads: make(map[string]*Download), history: history.NewMemoryStore(), cfg: cfg}
+			m.registerDownload("job", consts.JOB_TYPE_VIDEO, "", models.DownloadRequest{URL: "https://example.com/v"})
+			subscription := m.Subscribe()
+
+			var configs []*config.Config
+			result, err := m.executeWithRetries("job", consts.STATUS_DOWNLOADING, models.DownloadRequest{MaxAttempts: tt.maxAttempts}, func(cfg *config.Config) (*YtDlpResult, error) {
+				configs = append(configs, cfg)
+				if outcome := tt.outcomes[len(configs)-1]; outcome != nil {
+					return nil, outcome
+				}
+				return success, nil
+			})
+
+			if len(configs) != tt.wantAttempts {
+				t.Fatalf("ran %d attempts, want %d", len(configs), tt.wantAttempts)
+			}
+			if tt.wantCode == "" {
+				if err != nil || result != success {
+					t.Fatalf("executeWithRetries() = %v, %v, want the result", result, err)
+				}
+			} else {
+				var jobErr *JobError
+				if !errors.As(err, &jobErr) || jobErr.Code != tt.wantCode {
+					t.Fatalf("executeWithRetries() error = %v, want code %s", err, tt.wantCode)
+				}
+			}
+
+			job, _ := m.GetJob("job")
+			if len(job.Attempts) != tt.wantRetrying {
+				t.Errorf("recorded %d attempts, want %d", len(job.Attempts), tt.wantRetrying)
+			}
+			for i, attempt := range job.Attempts {
+				if attempt.Attempt != i+1 || attempt.Code != consts.ERROR_CODE_FORBIDDEN {
+					t.Errorf("attempt %d = %+v", i+1, attempt)
+				}
+			}
+
+			retrying := 0
+			for _, update := range subscription.Take() {
+				if update.Status == consts.STATUS_RETRYING {
+					retrying++
+					if update.RetryIn != 1 || update.Code != consts.ERROR_CODE_FORBIDDEN || !update.Retryable {
+						t.Errorf("retrying update = %+v", update)
+					}
+				}
+			}
+			if retrying != tt.wantRetrying {
+				t.Errorf("published %d retrying updates, want %d", retrying, tt.wantRetrying)
+			}
+		})
+	}
+}
+
+func TestExecuteWithRetriesCancelled(t *testing.T) {
+	cfg := config.Default()
+	cfg.Downloads.Retry.InitialDelay = config.Duration(time.Minute)
+	m := &Manager{downloads: make(map[string]*Download), history: history.NewMemoryStore(), cfg: cfg}
+	m.registerDownload("job", consts.JOB_TYPE_VIDEO, "", models.DownloadRequest{URL: "https://example.com/v"})
+
+	subscription := m.Subscribe()
+	attempts := 0
+	done := make(chan error)
+	go func() {
+		_, err := m.executeWithRetries("job", consts.STATUS_DOWNLOADING, models.DownloadRequest{}, func(*config.Config) (*YtDlpResult, error) {
+			attempts++
+			return nil, &ProcessError{ExitCode: 1, Stderr: "ERROR: HTTP Error 429: Too Many Requests"}
+		})
+		done <- err
+	}()
+
+	for retrying := false; !retrying; {
+		<-subscription.Ready()
+		for _, update := range subscription.Take() {
+			retrying = retrying || update.Status == consts.STATUS_RETRYING
+		}
+	}
+	if err := m.CancelDownload("job"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil || attempts != 1 {
+			t.Errorf("cancelled wait returned %v after %d attempts", err, attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the wait for the next attempt was not cancelled")
+	}
+}
@@ -0,0 +1,252 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// SaveTarget decides where a finished file ends up once yt-dlp is done with
+// it. Implementations are picked at runtime by NewSaveTarget.
+type SaveTarget interface {
+	Name() string
+	Save(sourceFile string) (SaveResult, error)
+}
+
+type SaveResult struct {
+	// Path is the final location of the file. For deferred saves it is the
+	// file kept in the job workspace.
+	Path string
+	// Deferred means the file stays in the workspace until a browser
+	// fetches it through the job file endpoint.
+	Deferred bool
+	// Reveal asks the manager to open the containing folder locally.
+	Reveal bool
+}
+
+var errSaveCancelled = errors.New(consts.ERR_SAVE_CANCELLED)
+
+// NewSaveTarget returns the named save target. "auto" chooses a native save
+// dialog for the current OS and falls back to outputDir when none is found.
+func NewSaveTarget(name, outputDir string) (SaveTarget, error) {
+	switch name {
+	case consts.SAVE_TARGET_AUTO, "":
+		return autoSaveTarget(outputDir), nil
+	case consts.SAVE_TARGET_DIRECTORY:
+		return &directorySaveTarget{dir: outputDir}, nil
+	case consts.SAVE_TARGET_BROWSER:
+		return &browserSaveTarget{}, nil
+	case consts.SAVE_TARGET_POWERSHELL:
+		return newDialogSaveTarget(name, consts.POWERSHELL_COMMAND, powershellDialogArgs, 0), nil
+	case consts.SAVE_TARGET_ZENITY:
+		return newDialogSaveTarget(name, consts.ZENITY_COMMAND, zenityDialogArgs, consts.DIALOG_CANCEL_EXIT_CODE), nil
+	case consts.SAVE_TARGET_KDIALOG:
+		return newDialogSaveTarget(name, consts.KDIALOG_COMMAND, kdialogDialogArgs, consts.DIALOG_CANCEL_EXIT_CODE), nil
+	case consts.SAVE_TARGET_OSASCRIPT:
+		return newDialogSaveTarget(name, consts.OSASCRIPT_COMMAND, osascriptDialogArgs, consts.DIALOG_CANCEL_EXIT_CODE), nil
+	}
+	return nil, fmt.Errorf(consts.ERR_UNKNOWN_SAVE_TARGET, name)
+}
+
+func autoSaveTarget(outputDir string) SaveTarget {
+	var candidates []string
+	switch runtime.GOOS {
+	case consts.WINDOWS_OS:
+		candidates = []string{consts.SAVE_TARGET_POWERSHELL}
+	case consts.DARWIN_OS:
+		candidates = []string{consts.SAVE_TARGET_OSASCRIPT}
+	default:
+		candidates = []string{consts.SAVE_TARGET_ZENITY, consts.SAVE_TARGET_KDIALOG}
+	}
+
+	for _, name := range candidates {
+		target, _ := NewSaveTarget(name, outputDir)
+		if dialog, ok := target.(*dialogSaveTarget); ok && dialog.available() {
+			log.Printf(consts.LOG_SAVE_TARGET_SELECTED, name, runtime.GOOS)
+			return target
+		}
+	}
+
+	log.Printf(consts.LOG_SAVE_TARGET_SELECTED, consts.SAVE_TARGET_DIRECTORY, runtime.GOOS)
+	return &directorySaveTarget{dir: outputDir}
+}
+
+// directorySaveTarget moves files into a fixed directory without any user
+// interaction, which is what headless installs need.
+type directorySaveTarget struct {
+	dir string
+}
+
+func (t *directorySaveTarget) Name() string {
+	return consts.SAVE_TARGET_DIRECTORY
+}
+
+func (t *directorySaveTarget) Save(sourceFile string) (SaveResult, error) {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return SaveResult{}, fmt.Errorf(consts.ERR_SAVE_FILE_PICKER, err)
+	}
+
+	destination := uniquePath(filepath.Join(t.dir, filepath.Base(sourceFile)))
+	if err := moveFile(sourceFile, destination); err != nil {
+		return SaveResult{}, fmt.Errorf(consts.ERR_SAVE_FILE_PICKER, err)
+	}
+	return SaveResult{Path: destination}, nil
+}
+
+// browserSaveTarget leaves the file in the job workspace; the browser pulls
+// it from the server afterwards.
+type browserSaveTarget struct{}
+
+func (t *browserSaveTarget) Name() string {
+	return consts.SAVE_TARGET_BROWSER
+}
+
+func (t *browserSaveTarget) Save(sourceFile string) (SaveResult, error) {
+	return SaveResult{Path: sourceFile, Deferred: true}, nil
+}
+
+// dialogSaveTarget asks the desktop user for a destination through a native
+// save dialog driven by an external command that prints the chosen path.
+type dialogSaveTarget struct {
+	name    string
+	command string
+	args    func(filename string) []string
+	// cancelExitCode is the exit code the dialog uses when it is dismissed,
+	// or 0 if it reports cancellation on stdout instead.
+	cancelExitCode int
+}
+
+func newDialogSaveTarget(name, command string, args func(filename string) []string, cancelExitCode int) *dialogSaveTarget {
+	return &dialogSaveTarget{name: name, command: command, args: args, cancelExitCode: cancelExitCode}
+}
+
+func (t *dialogSaveTarget) Name() string {
+	return t.name
+}
+
+func (t *dialogSaveTarget) available() bool {
+	_, err := exec.LookPath(t.command)
+	return err == nil
+}
+
+func (t *dialogSaveTarget) Save(sourceFile string) (SaveResult, error) {
+	output, err := exec.Command(t.command, t.args(filepath.Base(sourceFile))...).Output()
+	selectedPath := strings.TrimSpace(string(output))
+
+	cancelled := selectedPath == consts.CANCELLED_MESSAGE || (err == nil && selectedPath == "")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && t.cancelExitCode != 0 && exitErr.ExitCode() == t.cancelExitCode {
+		cancelled = true
+	}
+	if cancelled {
+		os.Remove(sourceFile)
+		return SaveResult{}, errSaveCancelled
+	}
+	if err != nil {
+		log.Printf(consts.LOG_SAVE_DIALOG_FAILED, t.name, err)
+		return SaveResult{}, fmt.Errorf(consts.ERR_SAVE_FILE_PICKER, err)
+	}
+
+	if err := moveFile(sourceFile, selectedPath); err != nil {
+		return SaveResult{}, fmt.Errorf(consts.ERR_SAVE_FILE_PICKER, err)
+	}
+	return SaveResult{Path: selectedPath, Reveal: true}, nil
+}
+
+func powershellDialogArgs(filename string) []string {
+	script := fmt.Sprintf(consts.POWERSHELL_FILE_PICKER_SCRIPT,
+		consts.FILE_PICKER_FILTER,
+		filename,
+		consts.SAVE_DIALOG_TITLE,
+		filepath.Ext(filename),
+		consts.CANCELLED_MESSAGE)
+	return []string{consts.COMMAND_FLAG, script}
+}
+
+func zenityDialogArgs(filename string) []string {
+	args := append([]string{}, consts.ZENITY_SAVE_DIALOG_ARGS...)
+	return append(args, consts.ZENITY_FILENAME_FLAG+filename)
+}
+
+func kdialogDialogArgs(filename string) []string {
+	args := append([]string{}, consts.KDIALOG_SAVE_DIALOG_ARGS...)
+	return append(args, filename)
+}
+
+func osascriptDialogArgs(filename string) []string {
+	script := fmt.Sprintf(consts.OSASCRIPT_SAVE_DIALOG_SCRIPT, consts.SAVE_DIALOG_TITLE, escapeAppleScript(filename))
+	return []string{consts.OSASCRIPT_SCRIPT_FLAG, script}
+}
+
+func escapeAppleScript(value string) string {
+	return strings.NewReplacer(consts.APPLESCRIPT_ESCAPES...).Replace(value)
+}
+
+// uniquePath appends " (n)" before the extension until the name is free.
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf(consts.DUPLICATE_FILE_FORMAT, base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			log.Printf(consts.DUPLICATE_MSG, filepath.Base(candidate))
+			return candidate
+		}
+	}
+}
+
+// moveFile renames when possible and copies across filesystems otherwise.
+func moveFile(source, dest string) error {
+	if err := os.Rename(source, dest); err == nil {
+		return nil
+	}
+
+	if err := copyFile(source, dest); err != nil {
+		return err
+	}
+	return os.Remove(source)
+}
+
+func copyFile(source, dest string) error {
+	sourceFile, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = destFile.ReadFrom(sourceFile)
+	return err
+}
+
+func openFileExplorer(path string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case consts.WINDOWS_OS:
+		cmd = exec.Command(consts.EXPLORER_COMMAND, path)
+	case consts.DARWIN_OS:
+		cmd = exec.Command(consts.OPEN_COMMAND, path)
+	default:
+		cmd = exec.Command(consts.XDG_OPEN_COMMAND, path)
+	}
+	if err := cmd.Start(); err != nil {
+		log.Printf(consts.LOG_OPEN_FILE_EXPLORER_FAILED, err)
+		return
+	}
+	go cmd.Wait()
+}
@@ -0,0 +1,128 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"archive/zip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// saveFiles saves a job's main file through its save target and moves the
+// sidecars next to wherever it ended up, renamed to match. The browser
+// fetches a single file per job, so for it everything is bundled into a
+// zip archive named bundleName first.
+func (m *Manager) saveFiles(id string, result *YtDlpResult, bundleName string) (SaveResult, error) {
+	target := m.saveTargetFor(id)
+	if len(result.Sidecars) == 0 {
+		return target.Save(result.FilePath)
+	}
+
+	if _, ok := target.(*browserSaveTarget); ok {
+		bundle := filepath.Join(filepath.Dir(result.FilePath), bundleName+consts.BUNDLE_EXT)
+		if err := bundleFiles(bundle, append([]string{result.FilePath}, result.Sidecars...)); err != nil {
+			return SaveResult{}, fmt.Errorf(consts.ERR_BUNDLE_FILES, err)
+		}
+		return target.Save(bundle)
+	}
+
+	saved, err := target.Save(result.FilePath)
+	if err != nil {
+		return saved, err
+	}
+
+	dir, stem := filepath.Dir(saved.Path), fileStem(filepath.Base(saved.Path))
+	for _, sidecar := range result.Sidecars {
+		destination := uniquePath(filepath.Join(dir, sidecarName(result.FilePath, sidecar, stem)))
+		if err := moveFile(sidecar, destination); err != nil {
+			log.Printf(consts.WARNING_SIDECAR_NOT_SAVED, filepath.Base(sidecar), err)
+		}
+	}
+	return saved, nil
+}
+
+// renameSidecars follows a rename of the main file, so "Title.en.srt"
+// stays paired with "Title [1080p].mp4".
+func renameSidecars(oldMain, newMain string, sidecars []string) []string {
+	stem := fileStem(filepath.Base(newMain))
+	renamed := make([]string, 0, len(sidecars))
+	for _, sidecar := range sidecars {
+		target := filepath.Join(filepath.Dir(sidecar), sidecarName(oldMain, sidecar, stem))
+		if err := os.Rename(sidecar, target); err != nil {
+			log.Printf(consts.ERR_RENAME_FILE, err)
+			target = sidecar
+		}
+		renamed = append(renamed, target)
+	}
+	return renamed
+}
+
+// sidecarName swaps the main file's name in a sidecar's name for stem,
+// keeping suffixes such as ".en.srt". Sidecars named differently keep
+// their name.
+func sidecarName(mainFile, sidecar, stem string) string {
+	name := filepath.Base(sidecar)
+	prefix := fileStem(filepath.Base(mainFile)) + "."
+	if !strings.HasPrefix(name, prefix) {
+		return name
+	}
+	return stem + "." + strings.TrimPrefix(name, prefix)
+}
+
+// bundleFiles writes files into a zip archive at path and removes them.
+// Media is stored as is since it is already compressed.
+func bundleFiles(path string, files []string) error {
+	archive, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	writer := zip.NewWriter(archive)
+	for _, file := range files {
+		if err := addToZip(writer, file); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		os.Remove(file)
+	}
+	return nil
+}
+
+func addToZip(writer *zip.Writer, file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Method = zip.Store
+	if isSubtitleFile(file) {
+		header.Method = zip.Deflate
+	}
+
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	source, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	_, err = io.Copy(entry, source)
+	return err
+}
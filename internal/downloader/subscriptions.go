@@ -0,0 +1,160 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"log"
+	"sort"
+	"sync"
+)
+
+// Subscription delivers job updates to one client. Once
+// consts.SUBSCRIBER_BUFFER_SIZE updates are waiting to be taken, a new
+// update replaces the one waiting for the same job, so a client that falls
+// behind skips steps but still sees the latest state of every job.
+type Subscription struct {
+	manager *Manager
+	ready   chan struct{}
+	mu      sync.Mutex
+	pending []models.ProgressUpdate
+	// jobs holds the followed jobs; nil follows every job.
+	jobs map[string]bool
+}
+
+// Subscribe starts delivering the updates of the given jobs, or of every job
+// when no IDs are given. The caller must Close the subscription when done.
+func (m *Manager) Subscribe(ids ...string) *Subscription {
+	sub := &Subscription{manager: m, ready: make(chan struct{}, 1)}
+	sub.Follow(ids)
+
+	m.mu.Lock()
+	if m.subscribers == nil {
+		m.subscribers = make(map[*Subscription]struct{})
+	}
+	m.subscribers[sub] = struct{}{}
+	m.mu.Unlock()
+	return sub
+}
+
+// Follow replaces the followed jobs; a playlist brings its items along.
+// Updates waiting for jobs no longer followed are dropped.
+func (s *Subscription) Follow(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = nil
+	if len(ids) > 0 {
+		s.jobs = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			s.jobs[id] = true
+		}
+	}
+
+	kept := s.pending[:0]
+	for _, update := range s.pending {
+		if s.follows(update) {
+			kept = append(kept, update)
+		}
+	}
+	s.pending = kept
+}
+
+// Ready receives a value when updates are waiting to be taken.
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Take returns the waiting updates, oldest first.
+func (s *Subscription) Take() []models.ProgressUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updates := s.pending
+	s.pending = nil
+	return updates
+}
+
+// Close stops the deliveries.
+func (s *Subscription) Close() {
+	s.manager.mu.Lock()
+	delete(s.manager.subscribers, s)
+	s.manager.mu.Unlock()
+}
+
+// follows must be called with s.mu held.
+func (s *Subscription) follows(update models.ProgressUpdate) bool {
+	return s.jobs == nil || s.jobs[update.ID] || (update.ParentID != "" && s.jobs[update.ParentID])
+}
+
+func (s *Subscription) deliver(update models.ProgressUpdate) {
+	s.mu.Lock()
+	if !s.follows(update) {
+		s.mu.Unlock()
+		return
+	}
+	if len(s.pending) >= consts.SUBSCRIBER_BUFFER_SIZE {
+		for i := len(s.pending) - 1; i >= 0; i-- {
+			if s.pending[i].ID == update.ID {
+				log.Printf(consts.LOG_SUBSCRIBER_BEHIND, update.ID)
+				s.pending = append(s.pending[:i], s.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	s.pending = append(s.pending, update)
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) broadcast(update models.ProgressUpdate) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log.Printf(consts.LOG_BROADCASTING_SUBSCRIBERS, len(m.subscribers))
+	for sub := range m.subscribers {
+		sub.deliver(update)
+	}
+}
+
+// ActiveJobs returns a snapshot of the jobs that have not finished, oldest
+// first, with the items of each playlist. When IDs are given, only those
+// jobs are included.
+func (m *Manager) ActiveJobs(ids ...string) []models.JobStatus {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	m.mu.RLock()
+	var active []*Download
+	for id, download := range m.downloads {
+		if isTerminalStatus(download.Status) {
+			continue
+		}
+		if len(ids) > 0 && !wanted[id] {
+			continue
+		}
+		if len(ids) == 0 && download.ParentID != "" {
+			continue
+		}
+		active = append(active, download)
+	}
+	sort.Slice(active, func(a, b int) bool {
+		return active[a].CreatedAt.Before(active[b].CreatedAt)
+	})
+
+	jobs := []models.JobStatus{}
+	for _, download := range active {
+		status := download.jobStatus()
+		for _, childID := range download.Children {
+			status.Children = append(status.Children, m.downloads[childID].jobStatus())
+		}
+		jobs = append(jobs, status)
+	}
+	m.mu.RUnlock()
+	return jobs
+}
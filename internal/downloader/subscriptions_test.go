@@ -0,0 +1,170 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/history"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionFollow(t *testing.T) {
+	updates := []models.ProgressUpdate{
+		{ID: "video"},
+		{ID: "playlist"},
+		{ID: "item", ParentID: "playlist"},
+		{ID: "other", ParentID: "elsewhere"},
+	}
+
+	tests := []struct {
+		name   string
+		follow []string
+		want   []string
+	}{
+		{name: "every job", want: []string{"video", "playlist", "item", "other"}},
+		{name: "one job", follow: []string{"video"}, want: []string{"video"}},
+		{name: "playlist and its items", follow: []string{"playlist"}, want: []string{"playlist", "item"}},
+		{name: "playlist item", follow: []string{"item"}, want: []string{"item"}},
+		{name: "unknown job", follow: []string{"missing"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{}
+			subscription := m.Subscribe(tt.follow...)
+			for _, update := range updates {
+				m.broadcast(update)
+			}
+
+			var got []string
+			for _, update := range subscription.Take() {
+				got = append(got, update.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("delivered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionFollowDropsPending(t *testing.T) {
+	m := &Manager{}
+	subscription := m.Subscribe()
+	m.broadcast(models.ProgressUpdate{ID: "kept"})
+	m.broadcast(models.ProgressUpdate{ID: "dropped"})
+
+	subscription.Follow([]string{"kept"})
+	m.broadcast(models.ProgressUpdate{ID: "dropped"})
+
+	updates := subscription.Take()
+	if len(updates) != 1 || updates[0].ID != "kept" {
+		t.Errorf("delivered %+v, want only the followed job", updates)
+	}
+}
+
+func TestSubscriptionCoalescesWhenBehind(t *testing.T) {
+	m := &Manager{}
+	subscription := m.Subscribe()
+
+	m.broadcast(models.ProgressUpdate{ID: "slow", Progress: 1})
+	for i := 1; i < consts.SUBSCRIBER_BUFFER_SIZE; i++ {
+		m.broadcast(models.ProgressUpdate{ID: fmt.Sprintf("job-%d", i)})
+	}
+	// Past the buffer, updates replace the waiting update of their job...
+	m.broadcast(models.ProgressUpdate{ID: "slow", Progress: 2})
+	m.broadcast(models.ProgressUpdate{ID: "slow", Progress: 3, Status: consts.STATUS_COMPLETED})
+	// ...and a job with nothing waiting is still delivered.
+	m.broadcast(models.ProgressUpdate{ID: "new"})
+
+	updates := subscription.Take()
+	if len(updates) != consts.SUBSCRIBER_BUFFER_SIZE+1 {
+		t.Fatalf("delivered %d updates, want %d", len(updates), consts.SUBSCRIBER_BUFFER_SIZE+1)
+	}
+	var slow []models.ProgressUpdate
+	for _, update := range updates {
+		if update.ID == "slow" {
+			slow = append(slow, update)
+		}
+	}
+	if len(slow) != 1 || slow[0].Progress != 3 || slow[0].Status != consts.STATUS_COMPLETED {
+		t.Errorf("updates of the slow job = %+v, want only the latest", slow)
+	}
+	if last := updates[len(updates)-1]; last.ID != "new" {
+		t.Errorf("last update = %+v, want the new job", last)
+	}
+	if updates := subscription.Take(); len(updates) != 0 {
+		t.Errorf("Take() again = %+v, want nothing", updates)
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	m := &Manager{}
+	open := m.Subscribe()
+	closed := m.Subscribe()
+	closed.Close()
+
+	m.broadcast(models.ProgressUpdate{ID: "job"})
+
+	if len(m.subscribers) != 1 {
+		t.Errorf("%d subscribers left, want 1", len(m.subscribers))
+	}
+	select {
+	case <-closed.Ready():
+		t.Error("a closed subscription was signalled")
+	default:
+	}
+	select {
+	case <-open.Ready():
+	default:
+		t.Error("an open subscription was not signalled")
+	}
+	if updates := closed.Take(); len(updates) != 0 {
+		t.Errorf("a closed subscription received %+v", updates)
+	}
+}
+
+func TestActiveJobs(t *testing.T) {
+	m := &Manager{downloads: make(map[string]*Download), history: history.NewMemoryStore(), cfg: config.Default()}
+	created := time.Now()
+	register := func(id, jobType, status, parentID string, children ...string) {
+		download := newDownload(id, jobType, "https://example.com/"+id, "")
+		download.Status = status
+		download.ParentID = parentID
+		download.Children = children
+		download.CreatedAt = created.Add(time.Duration(len(m.downloads)) * time.Second)
+		m.registerJob(download)
+	}
+	register("queued", consts.JOB_TYPE_VIDEO, consts.STATUS_QUEUED, "")
+	register("done", consts.JOB_TYPE_AUDIO, consts.STATUS_COMPLETED, "")
+	register("playlist", consts.JOB_TYPE_PLAYLIST, consts.STATUS_DOWNLOADING, "", "first", "second")
+	register("first", consts.JOB_TYPE_VIDEO, consts.STATUS_COMPLETED, "playlist")
+	register("second", consts.JOB_TYPE_VIDEO, consts.STATUS_DOWNLOADING, "playlist")
+
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{name: "every job", want: []string{"queued", "playlist"}},
+		{name: "listed jobs", ids: []string{"second", "queued"}, want: []string{"queued", "second"}},
+		{name: "finished job", ids: []string{"done"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, job := range m.ActiveJobs(tt.ids...) {
+				got = append(got, job.ID)
+				if job.ID == "playlist" && len(job.Children) != 2 {
+					t.Errorf("playlist snapshot has %d items, want 2", len(job.Children))
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ActiveJobs(%v) = %v, want %v", tt.ids, got, tt.want)
+			}
+		})
+	}
+}
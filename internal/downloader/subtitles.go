@@ -0,0 +1,210 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var subtitleLanguageRegex = regexp.MustCompile(consts.SUBTITLE_LANGUAGE_REGEX)
+
+// ExecuteSubtitleDownload fetches only the requested subtitle tracks. The
+// first track becomes the result file and the rest its sidecars.
+func ExecuteSubtitleDownload(cfg *config.Config, deps *dependencies.Resolver, ytdlp YtDlpClient, workspace string, req models.DownloadRequest, progressCallback ProgressCallback, processCallback ProcessCallback) (*YtDlpResult, error) {
+	args := buildSubtitleCommand(cfg, deps, workspace, req)
+
+	if _, err := executeDownloadProcess(ytdlp, args, progressCallback, processCallback); err != nil {
+		return nil, err
+	}
+
+	files := findSubtitleFiles(workspace)
+	if len(files) == 0 {
+		return nil, fmt.Errorf(consts.ERR_NO_SUBTITLE_FILES)
+	}
+
+	return &YtDlpResult{
+		Title:    subtitleTitle(files[0]),
+		FilePath: files[0],
+		Sidecars: files[1:],
+		TempDir:  workspace,
+		Success:  true,
+	}, nil
+}
+
+func buildSubtitleCommand(cfg *config.Config, deps *dependencies.Resolver, tempDir string, req models.DownloadRequest) []string {
+	outputPath := filepath.Join(tempDir, consts.YT_DLP_OUTPUT_FORMAT)
+
+	args := []string{"-o", outputPath}
+	args = append(args, cfg.YtDlp.SubtitleArgs...)
+	args = append(args, networkArgs(cfg, req.URL)...)
+	args = append(args, consts.YT_DLP_PROGRESS_ARGS...)
+
+	// Converting subtitles needs ffmpeg; without it yt-dlp keeps the
+	// original format.
+	if ffmpegPath, err := deps.FFmpegPath(); err != nil {
+		log.Printf(consts.WARNING_FFMPEG_NOT_FOUND, err)
+	} else {
+		args = append(args, consts.FFMPEG_LOCATION_FLAG, ffmpegPath)
+	}
+
+	// There is no video to embed into, so the tracks are always sidecars.
+	opts := *req.Subtitles
+	opts.Mode = consts.SUBTITLE_MODE_SIDECAR
+	args = append(args, subtitleArgs(&opts)...)
+	args = append(args, req.URL)
+
+	return args
+}
+
+// subtitleArgs returns the yt-dlp options for the requested subtitle tracks,
+// or nothing when no subtitles were asked for.
+func subtitleArgs(opts *models.SubtitleOptions) []string {
+	if opts == nil || len(opts.Languages) == 0 {
+		return nil
+	}
+
+	args := []string{consts.WRITE_SUBS_FLAG, consts.SUB_LANGS_FLAG, strings.Join(opts.Languages, consts.LIST_SEPARATOR)}
+	if opts.Auto {
+		args = append(args, consts.WRITE_AUTO_SUBS_FLAG)
+	}
+	if opts.Format != "" {
+		args = append(args, consts.CONVERT_SUBS_FLAG, opts.Format)
+	}
+	if opts.Mode == consts.SUBTITLE_MODE_EMBED {
+		args = append(args, consts.EMBED_SUBS_FLAG)
+	}
+	return args
+}
+
+// validateDownloadOptions rejects container and subtitle choices yt-dlp
+// cannot honour before the job is queued.
+func validateDownloadOptions(req models.DownloadRequest) error {
+	if req.Container != "" && !containsString(consts.CONTAINERS, req.Container) {
+		return fmt.Errorf(consts.ERR_INVALID_CONTAINER, req.Container)
+	}
+	if req.Clip != nil {
+		if err := validateClipOptions(*req.Clip); err != nil {
+			return err
+		}
+	}
+	if err := validateChapterMode(req); err != nil {
+		return err
+	}
+	if err := validateFormatIDs(req); err != nil {
+		return err
+	}
+	if err := validatePreferences(req.Preferences, req.Quality, req.Container); err != nil {
+		return err
+	}
+	if req.Subtitles == nil {
+		return nil
+	}
+	return validateSubtitleOptions(*req.Subtitles, req.Container)
+}
+
+func validateSubtitleOptions(opts models.SubtitleOptions, container string) error {
+	if len(opts.Languages) == 0 {
+		return fmt.Errorf(consts.ERR_NO_SUBTITLE_LANGUAGES)
+	}
+	for _, language := range opts.Languages {
+		if !subtitleLanguageRegex.MatchString(language) {
+			return fmt.Errorf(consts.ERR_INVALID_SUBTITLE_LANGUAGE, language)
+		}
+	}
+
+	switch opts.Mode {
+	case "", consts.SUBTITLE_MODE_EMBED, consts.SUBTITLE_MODE_SIDECAR:
+	default:
+		return fmt.Errorf(consts.ERR_INVALID_SUBTITLE_MODE, opts.Mode)
+	}
+
+	if opts.Format != "" && !containsString(consts.SUBTITLE_FORMATS, opts.Format) {
+		return fmt.Errorf(consts.ERR_INVALID_SUBTITLE_FORMAT, opts.Format)
+	}
+	if opts.Mode == consts.SUBTITLE_MODE_EMBED && container != consts.CONTAINER_MKV &&
+		containsString(consts.MP4_UNEMBEDDABLE_SUBTITLE_FORMATS, opts.Format) {
+		return fmt.Errorf(consts.ERR_EMBED_NEEDS_MKV, strings.ToUpper(opts.Format))
+	}
+	if opts.Mode == consts.SUBTITLE_MODE_EMBED && container == consts.CONTAINER_WEBM &&
+		opts.Format != consts.SUBTITLE_FORMAT_VTT {
+		return fmt.Errorf(consts.ERR_EMBED_WEBM_NEEDS_VTT)
+	}
+	return nil
+}
+
+// extractSubtitleTracks lists uploaded subtitles first, then automatic
+// captions, each sorted by language.
+func extractSubtitleTracks(info *ytDlpInfo) []models.SubtitleTrack {
+	tracks := []models.SubtitleTrack{}
+	tracks = append(tracks, subtitleTracks(info.Subtitles, false)...)
+	tracks = append(tracks, subtitleTracks(info.AutomaticCaptions, true)...)
+	return tracks
+}
+
+func subtitleTracks(languages map[string][]ytDlpSubtitle, auto bool) []models.SubtitleTrack {
+	tracks := make([]models.SubtitleTrack, 0, len(languages))
+	for language, formats := range languages {
+		// YouTube lists the live chat replay as a subtitle track.
+		if language == consts.LIVE_CHAT_LANGUAGE {
+			continue
+		}
+
+		track := models.SubtitleTrack{Language: language, Formats: []string{}, Auto: auto}
+		for _, format := range formats {
+			if format.Ext != "" {
+				track.Formats = append(track.Formats, format.Ext)
+			}
+			if track.Name == "" {
+				track.Name = format.Name
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].Language < tracks[j].Language
+	})
+	return tracks
+}
+
+func findSubtitleFiles(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+
+	var subtitles []string
+	for _, file := range files {
+		if isSubtitleFile(file) {
+			subtitles = append(subtitles, file)
+		}
+	}
+	return subtitles
+}
+
+func isSubtitleFile(file string) bool {
+	return containsString(consts.SUBTITLE_EXTENSIONS, strings.ToLower(filepath.Ext(file)))
+}
+
+// subtitleTitle strips the format and language extensions yt-dlp adds, so
+// "Title.en.srt" becomes "Title".
+func subtitleTitle(file string) string {
+	return fileStem(fileStem(filepath.Base(file)))
+}
+
+func fileStem(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	tagYearRegex  = regexp.MustCompile(consts.TAG_YEAR_REGEX)
+	tagTrackRegex = regexp.MustCompile(consts.TAG_TRACK_REGEX)
+)
+
+// titleRules holds the compiled tagging patterns. They come from the
+// configuration, so each one is compiled the first time it is used.
+var titleRules = struct {
+	mu       sync.Mutex
+	compiled map[string]*regexp.Regexp
+}{compiled: make(map[string]*regexp.Regexp)}
+
+// titleRule returns the compiled pattern, or nil when it does not compile.
+// Validate rejects such patterns, so this only guards configurations that
+// were never validated.
+func titleRule(pattern string) *regexp.Regexp {
+	titleRules.mu.Lock()
+	defer titleRules.mu.Unlock()
+	if rule, ok := titleRules.compiled[pattern]; ok {
+		return rule
+	}
+	rule, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Printf(consts.WARNING_BAD_TITLE_RULE, pattern, err)
+	}
+	titleRules.compiled[pattern] = rule
+	return rule
+}
+
+// loadTrackInfo reads the info JSON yt-dlp wrote into the workspace.
+func loadTrackInfo(workspace string) (*ytDlpInfo, bool) {
+	files, _ := filepath.Glob(filepath.Join(workspace, consts.INFO_JSON_GLOB))
+	if len(files) == 0 {
+		return nil, false
+	}
+	info, err := readInfoJSON(files[0])
+	if err != nil {
+		return nil, false
+	}
+	return info, true
+}
+
+// deriveTags prefers what the site says about the track. Otherwise the
+// title rules split the video title, and the uploader stands in for a
+// missing artist.
+func deriveTags(cfg config.TaggingConfig, info *ytDlpInfo) models.AudioTags {
+	tags := models.AudioTags{
+		Title:  info.Track,
+		Artist: info.Artist,
+		Album:  info.Album,
+	}
+	if tags.Title == "" {
+		applyTitleRules(cfg, info.Title, &tags)
+	}
+	if tags.Artist == "" {
+		uploader := info.Uploader
+		if uploader == "" {
+			uploader = info.Channel
+		}
+		tags.Artist = strings.TrimSuffix(uploader, consts.TOPIC_CHANNEL_SUFFIX)
+	}
+
+	switch {
+	case tags.Year != "":
+	case info.ReleaseYear > 0:
+		tags.Year = strconv.Itoa(info.ReleaseYear)
+	case len(info.UploadDate) >= 4:
+		tags.Year = info.UploadDate[:4]
+	}
+	if tags.Track == "" && info.TrackNumber > 0 {
+		tags.Track = strconv.Itoa(info.TrackNumber)
+	}
+	return tags
+}
+
+// applyTitleRules cleans up the title and fills the tags named by the
+// capture groups of the first matching pattern.
+func applyTitleRules(cfg config.TaggingConfig, title string, tags *models.AudioTags) {
+	for _, pattern := range cfg.TitleCleanup {
+		if rule := titleRule(pattern); rule != nil {
+			title = rule.ReplaceAllString(title, "")
+		}
+	}
+	title = strings.TrimSpace(title)
+	tags.Title = title
+
+	for _, pattern := range cfg.TitlePatterns {
+		rule := titleRule(pattern)
+		if rule == nil {
+			continue
+		}
+		matches := rule.FindStringSubmatch(title)
+		if matches == nil {
+			continue
+		}
+		for i, name := range rule.SubexpNames() {
+			value := strings.TrimSpace(matches[i])
+			if value == "" {
+				continue
+			}
+			switch name {
+			case consts.TAG_GROUP_TITLE:
+				tags.Title = value
+			case consts.TAG_GROUP_ARTIST:
+				tags.Artist = value
+			case consts.TAG_GROUP_ALBUM:
+				tags.Album = value
+			case consts.TAG_GROUP_YEAR:
+				tags.Year = value
+			case consts.TAG_GROUP_TRACK:
+				tags.Track = value
+			}
+		}
+		return
+	}
+}
+
+// overrideTags replaces the derived tags with the non-empty fields of the
+// request.
+func overrideTags(tags models.AudioTags, overrides *models.AudioTags) models.AudioTags {
+	if overrides == nil {
+		return tags
+	}
+	set := func(tag *string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			*tag = value
+		}
+	}
+	set(&tags.Title, overrides.Title)
+	set(&tags.Artist, overrides.Artist)
+	set(&tags.Album, overrides.Album)
+	set(&tags.Year, overrides.Year)
+	set(&tags.Track, overrides.Track)
+	return tags
+}
+
+func validateTags(tags *models.AudioTags) error {
+	if tags == nil {
+		return nil
+	}
+	if year := strings.TrimSpace(tags.Year); year != "" && !tagYearRegex.MatchString(year) {
+		return fmt.Errorf(consts.ERR_INVALID_TAG_YEAR, tags.Year)
+	}
+	if track := strings.TrimSpace(tags.Track); track != "" && !tagTrackRegex.MatchString(track) {
+		return fmt.Errorf(consts.ERR_INVALID_TAG_TRACK, tags.Track)
+	}
+	return nil
+}
+
+// prepareTags works out the tags for an audio job and the thumbnail to use
+// as cover art, which is empty when there is none or it is turned off.
+func prepareTags(cfg *config.Config, workspace, title string, req models.DownloadRequest) (models.AudioTags, string) {
+	info, ok := loadTrackInfo(workspace)
+	if !ok {
+		log.Printf(consts.WARNING_NO_TRACK_INFO, workspace)
+		info = &ytDlpInfo{Title: title}
+	}
+	tags := overrideTags(deriveTags(cfg.Tagging, info), req.Tags)
+
+	var cover string
+	if cfg.Tagging.CoverArt {
+		if thumbnails, _ := filepath.Glob(filepath.Join(workspace, consts.THUMBNAIL_GLOB)); len(thumbnails) > 0 {
+			cover = thumbnails[0]
+		}
+	}
+	return tags, cover
+}
+
+// tagAudio writes the tags and cover art into a finished audio file. A
+// missing ffmpeg only costs the tags.
+func tagAudio(deps *dependencies.Resolver, file string, tags models.AudioTags, cover string, progressCallback ProgressCallback, processCallback ProcessCallback) error {
+	ffmpegPath, err := deps.FFmpegPath()
+	if err != nil {
+		log.Printf(consts.WARNING_TAGGING_SKIPPED, err)
+		return nil
+	}
+	if progressCallback != nil {
+		progressCallback(models.ProgressUpdate{
+			Phase:   consts.PHASE_EMBED_METADATA,
+			Message: consts.MSG_PHASE_EMBED_METADATA,
+		})
+	}
+	return writeTags(ffmpegPath, file, tags, cover, processCallback)
+}
+
+// writeTags remuxes a file with the given tags without re-encoding the
+// audio. A cover is cropped to a square and embedded where the container
+// allows it.
+func writeTags(ffmpegPath, file string, tags models.AudioTags, cover string, processCallback ProcessCallback) error {
+	ext := strings.ToLower(filepath.Ext(file))
+	if cover != "" && !containsString(consts.COVER_ART_EXTENSIONS, ext) {
+		log.Printf(consts.WARNING_NO_COVER_ART, ext)
+		cover = ""
+	}
+
+	output := fileStem(file) + consts.TAGGED_FILE_SUFFIX + filepath.Ext(file)
+	args := append([]string{}, consts.FFMPEG_PROGRESS_ARGS...)
+	args = append(args, consts.FFMPEG_INPUT_FLAG, file)
+	if cover != "" {
+		args = append(args, consts.FFMPEG_INPUT_FLAG, cover)
+		args = append(args, consts.FFMPEG_COVER_ART_ARGS...)
+	} else {
+		args = append(args, consts.FFMPEG_CLIP_COPY_ARGS...)
+	}
+	if ext == consts.MP3_EXT {
+		args = append(args, consts.FFMPEG_ID3_ARGS...)
+	}
+	for _, tag := range []struct{ format, value string }{
+		{consts.FFMPEG_TAG_TITLE, tags.Title},
+		{consts.FFMPEG_TAG_ARTIST, tags.Artist},
+		{consts.FFMPEG_TAG_ALBUM, tags.Album},
+		{consts.FFMPEG_TAG_DATE, tags.Year},
+		{consts.FFMPEG_TAG_TRACK, tags.Track},
+	} {
+		if tag.value != "" {
+			args = append(args, consts.FFMPEG_METADATA_FLAG, fmt.Sprintf(tag.format, tag.value))
+		}
+	}
+	args = append(args, output)
+
+	if err := runFFmpegWithProgress(ffmpegPath, args, 0, nil, processCallback); err != nil {
+		os.Remove(output)
+		return fmt.Errorf(consts.ERR_TAG_FILE, filepath.Base(file), err)
+	}
+	if err := os.Rename(output, file); err != nil {
+		return fmt.Errorf(consts.ERR_TAG_FILE, filepath.Base(file), err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/models"
+	"testing"
+)
+
+func TestDeriveTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		cleanup []string
+		rules   []string
+		info    ytDlpInfo
+		want    models.AudioTags
+	}{
+		{
+			name: "artist and title from the title",
+			info: ytDlpInfo{Title: "Artist - Song (Official Video)", Uploader: "Label", UploadDate: "20200102"},
+			want: models.AudioTags{Title: "Song", Artist: "Artist", Year: "2020"},
+		},
+		{
+			name: "site tags win",
+			info: ytDlpInfo{Title: "Artist - Song", Track: "Track", Artist: "Band", ReleaseYear: 1999},
+			want: models.AudioTags{Title: "Track", Artist: "Band", Year: "1999"},
+		},
+		{
+			name: "uploader stands in for the artist",
+			info: ytDlpInfo{Title: "Song [HD]", Channel: "Band - Topic"},
+			want: models.AudioTags{Title: "Song", Artist: "Band"},
+		},
+		{
+			name:    "invalid patterns are skipped",
+			cleanup: []string{`(`, `\s*\[live\]`},
+			rules:   []string{`(?P<title>`, `^(?P<title>.+?) by (?P<artist>.+)$`},
+			info:    ytDlpInfo{Title: "Song [live] by Band"},
+			want:    models.AudioTags{Title: "Song", Artist: "Band"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default().Tagging
+			if tt.cleanup != nil {
+				cfg.TitleCleanup = tt.cleanup
+			}
+			if tt.rules != nil {
+				cfg.TitlePatterns = tt.rules
+			}
+			if got := deriveTags(cfg, &tt.info); got != tt.want {
+				t.Errorf("deriveTags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDownloadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  fakeScript
+		wantErr string
+	}{
+		{
+			name:   "success",
+			script: fakeScript{Stdout: []string{"[download] 100% of 10.00MiB"}},
+		},
+		{
+			name:    "unavailable",
+			script:  fakeScript{Stderr: []string{"ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader"}, ExitCode: 1},
+			wantErr: consts.ERR_VIDEO_UNAVAILABLE,
+		},
+		{
+			name:    "forbidden",
+			script:  fakeScript{Stderr: []string{"ERROR: unable to download video data: HTTP Error 403: Forbidden"}, ExitCode: 1},
+			wantErr: consts.ERR_FORBIDDEN,
+		},
+		{
+			name:    "sign in",
+			script:  fakeScript{Stderr: []string{"ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users."}, ExitCode: 1},
+			wantErr: consts.ERR_VIDEO_AGE_RESTRICTED,
+		},
+		{
+			name:    "other failure",
+			script:  fakeScript{Stderr: []string{"WARNING: [youtube] abc: nsig extraction failed: 403", "ERROR: Postprocessing: Conversion failed!"}, ExitCode: 1},
+			wantErr: "ERROR: Postprocessing: Conversion failed!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executeDownloadProcess(newFakeYtDlp(t, tt.script), nil, nil, nil)
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVideoInfoErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stderr  string
+		wantErr string
+	}{
+		{name: "forbidden", stderr: "ERROR: [youtube] abc: HTTP Error 403: Forbidden", wantErr: consts.ERR_FORBIDDEN},
+		{name: "missing fragments", stderr: "ERROR: fragment 1 not found, unable to continue", wantErr: consts.ERR_FRAGMENTS_FAILED},
+		{name: "private", stderr: "ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video", wantErr: consts.ERR_VIDEO_PRIVATE},
+		{name: "removed", stderr: "ERROR: [youtube] abc: Video unavailable", wantErr: consts.ERR_VIDEO_UNAVAILABLE},
+		{name: "geo-blocked", stderr: "ERROR: [youtube] abc: Video unavailable. The uploader has not made this video available in your country", wantErr: consts.ERR_VIDEO_GEO_BLOCKED},
+		{name: "other failure", stderr: "ERROR: [youtube] abc: Unable to extract uploader id", wantErr: fmt.Sprintf(consts.ERR_GET_VIDEO_INFO, "ERROR: [youtube] abc: Unable to extract uploader id")},
+	}
+
+	cfg := config.Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeYtDlp(t, fakeScript{Stderr: []string{tt.stderr}, ExitCode: 1})
+			_, err := GetVideoInfo(cfg, client, "https://www.youtube.com/watch?v=abc")
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestExecuteDownload(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		wantTitle    string
+		wantFile     string
+		wantSidecars []string
+		wantErr      bool
+	}{
+		{
+			name:      "merged file",
+			files:     map[string]string{"Title.mp4": "merged video", "Title.jpg": "thumbnail"},
+			wantTitle: "Title",
+			wantFile:  "Title.mp4",
+		},
+		{
+			name:         "subtitle sidecar",
+			files:        map[string]string{"Title.mkv": "merged video", "Title.en.srt": "1"},
+			wantTitle:    "Title",
+			wantFile:     "Title.mkv",
+			wantSidecars: []string{"Title.en.srt"},
+		},
+		{
+			name:    "only partial download",
+			files:   map[string]string{"Title.mp4.part": "partial"},
+			wantErr: true,
+		},
+	}
+
+	cfg := config.Default()
+	deps := dependencies.NewResolver(cfg)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := t.TempDir()
+			client := newFakeYtDlp(t, fakeScript{
+				Stdout: []string{
+					"[youtube] abc: Downloading webpage",
+					"[download] Destination: " + filepath.Join(workspace, "Title.f137.mp4"),
+					`[goutil:download] {"progress":{"status":"finished"},"vcodec":"avc1","acodec":"none","format_id":"137"}`,
+				},
+				Files: tt.files,
+			})
+
+			var updates []models.ProgressUpdate
+			req := models.DownloadRequest{URL: "https://www.youtube.com/watch?v=abc", Quality: "720p"}
+			result, err := ExecuteDownload(cfg, deps, client, workspace, req, func(update models.ProgressUpdate) {
+				updates = append(updates, update)
+			}, nil)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ExecuteDownload() = %+v, want an error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteDownload() error = %v", err)
+			}
+			if result.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", result.Title, tt.wantTitle)
+			}
+			if result.FilePath != filepath.Join(workspace, tt.wantFile) {
+				t.Errorf("FilePath = %q, want %q", result.FilePath, tt.wantFile)
+			}
+			var sidecars []string
+			for _, sidecar := range result.Sidecars {
+				sidecars = append(sidecars, filepath.Base(sidecar))
+			}
+			if !reflect.DeepEqual(sidecars, tt.wantSidecars) {
+				t.Errorf("Sidecars = %q, want %q", sidecars, tt.wantSidecars)
+			}
+			if len(updates) != 1 || updates[0].Progress != 100 {
+				t.Errorf("progress updates = %+v, want one at 100%%", updates)
+			}
+		})
+	}
+}
+
+func checkError(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Fatalf("error = %v, want nil", err)
+	case want != "" && err == nil:
+		t.Fatalf("error = nil, want %q", want)
+	case want != "" && err.Error() != want:
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
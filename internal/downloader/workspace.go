@@ -0,0 +1,164 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// createWorkspace gives a job its own directory under the shared temp root so
+// concurrent jobs never see each other's partial or finished files.
+func (m *Manager) createWorkspace(id string) (string, error) {
+	dir := filepath.Join(m.cfg.Paths.TempDir, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf(consts.ERR_CREATE_TEMP_DIR, err)
+	}
+
+	m.mu.Lock()
+	if download, ok := m.downloads[id]; ok {
+		download.Workspace = dir
+	}
+	m.mu.Unlock()
+
+	log.Printf(consts.LOG_WORKSPACE_CREATED, id, dir)
+	return dir, nil
+}
+
+// releaseWorkspace removes the workspace once a job is done with it, unless
+// its result is still waiting to be fetched by the browser or the job was
+// stopped by a shutdown and can be resumed from it.
+func (m *Manager) releaseWorkspace(id string) {
+	m.mu.RLock()
+	retained := m.stopping
+	if download, ok := m.downloads[id]; ok {
+		retained = retained || download.retainedFile != ""
+	}
+	m.mu.RUnlock()
+
+	if !retained {
+		m.removeWorkspace(id)
+	}
+}
+
+func (m *Manager) removeWorkspace(id string) {
+	m.mu.Lock()
+	var dir string
+	if download, ok := m.downloads[id]; ok {
+		dir = download.Workspace
+		download.Workspace = ""
+	}
+	m.mu.Unlock()
+
+	if dir == "" {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf(consts.ERR_REMOVE_TEMP_FILE, dir, err)
+		return
+	}
+	log.Printf(consts.LOG_WORKSPACE_REMOVED, id, dir)
+}
+
+// CleanupOrphanedWorkspaces removes workspaces left behind by a previous run
+// that no interrupted job can be resumed from. The temp directory may be
+// shared with other programs, so only entries named like a job ID are
+// touched.
+func (m *Manager) CleanupOrphanedWorkspaces() {
+	entries, err := os.ReadDir(m.cfg.Paths.TempDir)
+	if err != nil {
+		return
+	}
+
+	m.mu.RLock()
+	active := make(map[string]bool)
+	for id, download := range m.downloads {
+		if download.Workspace != "" {
+			active[id] = true
+		}
+	}
+	for id := range m.interrupted {
+		active[id] = true
+	}
+	m.mu.RUnlock()
+
+	for _, entry := range entries {
+		if active[entry.Name()] || !isJobID(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(m.cfg.Paths.TempDir, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			log.Printf(consts.ERR_REMOVE_TEMP_FILE, path, err)
+			continue
+		}
+		log.Printf(consts.LOG_REMOVED_TEMP_FILE, path)
+	}
+}
+
+// Shutdown stops every running job and waits for the jobs to wind down.
+// Unfinished jobs stay in the journal and the history as they were, with
+// their workspaces, so the next run can resume them; the workspaces of
+// finished jobs are removed. The journal stays open until the jobs have
+// wound down so that a job completing meanwhile still leaves it.
+func (m *Manager) Shutdown() {
+	close(m.stopJanitor)
+
+	m.mu.Lock()
+	m.stopping = true
+	m.queue = nil
+	var commands []*exec.Cmd
+	for _, download := range m.downloads {
+		if download.finished {
+			continue
+		}
+		download.cancelled = true
+		download.stoppedByShutdown = true
+		if download.cmd != nil {
+			commands = append(commands, download.cmd)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, cmd := range commands {
+		if err := killProcessTree(cmd); err != nil {
+			log.Printf(consts.LOG_JOB_CONTROL_FAILED, err)
+		}
+	}
+	m.waitForJobs(time.Duration(consts.SHUTDOWN_JOB_WAIT_SECONDS) * time.Second)
+	m.journal.close()
+
+	m.mu.RLock()
+	var ids []string
+	for id, download := range m.downloads {
+		if download.Workspace != "" && download.finished && !download.keptForResume() {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+	for _, id := range ids {
+		m.removeWorkspace(id)
+	}
+
+	if err := m.history.Close(); err != nil {
+		log.Printf(consts.WARNING_HISTORY_SAVE_FAILED, err)
+	}
+}
+
+// waitForJobs waits until every dispatched job has returned, or timeout
+// has passed.
+func (m *Manager) waitForJobs(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		m.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf(consts.LOG_SHUTDOWN_JOBS_TIMEOUT, timeout)
+	}
+}
@@ -0,0 +1,46 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanupOrphanedWorkspaces(t *testing.T) {
+	cfg := config.Default()
+	cfg.Paths.TempDir = t.TempDir()
+
+	entries := map[string]bool{
+		"dl_01HZX3Y4Z5A6B7C8D9E0F1G2H3":    false,
+		"audio_01HZX3Y4Z5A6B7C8D9E0F1G2H4": true,
+		"sub_01HZX3Y4Z5A6B7C8D9E0F1G2H5":   true,
+		"dl_unrelated":                     true,
+		"other-program":                    true,
+		"notes.txt":                        true,
+	}
+	for name := range entries {
+		path := filepath.Join(cfg.Paths.TempDir, name)
+		if filepath.Ext(name) != "" {
+			if err := os.WriteFile(path, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &Manager{
+		downloads:   map[string]*Download{"audio_01HZX3Y4Z5A6B7C8D9E0F1G2H4": {Workspace: filepath.Join(cfg.Paths.TempDir, "audio_01HZX3Y4Z5A6B7C8D9E0F1G2H4")}},
+		interrupted: map[string]journalEntry{"sub_01HZX3Y4Z5A6B7C8D9E0F1G2H5": {}},
+		cfg:         cfg,
+	}
+	m.CleanupOrphanedWorkspaces()
+
+	for name, kept := range entries {
+		_, err := os.Stat(filepath.Join(cfg.Paths.TempDir, name))
+		if exists := err == nil; exists != kept {
+			t.Errorf("%s exists = %v, want %v", name, exists, kept)
+		}
+	}
+}
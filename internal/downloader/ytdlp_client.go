@@ -0,0 +1,176 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ProcessRunner creates the commands for external programs. Tests swap in
+// one that starts a fake yt-dlp.
+type ProcessRunner interface {
+	Command(name string, args ...string) *exec.Cmd
+}
+
+type execRunner struct{}
+
+func (execRunner) Command(name string, args ...string) *exec.Cmd {
+	return exec.Command(name, args...)
+}
+
+// YtDlpClient runs yt-dlp. A yt-dlp that exits with a non-zero code is
+// reported as a *ProcessError.
+type YtDlpClient interface {
+	// Run hands every line yt-dlp prints to onLine and waits for it to
+	// exit. processCallback receives the process once it has started.
+	Run(args []string, onLine func(line string), processCallback ProcessCallback) error
+	// Output runs yt-dlp to the end and returns what it printed.
+	Output(args []string) ([]byte, error)
+}
+
+// ProcessError is a process that exited with a non-zero code, with the end
+// of what it wrote to stderr.
+type ProcessError struct {
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *ProcessError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+type ytDlpClient struct {
+	locate func() (string, error)
+	runner ProcessRunner
+}
+
+// NewYtDlpClient returns a client that runs the yt-dlp found by locate
+// through runner.
+func NewYtDlpClient(locate func() (string, error), runner ProcessRunner) YtDlpClient {
+	return &ytDlpClient{locate: locate, runner: runner}
+}
+
+func (c *ytDlpClient) command(args []string) (*exec.Cmd, error) {
+	path, err := c.locate()
+	if err != nil {
+		return nil, newJobError(consts.ERROR_CODE_DEPENDENCY_MISSING, fmt.Sprintf(consts.ERR_YT_DLP_NOT_FOUND, err), err)
+	}
+	log.Printf(consts.LOG_YT_DLP_COMMAND, path, args)
+	return c.runner.Command(path, args...), nil
+}
+
+func (c *ytDlpClient) Run(args []string, onLine func(line string), processCallback ProcessCallback) error {
+	cmd, err := c.command(args)
+	if err != nil {
+		return err
+	}
+	configureProcess(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf(consts.ERR_CREATE_STDOUT_PIPE, err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf(consts.ERR_CREATE_STDERR_PIPE, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf(consts.ERR_START_YT_DLP_EXE, err)
+	}
+	if processCallback != nil {
+		processCallback(cmd)
+	}
+
+	var stderrTail []string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		stderrTail = readStderr(stderr)
+	}()
+
+	scanner := newLineScanner(stdout)
+	for scanner.Scan() {
+		if onLine != nil {
+			onLine(scanner.Text())
+		}
+	}
+	drain(scanner, stdout)
+	// Both pipes must be drained before Wait closes them.
+	wg.Wait()
+
+	if err := cmd.Wait(); err != nil {
+		return processError(err, strings.Join(stderrTail, "\n"))
+	}
+	return nil
+}
+
+func (c *ytDlpClient) Output(args []string) ([]byte, error) {
+	cmd, err := c.command(args)
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return nil, processError(err, strings.TrimSpace(string(exitError.Stderr)))
+		}
+		return nil, fmt.Errorf(consts.ERR_START_YT_DLP_EXE, err)
+	}
+	return output, nil
+}
+
+// readStderr logs what yt-dlp writes to stderr and keeps the last lines,
+// which hold the error when it fails.
+func readStderr(stderr io.Reader) []string {
+	var tail []string
+	scanner := newLineScanner(stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		log.Printf(consts.LOG_YT_DLP_STDERR, line)
+		tail = append(tail, line)
+		if len(tail) > consts.STDERR_TAIL_LINES {
+			tail = tail[1:]
+		}
+	}
+	drain(scanner, stderr)
+	return tail
+}
+
+// newLineScanner reads yt-dlp output lines of up to
+// consts.YT_DLP_MAX_LINE_BYTES, long enough for the JSON yt-dlp prints.
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), consts.YT_DLP_MAX_LINE_BYTES)
+	return scanner
+}
+
+// drain discards what is left of r after scanner stopped on an error, such
+// as a line that is still too long, so yt-dlp never blocks on a full pipe.
+func drain(scanner *bufio.Scanner, r io.Reader) {
+	if err := scanner.Err(); err != nil {
+		log.Printf(consts.LOG_YT_DLP_OUTPUT_SKIPPED, err)
+		io.Copy(io.Discard, r)
+	}
+}
+
+func processError(err error, stderr string) error {
+	var exitError *exec.ExitError
+	if !errors.As(err, &exitError) {
+		return err
+	}
+	return &ProcessError{ExitCode: exitError.ExitCode(), Stderr: stderr, Err: err}
+}
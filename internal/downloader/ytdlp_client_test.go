@@ -0,0 +1,143 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"bufio"
+	"errors"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYtDlpClientRun(t *testing.T) {
+	var manyLines []string
+	for i := 1; i <= consts.STDERR_TAIL_LINES+5; i++ {
+		manyLines = append(manyLines, fmt.Sprintf("warning %d", i))
+	}
+
+	longLine := `{"id":"abc","formats":"` + strings.Repeat("x", 1<<20) + `"}`
+
+	tests := []struct {
+		name       string
+		script     fakeScript
+		wantLines  []string
+		wantCode   int
+		wantStderr string
+	}{
+		{
+			name:      "success",
+			script:    fakeScript{Stdout: []string{"[youtube] abc: Downloading webpage", "[download] 100%"}},
+			wantLines: []string{"[youtube] abc: Downloading webpage", "[download] 100%"},
+		},
+		{
+			name: "failure keeps stderr",
+			script: fakeScript{
+				Stdout:   []string{"[youtube] abc: Downloading webpage"},
+				Stderr:   []string{"ERROR: [youtube] abc: Video unavailable"},
+				ExitCode: 1,
+			},
+			wantLines:  []string{"[youtube] abc: Downloading webpage"},
+			wantCode:   1,
+			wantStderr: "ERROR: [youtube] abc: Video unavailable",
+		},
+		{
+			name:      "lines past the default scanner limit",
+			script:    fakeScript{Stdout: []string{longLine, "[download] 100%"}, Stderr: []string{longLine}},
+			wantLines: []string{longLine, "[download] 100%"},
+		},
+		{
+			name:       "long stderr is cut to its tail",
+			script:     fakeScript{Stderr: manyLines, ExitCode: 2},
+			wantCode:   2,
+			wantStderr: strings.Join(manyLines[len(manyLines)-consts.STDERR_TAIL_LINES:], "\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeYtDlp(t, tt.script)
+			var lines []string
+			started := false
+			err := client.Run([]string{"https://example.com/watch"}, func(line string) {
+				lines = append(lines, line)
+			}, func(cmd *exec.Cmd) {
+				started = cmd.Process != nil
+			})
+
+			if !started {
+				t.Error("process callback did not receive the started process")
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", lines, tt.wantLines)
+			}
+			checkProcessError(t, err, tt.wantCode, tt.wantStderr)
+		})
+	}
+}
+
+func TestYtDlpClientOutput(t *testing.T) {
+	client := newFakeYtDlp(t, fakeScript{Stdout: []string{`{"id":"abc"}`}})
+	output, err := client.Output(nil)
+	if err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != `{"id":"abc"}` {
+		t.Errorf("Output() = %q", got)
+	}
+
+	client = newFakeYtDlp(t, fakeScript{Stderr: []string{"ERROR: Private video"}, ExitCode: 1})
+	_, err = client.Output(nil)
+	checkProcessError(t, err, 1, "ERROR: Private video")
+}
+
+func TestYtDlpClientNotFound(t *testing.T) {
+	locate := func() (string, error) { return "", errors.New("no yt-dlp") }
+	client := NewYtDlpClient(locate, fakeRunner{})
+
+	err := client.Run(nil, nil, nil)
+	var processErr *ProcessError
+	if err == nil || errors.As(err, &processErr) {
+		t.Fatalf("Run() error = %v, want a lookup error", err)
+	}
+	if _, err := client.Output(nil); err == nil {
+		t.Fatal("Output() error = nil, want a lookup error")
+	}
+}
+
+// checkProcessError checks that err is nil when wantCode is 0, or a
+// *ProcessError with the exit code and stderr.
+func checkProcessError(t *testing.T, err error, wantCode int, wantStderr string) {
+	t.Helper()
+	if wantCode == 0 {
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+		return
+	}
+	var processErr *ProcessError
+	if !errors.As(err, &processErr) {
+		t.Fatalf("error = %v, want a *ProcessError", err)
+	}
+	if processErr.ExitCode != wantCode {
+		t.Errorf("exit code = %d, want %d", processErr.ExitCode, wantCode)
+	}
+	if strings.TrimSpace(processErr.Stderr) != wantStderr {
+		t.Errorf("stderr = %q, want %q", processErr.Stderr, wantStderr)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	output := strings.NewReader(strings.Repeat("x", 100) + "\nrest\n")
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 10), 10)
+	for scanner.Scan() {
+		t.Errorf("scanned %q past the line limit", scanner.Text())
+	}
+
+	drain(scanner, output)
+	if output.Len() != 0 {
+		t.Errorf("%d bytes left unread", output.Len())
+	}
+}
@@ -0,0 +1,134 @@
+package downloader
+
+import (
+	"Go-Utilities/internal/consts"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// ytDlpInfo is a yt-dlp info dict, as printed by --dump-json and written by
+// --write-info-json. Sites fill in different subsets; fields they leave out
+// are zero, and counts a site does not report are nil.
+type ytDlpInfo struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	WebpageURL   string   `json:"webpage_url"`
+	Extractor    string   `json:"extractor"`
+	ExtractorKey string   `json:"extractor_key"`
+	Uploader     string   `json:"uploader"`
+	UploaderID   string   `json:"uploader_id"`
+	UploaderURL  string   `json:"uploader_url"`
+	Channel      string   `json:"channel"`
+	ChannelID    string   `json:"channel_id"`
+	ChannelURL   string   `json:"channel_url"`
+	UploadDate   string   `json:"upload_date"`
+	Duration     float64  `json:"duration"`
+	ViewCount    *int64   `json:"view_count"`
+	LikeCount    *int64   `json:"like_count"`
+	CommentCount *int64   `json:"comment_count"`
+	AgeLimit     int      `json:"age_limit"`
+	LiveStatus   string   `json:"live_status"`
+	Tags         []string `json:"tags"`
+	Categories   []string `json:"categories"`
+
+	Thumbnail  string           `json:"thumbnail"`
+	Thumbnails []ytDlpThumbnail `json:"thumbnails"`
+
+	Formats          []ytDlpFormat `json:"formats"`
+	RequestedFormats []ytDlpFormat `json:"requested_formats"`
+
+	Subtitles         map[string][]ytDlpSubtitle `json:"subtitles"`
+	AutomaticCaptions map[string][]ytDlpSubtitle `json:"automatic_captions"`
+	Chapters          []ytDlpChapter             `json:"chapters"`
+
+	// Music sites describe the track as well as the video.
+	Track       string `json:"track"`
+	Artist      string `json:"artist"`
+	Album       string `json:"album"`
+	TrackNumber int    `json:"track_number"`
+	ReleaseYear int    `json:"release_year"`
+}
+
+type ytDlpFormat struct {
+	FormatID       string  `json:"format_id"`
+	FormatNote     string  `json:"format_note"`
+	Ext            string  `json:"ext"`
+	Protocol       string  `json:"protocol"`
+	VCodec         string  `json:"vcodec"`
+	ACodec         string  `json:"acodec"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	FPS            float64 `json:"fps"`
+	DynamicRange   string  `json:"dynamic_range"`
+	TBR            float64 `json:"tbr"`
+	VBR            float64 `json:"vbr"`
+	ABR            float64 `json:"abr"`
+	ASR            float64 `json:"asr"`
+	AudioChannels  int     `json:"audio_channels"`
+	Language       string  `json:"language"`
+	Filesize       float64 `json:"filesize"`
+	FilesizeApprox float64 `json:"filesize_approx"`
+}
+
+type ytDlpThumbnail struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type ytDlpSubtitle struct {
+	Ext  string `json:"ext"`
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+type ytDlpChapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+func parseVideoInfoJSON(output []byte) (*ytDlpInfo, error) {
+	log.Printf(consts.LOG_RAW_VIDEO_INFO_LENGTH, len(output))
+	if len(output) > 1000 {
+		log.Printf(consts.LOG_FIRST_1000_CHARS, string(output[:1000]))
+	} else {
+		log.Printf(consts.LOG_FULL_OUTPUT, string(output))
+	}
+
+	var info ytDlpInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf(consts.ERR_PARSE_VIDEO_INFO, err)
+	}
+
+	return &info, nil
+}
+
+// readInfoJSON reads an info JSON file written by --write-info-json.
+func readInfoJSON(path string) (*ytDlpInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info ytDlpInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf(consts.ERR_PARSE_VIDEO_INFO, err)
+	}
+	return &info, nil
+}
+
+// isVideo reports whether a format has a video stream. Formats that do not
+// say are assumed to have one, as yt-dlp does.
+func (f ytDlpFormat) isVideo() bool {
+	return f.VCodec != consts.CODEC_NONE
+}
+
+// hasAudio reports whether a format has an audio stream, on the same terms
+// as isVideo.
+func (f ytDlpFormat) hasAudio() bool {
+	return f.ACodec != consts.CODEC_NONE
+}
@@ -1,15 +1,27 @@
 package handlers
 
 import (
+	"Go-Utilities/internal/config"
 	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
 	"Go-Utilities/internal/downloader"
+	"Go-Utilities/internal/health"
+	"Go-Utilities/internal/history"
 	"Go-Utilities/internal/models"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
 	"log"
+	"mime"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
@@ -19,15 +31,36 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+var appConfig *config.Config
 var downloadManager *downloader.Manager
+var healthChecker *health.Checker
 var shutdownSignal = make(chan bool, consts.SHUTDOWN_SIGNAL_BUFFER) // Buffered channel for shutdown signals
 
-func init() {
-	downloadManager = downloader.NewManager()
+// initManager builds the download manager from the loaded configuration.
+func initManager(cfg *config.Config) error {
+	saveTarget, err := downloader.NewSaveTarget(cfg.Downloads.SaveTarget, cfg.Paths.OutputDir)
+	if err != nil {
+		return err
+	}
+
+	historyStore, err := history.Open(filepath.Join(cfg.Paths.DataDir, consts.HISTORY_FILE_NAME))
+	if err != nil {
+		log.Printf(consts.WARNING_HISTORY_UNAVAILABLE, err)
+		historyStore = history.NewMemoryStore()
+	}
+
+	deps := dependencies.NewResolver(cfg)
+	appConfig = cfg
+	healthChecker = health.NewChecker(cfg, deps)
+	downloadManager = downloader.NewManager(cfg, deps, historyStore, saveTarget)
+	downloadManager.CleanupOrphanedWorkspaces()
 
-	if err := downloadManager.TestYtDlp(); err != nil {
-		log.Printf(consts.LOG_YT_DLP_TEST_FAILED, err)
+	for _, check := range healthChecker.Health().Checks {
+		if check.Status != consts.CHECK_PASS {
+			log.Printf(consts.WARNING_HEALTH_CHECK, check.Name, check.Status, check.Message)
+		}
 	}
+	return nil
 }
 
 // SendShutdownSignal sends shutdown signal to all connected WebSocket clients
@@ -41,6 +74,11 @@ func SendShutdownSignal() {
 	}
 }
 
+// Shutdown stops running jobs and releases their workspaces
+func Shutdown() {
+	downloadManager.Shutdown()
+}
+
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
 	tmpl, err := template.ParseFiles(consts.TEMPLATE_PATH)
 	if err != nil {
@@ -63,7 +101,11 @@ func DownloadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf(consts.LOG_STARTING_DOWNLOAD, req.URL, req.Quality)
-	downloadID := downloadManager.StartDownload(req.URL, req.Quality)
+	downloadID, err := downloadManager.StartDownload(req)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
 	log.Printf(consts.LOG_DOWNLOAD_STARTED, downloadID)
 
 	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
@@ -71,6 +113,7 @@ func DownloadHandler(w http.ResponseWriter, r *http.Request) {
 		Success:  true,
 		Message:  consts.MSG_DOWNLOAD_STARTED,
 		FileName: downloadID,
+		FilePath: downloadManager.JobFileURL(downloadID),
 	})
 }
 
@@ -86,7 +129,7 @@ func VideoInfoHandler(w http.ResponseWriter, r *http.Request) {
 
 	videoInfo, err := downloadManager.GetVideoInfo(req.URL)
 	if err != nil {
-		sendJSONError(w, err.Error(), http.StatusBadRequest)
+		sendErrorResponse(w, err, http.StatusBadRequest)
 		return
 	}
 
@@ -94,6 +137,60 @@ func VideoInfoHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(videoInfo)
 }
 
+func PlaylistInfoHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL string `json:"url"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, consts.ERR_INVALID_REQUEST_INFO, http.StatusBadRequest)
+		return
+	}
+
+	playlistInfo, err := downloadManager.GetPlaylistInfo(req.URL)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(playlistInfo)
+}
+
+func PlaylistDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.PlaylistDownloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf(consts.LOG_INVALID_REQUEST_BODY, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST, http.StatusBadRequest)
+		return
+	}
+
+	job, err := downloadManager.StartPlaylist(req)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(models.PlaylistDownloadResponse{
+		Success:  true,
+		Message:  consts.MSG_PLAYLIST_STARTED,
+		FileName: job.ID,
+		Job:      job,
+	})
+}
+
+func JobHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := downloadManager.GetJob(mux.Vars(r)["id"])
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(job)
+}
+
 func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -103,23 +200,49 @@ func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	log.Printf(consts.LOG_WS_CONNECTION_ESTABLISHED)
-	updates := downloadManager.SubscribeToUpdates()
+	subscription := downloadManager.Subscribe()
+	defer subscription.Close()
+
+	// Subscribing before taking the snapshot means no update falls between
+	// the two.
+	if err := writeSnapshot(conn, nil); err != nil {
+		log.Printf(consts.LOG_WS_WRITE_ERROR, err)
+		return
+	}
 
-	// Listen for both download updates and shutdown signals
+	done := make(chan struct{})
+	defer close(done)
+	requests, closed := readWebSocketRequests(conn, done)
+
+	// Listen for download updates, client requests and shutdown signals
 	for {
 		select {
-		case update := <-updates:
-			log.Printf(consts.LOG_SENDING_WS_UPDATE, update)
-			if err := conn.WriteJSON(update); err != nil {
+		case <-subscription.Ready():
+			for _, update := range subscription.Take() {
+				log.Printf(consts.LOG_SENDING_WS_UPDATE, update)
+				if err := conn.WriteJSON(update); err != nil {
+					log.Printf(consts.LOG_WS_WRITE_ERROR, err)
+					return
+				}
+			}
+
+		case req := <-requests:
+			log.Printf(consts.LOG_WS_SUBSCRIBED, req.IDs)
+			subscription.Follow(req.IDs)
+			if err := writeSnapshot(conn, req.IDs); err != nil {
 				log.Printf(consts.LOG_WS_WRITE_ERROR, err)
 				return
 			}
 
+		case err := <-closed:
+			log.Printf(consts.LOG_WS_CONNECTION_CLOSED, err)
+			return
+
 		case <-shutdownSignal:
 			log.Printf(consts.LOG_SENDING_SHUTDOWN_TO_WS)
-			shutdownMsg := map[string]interface{}{
-				"type":    consts.WS_MESSAGE_TYPE_SHUTDOWN,
-				"message": consts.MSG_SHUTDOWN_SIGNAL,
+			shutdownMsg := models.WebSocketMessage{
+				Type:    consts.WS_MESSAGE_TYPE_SHUTDOWN,
+				Message: consts.MSG_SHUTDOWN_SIGNAL,
 			}
 			if err := conn.WriteJSON(shutdownMsg); err != nil {
 				log.Printf(consts.ERR_SEND_SHUTDOWN_SIGNAL, err)
@@ -131,26 +254,370 @@ func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func Mp3ConvertHandler(w http.ResponseWriter, r *http.Request) {
+func writeSnapshot(conn *websocket.Conn, ids []string) error {
+	return conn.WriteJSON(models.WebSocketMessage{
+		Type: consts.WS_MESSAGE_TYPE_SNAPSHOT,
+		Jobs: downloadManager.ActiveJobs(ids...),
+	})
+}
+
+// readWebSocketRequests passes on the subscribe requests of a client until
+// its connection closes, which is reported on closed.
+func readWebSocketRequests(conn *websocket.Conn, done <-chan struct{}) (<-chan models.WebSocketRequest, <-chan error) {
+	requests := make(chan models.WebSocketRequest)
+	closed := make(chan error, 1)
+
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			var req models.WebSocketRequest
+			if err := json.Unmarshal(data, &req); err != nil || req.Type != consts.WS_MESSAGE_TYPE_SUBSCRIBE {
+				log.Printf(consts.LOG_WS_INVALID_MESSAGE, req.Type)
+				continue
+			}
+			select {
+			case requests <- req:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return requests, closed
+}
+
+func AudioExtractHandler(w http.ResponseWriter, r *http.Request) {
 	var req models.DownloadRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf(consts.LOG_INVALID_REQUEST_BODY_MP3, err)
-		sendJSONError(w, consts.ERR_INVALID_REQUEST_MP3, http.StatusBadRequest)
+		log.Printf(consts.LOG_INVALID_AUDIO_REQUEST, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST, http.StatusBadRequest)
 		return
 	}
 
-	log.Printf(consts.LOG_STARTING_MP3_CONVERSION, req.URL)
-	downloadID := downloadManager.StartMp3Convert(req.URL)
-	log.Printf(consts.LOG_MP3_CONVERSION_STARTED, downloadID)
+	log.Printf(consts.LOG_STARTING_AUDIO_JOB, req.URL)
+	downloadID, err := downloadManager.StartAudioExtract(req)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	log.Printf(consts.LOG_AUDIO_JOB_STARTED, downloadID)
 
 	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
 	json.NewEncoder(w).Encode(models.DownloadResponse{
 		Success:  true,
-		Message:  consts.MSG_MP3_CONVERSION_STARTED,
+		Message:  consts.MSG_AUDIO_JOB_STARTED,
 		FileName: downloadID,
+		FilePath: downloadManager.JobFileURL(downloadID),
+	})
+}
+
+func SubtitlesHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.DownloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf(consts.LOG_INVALID_REQUEST_BODY, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST, http.StatusBadRequest)
+		return
+	}
+
+	log.Printf(consts.LOG_STARTING_SUBTITLES, req.URL)
+	downloadID, err := downloadManager.StartSubtitles(req)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	log.Printf(consts.LOG_SUBTITLES_STARTED, downloadID)
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(models.DownloadResponse{
+		Success:  true,
+		Message:  consts.MSG_SUBTITLES_STARTED,
+		FileName: downloadID,
+		FilePath: downloadManager.JobFileURL(downloadID),
+	})
+}
+
+func CancelHandler(w http.ResponseWriter, r *http.Request) {
+	handleJobControl(w, r, downloadManager.CancelDownload, consts.MSG_JOB_CANCEL_REQUESTED)
+}
+
+func PauseHandler(w http.ResponseWriter, r *http.Request) {
+	handleJobControl(w, r, downloadManager.PauseDownload, consts.MSG_JOB_PAUSE_REQUESTED)
+}
+
+func ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	handleJobControl(w, r, downloadManager.ResumeDownload, consts.MSG_JOB_RESUME_REQUESTED)
+}
+
+func RetryHandler(w http.ResponseWriter, r *http.Request) {
+	handleJobControl(w, r, downloadManager.RetryDownload, consts.MSG_JOB_RETRY_REQUESTED)
+}
+
+func handleJobControl(w http.ResponseWriter, r *http.Request, action func(id string) error, successMessage string) {
+	var req models.JobControlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DownloadID == "" {
+		log.Printf(consts.LOG_INVALID_REQUEST_BODY, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST_JOB, http.StatusBadRequest)
+		return
+	}
+
+	if err := action(req.DownloadID); err != nil {
+		log.Printf(consts.LOG_JOB_CONTROL_FAILED, err)
+		sendErrorResponse(w, err, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(models.DownloadResponse{
+		Success:  true,
+		Message:  successMessage,
+		FileName: req.DownloadID,
+	})
+}
+
+func QueueHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(downloadManager.GetQueue())
+}
+
+func QueueMoveHandler(w http.ResponseWriter, r *http.Request) {
+	handleQueueRequest(w, r, func(req models.QueueRequest) error {
+		return downloadManager.MoveQueuedJob(req.DownloadID, req.Position)
 	})
 }
 
+func QueuePriorityHandler(w http.ResponseWriter, r *http.Request) {
+	handleQueueRequest(w, r, func(req models.QueueRequest) error {
+		return downloadManager.SetJobPriority(req.DownloadID, req.Priority)
+	})
+}
+
+func QueueRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	handleQueueRequest(w, r, func(req models.QueueRequest) error {
+		return downloadManager.RemoveQueuedJob(req.DownloadID)
+	})
+}
+
+func handleQueueRequest(w http.ResponseWriter, r *http.Request, action func(req models.QueueRequest) error) {
+	var req models.QueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DownloadID == "" {
+		log.Printf(consts.LOG_INVALID_REQUEST_BODY, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST_JOB, http.StatusBadRequest)
+		return
+	}
+
+	if err := action(req); err != nil {
+		log.Printf(consts.LOG_JOB_CONTROL_FAILED, err)
+		sendErrorResponse(w, err, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(downloadManager.GetQueue())
+}
+
+func QueueConcurrencyHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ConcurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf(consts.LOG_INVALID_REQUEST_BODY, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST, http.StatusBadRequest)
+		return
+	}
+
+	if err := downloadManager.SetMaxConcurrent(req.MaxConcurrent); err != nil {
+		sendErrorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(downloadManager.GetQueue())
+}
+
+// InterruptedHandler lists the jobs the last shutdown cut off.
+func InterruptedHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(downloadManager.GetInterruptedJobs())
+}
+
+func InterruptedResumeHandler(w http.ResponseWriter, r *http.Request) {
+	handleInterruptedRequest(w, r, downloadManager.ResumeInterrupted)
+}
+
+func InterruptedDiscardHandler(w http.ResponseWriter, r *http.Request) {
+	handleInterruptedRequest(w, r, downloadManager.DiscardInterrupted)
+}
+
+// handleInterruptedRequest applies action to the listed jobs, or to all of
+// them when the list is empty, and answers with the jobs still waiting.
+func handleInterruptedRequest(w http.ResponseWriter, r *http.Request, action func(ids []string) error) {
+	var req models.InterruptedJobsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf(consts.LOG_INVALID_REQUEST_BODY, err)
+		sendJSONError(w, consts.ERR_INVALID_REQUEST, http.StatusBadRequest)
+		return
+	}
+
+	if err := action(req.IDs); err != nil {
+		log.Printf(consts.LOG_JOB_CONTROL_FAILED, err)
+		sendErrorResponse(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(downloadManager.GetInterruptedJobs())
+}
+
+// ConfigHandler reports the effective configuration with secrets redacted.
+func ConfigHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(appConfig.Redacted())
+}
+
+// HealthHandler answers 503 when a check fails so it can back a liveness probe.
+func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	report := healthChecker.Health()
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	if report.Status == consts.CHECK_FAIL {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+func DiagnosticsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(healthChecker.Diagnostics())
+}
+
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := models.HistoryQuery{
+		Status: params.Get(consts.QUERY_PARAM_STATUS),
+		Type:   params.Get(consts.QUERY_PARAM_TYPE),
+		Search: params.Get(consts.QUERY_PARAM_SEARCH),
+		Limit:  consts.HISTORY_DEFAULT_LIMIT,
+	}
+
+	var err error
+	if query.Limit, err = parseIntParam(params.Get(consts.QUERY_PARAM_LIMIT), query.Limit); err != nil || query.Limit < 1 || query.Limit > consts.HISTORY_MAX_LIMIT {
+		sendJSONError(w, fmt.Sprintf(consts.ERR_INVALID_QUERY_PARAM, consts.QUERY_PARAM_LIMIT), http.StatusBadRequest)
+		return
+	}
+	if query.Offset, err = parseIntParam(params.Get(consts.QUERY_PARAM_OFFSET), 0); err != nil || query.Offset < 0 {
+		sendJSONError(w, fmt.Sprintf(consts.ERR_INVALID_QUERY_PARAM, consts.QUERY_PARAM_OFFSET), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	json.NewEncoder(w).Encode(downloadManager.GetHistory(query))
+}
+
+func parseIntParam(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// JobFileHandler streams a finished file kept for the browser save target.
+// Range requests are served as-is; the workspace is released once a single
+// response has delivered the whole file and no other request is reading it.
+func JobFileHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	path, err := downloadManager.OpenJobFile(id)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusNotFound)
+		return
+	}
+	delivered := false
+	defer func() {
+		downloadManager.CloseJobFile(id, delivered)
+	}()
+
+	file, err := os.Open(path)
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		sendErrorResponse(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	name := filepath.Base(path)
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, mediaContentType(name))
+	w.Header().Set(consts.HEADER_CONTENT_DISPOSITION, contentDisposition(name))
+
+	counter := &countingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	http.ServeContent(counter, r, name, info.ModTime(), file)
+
+	if r.Method == consts.HTTP_GET && counter.status == http.StatusOK && counter.written == info.Size() {
+		log.Printf(consts.LOG_JOB_FILE_SERVED, id, path)
+		delivered = true
+	}
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (w *countingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+func mediaContentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if contentType, ok := consts.MEDIA_CONTENT_TYPES[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return consts.CONTENT_TYPE_OCTET_STREAM
+}
+
+// contentDisposition builds an attachment header with a plain ASCII filename
+// for old clients and an RFC 5987 encoded one that keeps the full title.
+func contentDisposition(name string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	for _, b := range []byte(name) {
+		if isRFC5987AttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf(consts.CONTENT_DISPOSITION_ATTACHMENT, fallback.String(), encoded.String())
+}
+
+func isRFC5987AttrChar(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
 func ShutdownHandler(w http.ResponseWriter, r *http.Request) {
 	tmpl, err := template.ParseFiles(consts.SHUTDOWN_TEMPLATE_PATH)
 	if err != nil {
@@ -164,11 +631,46 @@ func ShutdownHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func sendJSONError(w http.ResponseWriter, message string, code int) {
-	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(models.DownloadResponse{
+func sendJSONError(w http.ResponseWriter, message string, status int) {
+	writeErrorResponse(w, status, models.DownloadResponse{
 		Success: false,
 		Message: message,
+		Code:    statusErrorCode(status),
+	})
+}
+
+// sendErrorResponse reports err with its error code. Errors that did not
+// come from a job and match no known failure, such as a rejected request,
+// get the code that goes with the HTTP status.
+func sendErrorResponse(w http.ResponseWriter, err error, status int) {
+	jobErr := downloader.ClassifyError(err)
+	code := jobErr.Code
+	if code == consts.ERROR_CODE_UNKNOWN && !errors.As(err, new(*downloader.JobError)) {
+		code = statusErrorCode(status)
+	}
+	writeErrorResponse(w, status, models.DownloadResponse{
+		Success:   false,
+		Message:   jobErr.Message,
+		Code:      code,
+		Retryable: jobErr.Retryable,
+		Hint:      jobErr.Hint,
 	})
 }
+
+func writeErrorResponse(w http.ResponseWriter, status int, response models.DownloadResponse) {
+	w.Header().Set(consts.HEADER_CONTENT_TYPE, consts.CONTENT_TYPE_JSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return consts.ERROR_CODE_INVALID_REQUEST
+	case http.StatusNotFound:
+		return consts.ERROR_CODE_NOT_FOUND
+	case http.StatusConflict:
+		return consts.ERROR_CODE_CONFLICT
+	}
+	return consts.ERROR_CODE_INTERNAL
+}
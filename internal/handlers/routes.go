@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"Go-Utilities/internal/config"
 	"Go-Utilities/internal/consts"
 	"net/http"
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes() *mux.Router {
+func SetupRoutes(cfg *config.Config) (*mux.Router, error) {
+	if err := initManager(cfg); err != nil {
+		return nil, err
+	}
+
 	r := mux.NewRouter()
 	
 	// Static files
@@ -22,9 +27,32 @@ func SetupRoutes() *mux.Router {
 	// API routes
 	api := r.PathPrefix(consts.API_ROUTE_PREFIX).Subrouter()
 	api.HandleFunc(consts.DOWNLOAD_ROUTE, DownloadHandler).Methods(consts.HTTP_POST)
-	api.HandleFunc(consts.MP3_CONVERT_ROUTE, Mp3ConvertHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.AUDIO_EXTRACT_ROUTE, AudioExtractHandler).Methods(consts.HTTP_POST)
+	// The old MP3 endpoint accepts the same requests and defaults to MP3.
+	api.HandleFunc(consts.MP3_CONVERT_ROUTE, AudioExtractHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.SUBTITLES_ROUTE, SubtitlesHandler).Methods(consts.HTTP_POST)
 	api.HandleFunc(consts.VIDEO_INFO_ROUTE, VideoInfoHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.PLAYLIST_INFO_ROUTE, PlaylistInfoHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.PLAYLIST_DOWNLOAD_ROUTE, PlaylistDownloadHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.CANCEL_ROUTE, CancelHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.PAUSE_ROUTE, PauseHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.RESUME_ROUTE, ResumeHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.RETRY_ROUTE, RetryHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.CONFIG_ROUTE, ConfigHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.HEALTH_ROUTE, HealthHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.DIAGNOSTICS_ROUTE, DiagnosticsHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.HISTORY_ROUTE, HistoryHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.QUEUE_ROUTE, QueueHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.QUEUE_MOVE_ROUTE, QueueMoveHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.QUEUE_PRIORITY_ROUTE, QueuePriorityHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.QUEUE_REMOVE_ROUTE, QueueRemoveHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.QUEUE_CONCURRENCY_ROUTE, QueueConcurrencyHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.INTERRUPTED_ROUTE, InterruptedHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.INTERRUPTED_RESUME_ROUTE, InterruptedResumeHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.INTERRUPTED_DISCARD_ROUTE, InterruptedDiscardHandler).Methods(consts.HTTP_POST)
+	api.HandleFunc(consts.JOB_ROUTE, JobHandler).Methods(consts.HTTP_GET)
+	api.HandleFunc(consts.JOB_FILE_ROUTE, JobFileHandler).Methods(consts.HTTP_GET, consts.HTTP_HEAD)
 	api.HandleFunc(consts.WEBSOCKET_ROUTE, WebSocketHandler)
 	
-	return r
+	return r, nil
 }
\ No newline at end of file
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !windows
+
+package health
+
+import (
+	"Go-Utilities/internal/consts"
+	"errors"
+)
+
+func freeDiskSpace(path string) (uint64, error) {
+	return 0, errors.New(consts.ERR_DISK_SPACE_UNSUPPORTED)
+}
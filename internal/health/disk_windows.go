@@ -0,0 +1,33 @@
+//go:build windows
+
+package health
+
+import (
+	"Go-Utilities/internal/consts"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32               = syscall.NewLazyDLL(consts.KERNEL32_DLL)
+	procGetDiskFreeSpaceEx = kernel32.NewProc(consts.GET_DISK_FREE_SPACE_EX_PROC)
+)
+
+func freeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var freeBytesAvailable uint64
+	ret, _, callErr := procGetDiskFreeSpaceEx.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&freeBytesAvailable)),
+		0,
+		0,
+	)
+	if ret == 0 {
+		return 0, callErr
+	}
+	return freeBytesAvailable, nil
+}
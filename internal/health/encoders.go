@@ -0,0 +1,34 @@
+package health
+
+import (
+	"Go-Utilities/internal/consts"
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// listEncoders returns the encoder names printed by `ffmpeg -encoders`. The
+// list starts after the legend, which ends with a line of dashes.
+func listEncoders(ffmpegPath string) ([]string, error) {
+	output, err := exec.Command(ffmpegPath, consts.FFMPEG_HIDE_BANNER_FLAG, consts.FFMPEG_ENCODERS_FLAG).Output()
+	if err != nil {
+		return nil, fmt.Errorf(consts.ERR_LIST_ENCODERS, err)
+	}
+
+	var encoders []string
+	inList := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !inList {
+			inList = strings.HasPrefix(line, consts.FFMPEG_LIST_SEPARATOR)
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			encoders = append(encoders, fields[1])
+		}
+	}
+	return encoders, scanner.Err()
+}
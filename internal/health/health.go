@@ -0,0 +1,254 @@
+package health
+
+import (
+	"Go-Utilities/internal/config"
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/dependencies"
+	"Go-Utilities/internal/downloader"
+	"Go-Utilities/internal/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var (
+	ytDlpVersionRegex  = regexp.MustCompile(consts.YT_DLP_VERSION_REGEX)
+	ffmpegVersionRegex = regexp.MustCompile(consts.FFMPEG_VERSION_REGEX)
+)
+
+// Checker reports whether the external tools and disk space the downloader
+// relies on are usable.
+type Checker struct {
+	cfg  *config.Config
+	deps *dependencies.Resolver
+}
+
+func NewChecker(cfg *config.Config, deps *dependencies.Resolver) *Checker {
+	return &Checker{cfg: cfg, deps: deps}
+}
+
+// Health runs the cheap checks: cached binary lookups and free disk space.
+func (c *Checker) Health() models.HealthReport {
+	var checks []models.HealthCheck
+
+	ytDlp := c.dependency(dependencies.YtDlp)
+	ffmpeg := c.dependency(dependencies.FFmpeg)
+	ffprobe := c.dependency(dependencies.FFprobe)
+	checks = append(checks, ytDlpCheck(ytDlp), ffmpegCheck(ffmpeg), ffprobeCheck(ffprobe))
+
+	for _, disk := range c.disks() {
+		checks = append(checks, diskCheck(disk))
+	}
+
+	return models.HealthReport{Status: verdict(checks), Checks: checks}
+}
+
+// Diagnostics searches for the binaries again and also inspects the ffmpeg
+// build for the encoders the audio and video pipelines use.
+func (c *Checker) Diagnostics() models.DiagnosticsReport {
+	c.deps.Refresh()
+
+	report := models.DiagnosticsReport{
+		YtDlp:   c.dependency(dependencies.YtDlp),
+		FFmpeg:  c.dependency(dependencies.FFmpeg),
+		FFprobe: c.dependency(dependencies.FFprobe),
+		Disks:   c.disks(),
+	}
+
+	checks := []models.HealthCheck{ytDlpCheck(report.YtDlp), ffmpegCheck(report.FFmpeg), ffprobeCheck(report.FFprobe)}
+	if report.FFmpeg.Found {
+		encoders, err := listEncoders(report.FFmpeg.Path)
+		report.Encoders = encoders
+		checks = append(checks, encodersCheck(encoders, err))
+	}
+	for _, disk := range report.Disks {
+		checks = append(checks, diskCheck(disk))
+	}
+
+	report.Checks = checks
+	report.Status = verdict(checks)
+	return report
+}
+
+func (c *Checker) dependency(tool dependencies.Tool) models.DependencyInfo {
+	resolution, err := c.deps.Resolve(tool)
+	info := models.DependencyInfo{
+		Name:    tool.Name,
+		Found:   err == nil,
+		Path:    resolution.Path,
+		Source:  resolution.Source,
+		Version: resolution.Version,
+		Tried:   resolution.Tried,
+	}
+	if info.Found {
+		info.ParsedVersion = parseVersion(tool, resolution.Version)
+	} else {
+		info.Error = err.Error()
+	}
+	return info
+}
+
+func (c *Checker) disks() []models.DiskSpace {
+	dirs := []struct{ name, path string }{
+		{consts.DISK_TEMP, c.cfg.Paths.TempDir},
+		{consts.DISK_OUTPUT, c.cfg.Paths.OutputDir},
+	}
+
+	disks := make([]models.DiskSpace, 0, len(dirs))
+	for _, dir := range dirs {
+		disk := models.DiskSpace{Name: dir.name, Path: dir.path}
+		free, err := freeDiskSpace(existingParent(dir.path))
+		if err != nil {
+			disk.Error = err.Error()
+		} else {
+			disk.FreeBytes = free
+			disk.Free = downloader.FormatFileSize(int64(free))
+		}
+		disks = append(disks, disk)
+	}
+	return disks
+}
+
+// existingParent walks up from path to the nearest directory that exists,
+// since the output directory is only created on first use.
+func existingParent(path string) string {
+	path, _ = filepath.Abs(path)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// parseVersion normalises yt-dlp's date versions to YYYY-MM-DD and ffmpeg
+// releases to MAJOR.MINOR.PATCH. Git builds of ffmpeg have no release number.
+func parseVersion(tool dependencies.Tool, raw string) string {
+	switch tool.Name {
+	case consts.YT_DLP_BINARY:
+		if m := ytDlpVersionRegex.FindStringSubmatch(raw); m != nil {
+			return fmt.Sprintf(consts.DATE_VERSION_FORMAT, m[1], m[2], m[3])
+		}
+	case consts.FFMPEG_BINARY, consts.FFPROBE_BINARY:
+		if m := ffmpegVersionRegex.FindStringSubmatch(raw); m != nil {
+			patch := m[3]
+			if patch == "" {
+				patch = "0"
+			}
+			return fmt.Sprintf(consts.SEMVER_FORMAT, m[1], m[2], patch)
+		}
+	}
+	return ""
+}
+
+func ytDlpCheck(info models.DependencyInfo) models.HealthCheck {
+	check := models.HealthCheck{Name: info.Name, Status: consts.CHECK_PASS, Message: versionLabel(info)}
+	if !info.Found {
+		check.Status = consts.CHECK_FAIL
+		check.Message = missingMessage(info, consts.MSG_CHECK_YT_DLP_MISSING)
+		return check
+	}
+
+	released, err := time.Parse(consts.DATE_VERSION_LAYOUT, info.ParsedVersion)
+	if err != nil {
+		check.Status = consts.CHECK_WARN
+		check.Message = fmt.Sprintf(consts.MSG_CHECK_VERSION_UNKNOWN, info.Version)
+		return check
+	}
+	if time.Since(released) > consts.YT_DLP_MAX_AGE_DAYS*24*time.Hour {
+		check.Status = consts.CHECK_WARN
+		check.Message = fmt.Sprintf(consts.MSG_CHECK_YT_DLP_OUTDATED, info.ParsedVersion)
+	}
+	return check
+}
+
+func ffmpegCheck(info models.DependencyInfo) models.HealthCheck {
+	if !info.Found {
+		return models.HealthCheck{Name: info.Name, Status: consts.CHECK_FAIL, Message: missingMessage(info, consts.MSG_CHECK_FFMPEG_MISSING)}
+	}
+	return models.HealthCheck{Name: info.Name, Status: consts.CHECK_PASS, Message: versionLabel(info)}
+}
+
+func ffprobeCheck(info models.DependencyInfo) models.HealthCheck {
+	if !info.Found {
+		return models.HealthCheck{Name: info.Name, Status: consts.CHECK_WARN, Message: consts.MSG_CHECK_FFPROBE_MISSING}
+	}
+	return models.HealthCheck{Name: info.Name, Status: consts.CHECK_PASS, Message: versionLabel(info)}
+}
+
+// missingMessage reports a tool whose configured path cannot be used as
+// such, rather than as not installed.
+func missingMessage(info models.DependencyInfo, message string) string {
+	if info.Source == consts.SOURCE_CONFIG {
+		return info.Error
+	}
+	return message
+}
+
+func versionLabel(info models.DependencyInfo) string {
+	if info.ParsedVersion != "" {
+		return info.ParsedVersion
+	}
+	return info.Version
+}
+
+func encodersCheck(encoders []string, err error) models.HealthCheck {
+	check := models.HealthCheck{Name: consts.CHECK_ENCODERS, Status: consts.CHECK_PASS}
+	if err != nil {
+		check.Status = consts.CHECK_WARN
+		check.Message = err.Error()
+		return check
+	}
+
+	available := make(map[string]bool, len(encoders))
+	for _, encoder := range encoders {
+		available[encoder] = true
+	}
+	var missing []string
+	for _, encoder := range consts.REQUIRED_ENCODERS {
+		if !available[encoder] {
+			missing = append(missing, encoder)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status = consts.CHECK_WARN
+		check.Message = fmt.Sprintf(consts.MSG_CHECK_ENCODERS_MISSING, missing)
+		return check
+	}
+	check.Message = fmt.Sprintf(consts.MSG_CHECK_ENCODERS_FOUND, len(encoders))
+	return check
+}
+
+func diskCheck(disk models.DiskSpace) models.HealthCheck {
+	check := models.HealthCheck{Name: consts.CHECK_DISK_PREFIX + disk.Name, Status: consts.CHECK_PASS, Message: fmt.Sprintf(consts.MSG_CHECK_DISK_FREE, disk.Free, disk.Path)}
+	switch {
+	case disk.Error != "":
+		check.Status = consts.CHECK_WARN
+		check.Message = disk.Error
+	case disk.FreeBytes < consts.MIN_FREE_DISK_BYTES:
+		check.Status = consts.CHECK_FAIL
+	case disk.FreeBytes < consts.LOW_FREE_DISK_BYTES:
+		check.Status = consts.CHECK_WARN
+	}
+	return check
+}
+
+// verdict is the worst status among the checks.
+func verdict(checks []models.HealthCheck) string {
+	status := consts.CHECK_PASS
+	for _, check := range checks {
+		switch check.Status {
+		case consts.CHECK_FAIL:
+			return consts.CHECK_FAIL
+		case consts.CHECK_WARN:
+			status = consts.CHECK_WARN
+		}
+	}
+	return status
+}
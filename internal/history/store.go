@@ -0,0 +1,199 @@
+package history
+
+import (
+	"Go-Utilities/internal/consts"
+	"Go-Utilities/internal/models"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store keeps job history in memory and mirrors every change to an
+// append-only JSON-lines file. The file is compacted on open so it holds one
+// line per job after a restart.
+type Store struct {
+	path    string
+	file    *os.File
+	entries map[string]*models.HistoryEntry
+	mu      sync.RWMutex
+}
+
+// NewMemoryStore returns a store that is not backed by a file.
+func NewMemoryStore() *Store {
+	return &Store{entries: make(map[string]*models.HistoryEntry)}
+}
+
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf(consts.ERR_HISTORY_OPEN, path, err)
+	}
+
+	store := &Store{
+		path:    path,
+		entries: make(map[string]*models.HistoryEntry),
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf(consts.ERR_HISTORY_OPEN, path, err)
+	}
+	store.file = file
+
+	log.Printf(consts.LOG_HISTORY_LOADED, len(store.entries), path)
+	return store, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(consts.ERR_HISTORY_OPEN, s.path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), consts.HISTORY_MAX_LINE_BYTES)
+	for scanner.Scan() {
+		var entry models.HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.ID == "" {
+			log.Printf(consts.WARNING_HISTORY_BAD_LINE, err)
+			continue
+		}
+		s.entries[entry.ID] = &entry
+	}
+	return scanner.Err()
+}
+
+func (s *Store) compact() error {
+	tempPath := s.path + consts.TEMP_EXT
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf(consts.ERR_HISTORY_WRITE, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range s.sortedEntries() {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return fmt.Errorf(consts.ERR_HISTORY_WRITE, err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf(consts.ERR_HISTORY_WRITE, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf(consts.ERR_HISTORY_WRITE, err)
+	}
+	return os.Rename(tempPath, s.path)
+}
+
+// Save records the latest state of a job.
+func (s *Store) Save(entry models.HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.ID] = &entry
+	if s.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf(consts.ERR_HISTORY_WRITE, err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf(consts.ERR_HISTORY_WRITE, err)
+	}
+	return nil
+}
+
+func (s *Store) Get(id string) (models.HistoryEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return models.HistoryEntry{}, false
+	}
+	return *entry, true
+}
+
+// Query returns the newest entries first, filtered by status, job type and a
+// case-insensitive search over title and URL.
+func (s *Store) Query(query models.HistoryQuery) models.HistoryPage {
+	s.mu.RLock()
+	entries := s.sortedEntries()
+	s.mu.RUnlock()
+
+	search := strings.ToLower(query.Search)
+	matched := []models.HistoryEntry{}
+	for _, entry := range entries {
+		if query.Status != "" && entry.Status != query.Status {
+			continue
+		}
+		if query.Type != "" && entry.Type != query.Type {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(entry.Title), search) && !strings.Contains(strings.ToLower(entry.URL), search) {
+			continue
+		}
+		matched = append(matched, *entry)
+	}
+
+	page := models.HistoryPage{
+		Entries: []models.HistoryEntry{},
+		Total:   len(matched),
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+	if query.Offset < len(matched) {
+		end := len(matched)
+		if query.Limit > 0 && query.Offset+query.Limit < end {
+			end = query.Offset + query.Limit
+		}
+		page.Entries = matched[query.Offset:end]
+	}
+	return page
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *Store) sortedEntries() []*models.HistoryEntry {
+	entries := make([]*models.HistoryEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries
+}
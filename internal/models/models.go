@@ -1,38 +1,447 @@
 package models
 
+import "time"
+
 type DownloadRequest struct {
-	URL     string `json:"url"`
-	Quality string `json:"quality"`
+	URL      string `json:"url"`
+	Quality  string `json:"quality"`
+	Priority int    `json:"priority,omitempty"`
+	// Container is the merged output container, "mp4" or "mkv"; the yt-dlp
+	// download arguments decide when it is empty.
+	Container string           `json:"container,omitempty"`
+	Subtitles *SubtitleOptions `json:"subtitles,omitempty"`
+	Clip      *ClipOptions     `json:"clip,omitempty"`
+	// Chapters is "embed" to keep chapter markers in the file or "split"
+	// to save one file per chapter, numbered as album tracks.
+	Chapters string `json:"chapters,omitempty"`
+	// Audio applies to audio extraction jobs, which fall back to Quality
+	// when Audio.Quality is empty.
+	Audio *AudioOptions `json:"audio,omitempty"`
+	// Tags overrides the tags an audio extraction derives from the video.
+	Tags *AudioTags `json:"tags,omitempty"`
+	// VideoFormatID and AudioFormatID pick formats from the video info
+	// and take precedence over Quality. Audio extraction only takes an
+	// audio format.
+	VideoFormatID string `json:"video_format_id,omitempty"`
+	AudioFormatID string `json:"audio_format_id,omitempty"`
+	// Preferences refine Quality, which must then be a height such as
+	// "720p" or "best".
+	Preferences *QualityPreferences `json:"preferences,omitempty"`
+	// MaxAttempts overrides how many attempts the job gets when it fails
+	// for a reason that may pass; 0 keeps the configured number.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+}
+
+// QualityPreferences shape the formats a video download picks from. Codecs
+// ("h264", "vp9" or "av1") are tried in order before any other codec. The
+// rest are limits: formats that do not report a value pass them, and
+// MaxFileSize, a yt-dlp size such as "500M", applies to the video and the
+// audio stream separately.
+type QualityPreferences struct {
+	Codecs      []string `json:"codecs,omitempty"`
+	MaxFPS      int      `json:"max_fps,omitempty"`
+	ExcludeHDR  bool     `json:"exclude_hdr,omitempty"`
+	MaxFileSize string   `json:"max_filesize,omitempty"`
+}
+
+// AudioTags are the tags written to an audio file. Year is four digits and
+// Track a number, optionally with the total such as "3/12". In a request
+// only the non-empty fields override the derived tags.
+type AudioTags struct {
+	Title  string `json:"title,omitempty"`
+	Artist string `json:"artist,omitempty"`
+	Album  string `json:"album,omitempty"`
+	Year   string `json:"year,omitempty"`
+	Track  string `json:"track,omitempty"`
+}
+
+// AudioOptions selects what an audio extraction produces. Format is one of
+// mp3, m4a, opus, flac, wav or ogg, or "original" to keep the downloaded
+// stream without re-encoding. Quality is a VBR level from 0 (best) to 10 or
+// a bitrate such as "192K" and does not apply to lossless formats.
+type AudioOptions struct {
+	Format     string `json:"format,omitempty"`
+	Quality    string `json:"quality,omitempty"`
+	SampleRate int    `json:"sample_rate,omitempty"`
+	Channels   int    `json:"channels,omitempty"`
+}
+
+// ClipOptions keeps only parts of a video, each saved as its own file.
+// Chapters are chapter titles. Accurate re-encodes so cuts land exactly;
+// otherwise streams are copied and cuts snap to the nearest keyframe.
+type ClipOptions struct {
+	Ranges   []ClipRange `json:"ranges,omitempty"`
+	Chapters []string    `json:"chapters,omitempty"`
+	Accurate bool        `json:"accurate,omitempty"`
+}
+
+// ClipRange is a section given as seconds, MM:SS or HH:MM:SS. An empty End
+// runs to the end of the video.
+type ClipRange struct {
+	Start string `json:"start"`
+	End   string `json:"end,omitempty"`
+}
+
+// SubtitleOptions selects subtitle tracks by language code. Mode is "embed"
+// to mux them into the video or "sidecar" to save them as separate files;
+// Format converts them to "srt", "vtt" or "ass".
+type SubtitleOptions struct {
+	Languages []string `json:"languages"`
+	Auto      bool     `json:"auto,omitempty"`
+	Mode      string   `json:"mode,omitempty"`
+	Format    string   `json:"format,omitempty"`
+}
+
+// PlaylistDownloadRequest starts the selected entries of a playlist as child
+// jobs of one parent job. Type is a job type ("video" or "audio"; "mp3" is
+// still accepted). Audio applies to audio items.
+type PlaylistDownloadRequest struct {
+	URL      string          `json:"url"`
+	Title    string          `json:"title"`
+	Type     string          `json:"type"`
+	Quality  string          `json:"quality"`
+	Audio    *AudioOptions   `json:"audio,omitempty"`
+	Priority int             `json:"priority,omitempty"`
+	Entries  []PlaylistEntry `json:"entries"`
+
+	Preferences *QualityPreferences `json:"preferences,omitempty"`
+	MaxAttempts int                 `json:"max_attempts,omitempty"`
+}
+
+type JobControlRequest struct {
+	DownloadID string `json:"downloadId"`
 }
 
+type QueueRequest struct {
+	DownloadID string `json:"downloadId"`
+	Position   int    `json:"position,omitempty"`
+	Priority   int    `json:"priority,omitempty"`
+}
+
+// InterruptedJobsRequest names interrupted jobs to resume or discard; an
+// empty list means all of them. Naming a playlist names its items.
+type InterruptedJobsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// InterruptedJob is a job that was still running or queued when the
+// application last stopped. PartialBytes is how much of it is already in its
+// workspace.
+type InterruptedJob struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	URL          string    `json:"url"`
+	Title        string    `json:"title,omitempty"`
+	Quality      string    `json:"quality,omitempty"`
+	ParentID     string    `json:"parent_id,omitempty"`
+	PartialBytes int64     `json:"partial_bytes"`
+	CreatedAt    time.Time `json:"created_at"`
+	// InterruptedAt is when the job was found after the restart.
+	InterruptedAt time.Time `json:"interrupted_at"`
+}
+
+type ConcurrencyRequest struct {
+	MaxConcurrent int `json:"max_concurrent"`
+}
+
+type QueuedJob struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	URL      string `json:"url"`
+	Title    string `json:"title,omitempty"`
+	Priority int    `json:"priority"`
+	Position int    `json:"position"`
+}
+
+type QueueStatus struct {
+	Running       int         `json:"running"`
+	MaxConcurrent int         `json:"max_concurrent"`
+	Queued        []QueuedJob `json:"queued"`
+}
+
+// DownloadResponse carries a stable error code, whether retrying may help
+// and a hint for the user when Success is false.
 type DownloadResponse struct {
-	Success  bool   `json:"success"`
-	Message  string `json:"message"`
-	FileName string `json:"filename,omitempty"`
-	FilePath string `json:"filepath,omitempty"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Code      string `json:"code,omitempty"`
+	Retryable bool   `json:"retryable,omitempty"`
+	Hint      string `json:"hint,omitempty"`
+	FileName  string `json:"filename,omitempty"`
+	FilePath  string `json:"filepath,omitempty"`
+}
+
+type PlaylistDownloadResponse struct {
+	Success  bool      `json:"success"`
+	Message  string    `json:"message"`
+	FileName string    `json:"filename,omitempty"`
+	Job      JobStatus `json:"job"`
+}
+
+// JobStatus is a snapshot of a job. Playlist jobs list their items in
+// Children.
+type JobStatus struct {
+	ID          string       `json:"id"`
+	Type        string       `json:"type"`
+	URL         string       `json:"url"`
+	Title       string       `json:"title,omitempty"`
+	Quality     string       `json:"quality,omitempty"`
+	Status      string       `json:"status"`
+	Progress    float64      `json:"progress"`
+	FilePath    string       `json:"file_path,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	ErrorCode   string       `json:"error_code,omitempty"`
+	ParentID    string       `json:"parent_id,omitempty"`
+	Retries     int          `json:"retries,omitempty"`
+	Attempts    []JobAttempt `json:"attempts,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	Children    []JobStatus  `json:"children,omitempty"`
 }
 
 type ProgressUpdate struct {
-	ID         string  `json:"id"`
-	Progress   float64 `json:"progress"`
-	Speed      string  `json:"speed"`
-	ETA        string  `json:"eta"`
-	Status     string  `json:"status"`
-	Message    string  `json:"message,omitempty"`
+	ID       string  `json:"id"`
+	ParentID string  `json:"parent_id,omitempty"`
+	Progress float64 `json:"progress"`
+	Speed    string  `json:"speed"`
+	ETA      string  `json:"eta"`
+	Status   string  `json:"status"`
+	Message  string  `json:"message,omitempty"`
+	// Code, Retryable and Hint describe why a job failed or stopped.
+	Code      string `json:"code,omitempty"`
+	Retryable bool   `json:"retryable,omitempty"`
+	Hint      string `json:"hint,omitempty"`
+	// Attempt and MaxAttempts count the attempts of a job that is retried
+	// after a failure that may pass; RetryIn is the number of seconds
+	// until the next one while the job is "retrying".
+	Attempt         int     `json:"attempt,omitempty"`
+	MaxAttempts     int     `json:"max_attempts,omitempty"`
+	RetryIn         int     `json:"retry_in,omitempty"`
+	Position        int     `json:"position,omitempty"`
+	Phase           string  `json:"phase,omitempty"`
+	SpeedBytes      float64 `json:"speed_bps,omitempty"`
+	ETASeconds      int     `json:"eta_seconds,omitempty"`
+	DownloadedBytes int64   `json:"downloaded_bytes,omitempty"`
+	TotalBytes      int64   `json:"total_bytes,omitempty"`
+	FragmentIndex   int     `json:"fragment_index,omitempty"`
+	FragmentCount   int     `json:"fragment_count,omitempty"`
+	FileURL         string  `json:"file_url,omitempty"`
+	TotalItems      int     `json:"total_items,omitempty"`
+	CompletedItems  int     `json:"completed_items,omitempty"`
+	FailedItems     int     `json:"failed_items,omitempty"`
+}
+
+// WebSocketMessage is a message to a WebSocket client other than a job
+// update: the snapshot of active jobs or the shutdown notice.
+type WebSocketMessage struct {
+	Type    string      `json:"type"`
+	Message string      `json:"message,omitempty"`
+	Jobs    []JobStatus `json:"jobs,omitempty"`
+}
+
+// WebSocketRequest is a message from a WebSocket client. A "subscribe"
+// request limits the updates to the listed jobs, or opens them to every job
+// when IDs is empty.
+type WebSocketRequest struct {
+	Type string   `json:"type"`
+	IDs  []string `json:"ids"`
 }
 
+// VideoFormat is a recommended choice: the format yt-dlp ranks best for a
+// resolution, frame rate and dynamic range, and the audio format to merge
+// with it when it has no audio of its own. Extension is the merged
+// container and FileSize an estimate of the merged file.
 type VideoFormat struct {
-	FormatID   string `json:"format_id"`
-	Resolution string `json:"resolution"`
-	Extension  string `json:"ext"`
-	FileSize   string `json:"filesize"`
-	Quality    string `json:"quality"`
+	FormatID      string  `json:"format_id"`
+	AudioFormatID string  `json:"audio_format_id,omitempty"`
+	Resolution    string  `json:"resolution"`
+	Extension     string  `json:"ext"`
+	FileSize      string  `json:"filesize"`
+	Quality       string  `json:"quality"`
+	FPS           float64 `json:"fps,omitempty"`
+	DynamicRange  string  `json:"dynamic_range,omitempty"`
+	VCodec        string  `json:"vcodec,omitempty"`
+	ACodec        string  `json:"acodec,omitempty"`
+}
+
+// MediaFormat is one format as the site offers it. Kind is "video",
+// "audio" or "video+audio". Sizes are exact unless FileSizeApprox is set,
+// and bitrates are in kbit/s.
+type MediaFormat struct {
+	FormatID       string  `json:"format_id"`
+	Kind           string  `json:"kind"`
+	Extension      string  `json:"ext"`
+	Note           string  `json:"note,omitempty"`
+	Protocol       string  `json:"protocol,omitempty"`
+	Resolution     string  `json:"resolution,omitempty"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	FPS            float64 `json:"fps,omitempty"`
+	DynamicRange   string  `json:"dynamic_range,omitempty"`
+	VCodec         string  `json:"vcodec,omitempty"`
+	ACodec         string  `json:"acodec,omitempty"`
+	Bitrate        float64 `json:"tbr,omitempty"`
+	VideoBitrate   float64 `json:"vbr,omitempty"`
+	AudioBitrate   float64 `json:"abr,omitempty"`
+	SampleRate     int     `json:"asr,omitempty"`
+	AudioChannels  int     `json:"audio_channels,omitempty"`
+	Language       string  `json:"language,omitempty"`
+	FileSize       string  `json:"filesize,omitempty"`
+	FileSizeBytes  int64   `json:"filesize_bytes,omitempty"`
+	FileSizeApprox bool    `json:"filesize_approx,omitempty"`
 }
 
+// FormatCombination is a video-only format merged with an audio-only one.
+// The size is the sum of the two and always an estimate.
+type FormatCombination struct {
+	VideoFormatID string `json:"video_format_id"`
+	AudioFormatID string `json:"audio_format_id"`
+	Extension     string `json:"ext"`
+	FileSize      string `json:"filesize,omitempty"`
+	FileSizeBytes int64  `json:"filesize_bytes,omitempty"`
+}
+
+// VideoInfo describes a video before it is downloaded. Counts are omitted
+// when the site does not report them, and Tags are the uploader's keywords.
+// AudioTags are the tags an audio extraction of the video would get.
+// Formats is the recommended list; AllFormats has every format the site
+// offers and Combinations every video-only and audio-only pair.
 type VideoInfo struct {
-	Title       string        `json:"title"`
-	Duration    string        `json:"duration"`
-	Thumbnail   string        `json:"thumbnail"`
-	Formats     []VideoFormat `json:"formats"`
-	ParsedURL   string        `json:"parsed_url"`
-}
\ No newline at end of file
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Duration    string          `json:"duration"`
+	Thumbnail   string          `json:"thumbnail"`
+	Extractor   string          `json:"extractor"`
+	Uploader    string          `json:"uploader"`
+	UploaderURL string          `json:"uploader_url,omitempty"`
+	Channel     string          `json:"channel,omitempty"`
+	UploadDate  string          `json:"upload_date,omitempty"`
+	ViewCount   *int64          `json:"view_count,omitempty"`
+	LikeCount   *int64          `json:"like_count,omitempty"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
+	Formats     []VideoFormat   `json:"formats"`
+	Subtitles   []SubtitleTrack `json:"subtitles"`
+	Chapters    []Chapter       `json:"chapters"`
+	ParsedURL   string          `json:"parsed_url"`
+	AudioTags   AudioTags       `json:"audio_tags"`
+
+	AllFormats   []MediaFormat       `json:"all_formats"`
+	Combinations []FormatCombination `json:"combinations"`
+}
+
+// Chapter is one chapter of a video, in seconds from the start.
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// SubtitleTrack is one available subtitle language. Auto marks captions
+// generated by the site rather than uploaded with the video.
+type SubtitleTrack struct {
+	Language string   `json:"language"`
+	Name     string   `json:"name,omitempty"`
+	Formats  []string `json:"formats"`
+	Auto     bool     `json:"auto"`
+}
+
+type PlaylistEntry struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	Duration string `json:"duration,omitempty"`
+}
+
+type PlaylistInfo struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Uploader  string          `json:"uploader,omitempty"`
+	ParsedURL string          `json:"parsed_url"`
+	Entries   []PlaylistEntry `json:"entries"`
+}
+
+type HistoryEntry struct {
+	ID          string       `json:"id"`
+	Type        string       `json:"type"`
+	URL         string       `json:"url"`
+	Title       string       `json:"title"`
+	Quality     string       `json:"quality,omitempty"`
+	FilePath    string       `json:"file_path,omitempty"`
+	Status      string       `json:"status"`
+	Error       string       `json:"error,omitempty"`
+	ErrorCode   string       `json:"error_code,omitempty"`
+	ParentID    string       `json:"parent_id,omitempty"`
+	Retries     int          `json:"retries,omitempty"`
+	Attempts    []JobAttempt `json:"attempts,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
+
+// JobAttempt is an attempt of a job that failed and was retried
+// automatically. Strategies are the alternatives the attempt ran with.
+type JobAttempt struct {
+	Attempt    int       `json:"attempt"`
+	Code       string    `json:"code"`
+	Error      string    `json:"error"`
+	Strategies []string  `json:"strategies,omitempty"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+type HistoryQuery struct {
+	Status string
+	Type   string
+	Search string
+	Limit  int
+	Offset int
+}
+
+type HistoryPage struct {
+	Entries []HistoryEntry `json:"entries"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+type DependencyInfo struct {
+	Name          string   `json:"name"`
+	Found         bool     `json:"found"`
+	Path          string   `json:"path,omitempty"`
+	Source        string   `json:"source,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	ParsedVersion string   `json:"parsed_version,omitempty"`
+	Tried         []string `json:"tried,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+type DiskSpace struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	FreeBytes uint64 `json:"free_bytes"`
+	Free      string `json:"free,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type DiagnosticsReport struct {
+	Status   string         `json:"status"`
+	Checks   []HealthCheck  `json:"checks"`
+	YtDlp    DependencyInfo `json:"yt_dlp"`
+	FFmpeg   DependencyInfo `json:"ffmpeg"`
+	FFprobe  DependencyInfo `json:"ffprobe"`
+	Encoders []string       `json:"encoders"`
+	Disks    []DiskSpace    `json:"disks"`
+}
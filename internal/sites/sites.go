@@ -0,0 +1,177 @@
+// Package sites turns user-supplied URLs into the URLs handed to yt-dlp.
+// Hosts with a registered normalizer are canonicalised; any other http(s)
+// URL passes through unchanged so every yt-dlp extractor stays usable.
+package sites
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Normalizer canonicalises the URLs of one site.
+type Normalizer interface {
+	Matches(host string) bool
+	Video(u *url.URL) (string, error)
+	Playlist(u *url.URL) (string, error)
+	// Args are the yt-dlp options added to every command for the site.
+	Args() []string
+}
+
+// builtIn are the normalizers every registry starts with.
+var builtIn = []Normalizer{youtube{}}
+
+// Registry picks the normalizer for a URL and enforces the site allow and
+// deny lists.
+type Registry struct {
+	normalizers []Normalizer
+	allow       []string
+	deny        []string
+}
+
+// NewRegistry returns a registry with the built-in normalizers. An empty
+// allow list allows every site; the deny list always wins.
+func NewRegistry(allow, deny []string) *Registry {
+	return &Registry{
+		normalizers: append([]Normalizer{}, builtIn...),
+		allow:       cleanSites(allow),
+		deny:        cleanSites(deny),
+	}
+}
+
+// Register adds a normalizer, consulted after the ones already registered.
+func (r *Registry) Register(n Normalizer) {
+	r.normalizers = append(r.normalizers, n)
+}
+
+// NormalizeVideo returns the canonical URL of a single video.
+func (r *Registry) NormalizeVideo(rawURL string) (string, error) {
+	return r.normalize(rawURL, Normalizer.Video)
+}
+
+// NormalizePlaylist returns the canonical URL of a playlist or channel.
+func (r *Registry) NormalizePlaylist(rawURL string) (string, error) {
+	return r.normalize(rawURL, Normalizer.Playlist)
+}
+
+// Args returns the yt-dlp options of the built-in site a URL belongs to, or
+// nil for any other site.
+func Args(rawURL string) []string {
+	parsedURL, err := parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	if n := lookup(builtIn, hostname(parsedURL)); n != nil {
+		return n.Args()
+	}
+	return nil
+}
+
+func (r *Registry) normalize(rawURL string, canonical func(Normalizer, *url.URL) (string, error)) (string, error) {
+	parsedURL, err := parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	result := passThrough(parsedURL)
+	if n := lookup(r.normalizers, hostname(parsedURL)); n != nil {
+		if result, err = canonical(n, parsedURL); err != nil {
+			return "", err
+		}
+	}
+
+	// The lists are checked against the canonical URL so that short links
+	// such as youtu.be are judged as the site they point to.
+	canonicalURL, err := url.Parse(result)
+	if err != nil {
+		return "", fmt.Errorf(consts.ERR_INVALID_URL, err)
+	}
+	if err := r.check(hostname(canonicalURL)); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func (r *Registry) check(host string) error {
+	if matchesAny(host, r.deny) {
+		return fmt.Errorf(consts.ERR_SITE_DENIED, host)
+	}
+	if len(r.allow) > 0 && !matchesAny(host, r.allow) {
+		return fmt.Errorf(consts.ERR_SITE_NOT_ALLOWED, host)
+	}
+	return nil
+}
+
+// lookup returns the first normalizer that matches host, or nil.
+func lookup(normalizers []Normalizer, host string) Normalizer {
+	for _, n := range normalizers {
+		if n.Matches(host) {
+			return n
+		}
+	}
+	return nil
+}
+
+// parse accepts URLs typed without a scheme and rejects anything that is not
+// http or https.
+func parse(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, consts.SCHEME_SEPARATOR) {
+		rawURL = consts.HTTPS_SCHEME + consts.SCHEME_SEPARATOR + rawURL
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf(consts.ERR_INVALID_URL, err)
+	}
+
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	if parsedURL.Scheme != consts.HTTP_SCHEME && parsedURL.Scheme != consts.HTTPS_SCHEME {
+		return nil, fmt.Errorf(consts.ERR_UNSUPPORTED_SCHEME, parsedURL.Scheme)
+	}
+	if parsedURL.Hostname() == "" {
+		return nil, fmt.Errorf(consts.ERR_MISSING_HOST)
+	}
+	return parsedURL, nil
+}
+
+// passThrough is the URL of a site without a normalizer: unchanged apart
+// from the fragment, which never reaches the server.
+func passThrough(parsedURL *url.URL) string {
+	clean := *parsedURL
+	clean.Fragment = ""
+	clean.RawFragment = ""
+	return clean.String()
+}
+
+func hostname(parsedURL *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), consts.SUBDOMAIN_SEPARATOR)
+}
+
+// matchesSite reports whether host is site or one of its subdomains.
+func matchesSite(host, site string) bool {
+	return host == site || strings.HasSuffix(host, consts.SUBDOMAIN_SEPARATOR+site)
+}
+
+func matchesAny(host string, sites []string) bool {
+	for _, site := range sites {
+		if matchesSite(host, site) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanSites lowercases list entries and drops wildcards and blanks, so
+// "*.Example.com" and "example.com" mean the same thing.
+func cleanSites(sites []string) []string {
+	var cleaned []string
+	for _, site := range sites {
+		site = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(site)), consts.WILDCARD_PREFIX)
+		if site != "" {
+			cleaned = append(cleaned, site)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,147 @@
+package sites
+
+import (
+	"Go-Utilities/internal/consts"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeVideo(t *testing.T) {
+	const watch = "https://www.youtube.com/watch?v=abc123"
+
+	tests := []struct {
+		name    string
+		url     string
+		allow   []string
+		deny    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "watch URL", url: "https://www.youtube.com/watch?v=abc123&t=42", want: watch},
+		{name: "without scheme", url: "  youtube.com/watch?v=abc123 ", want: watch},
+		{name: "mobile host", url: "https://m.youtube.com/watch?v=abc123", want: watch},
+		{name: "music host", url: "https://music.youtube.com/watch?v=abc123&list=RD", want: watch},
+		{name: "upper case host", url: "HTTPS://WWW.YOUTUBE.COM./watch?v=abc123", want: watch},
+		{name: "short link", url: "https://youtu.be/abc123?si=share", want: watch},
+		{name: "shorts", url: "https://www.youtube.com/shorts/abc123", want: watch},
+		{name: "live", url: "https://www.youtube.com/live/abc123?feature=share", want: watch},
+		{name: "embed", url: "https://www.youtube-nocookie.com/embed/abc123", want: watch},
+		{name: "no video ID", url: "https://www.youtube.com/feed/trending", wantErr: true},
+		{name: "other site", url: "https://vimeo.com/12345?h=x#t=10", want: "https://vimeo.com/12345?h=x"},
+		{name: "unsupported scheme", url: "ftp://example.com/video", wantErr: true},
+		{name: "missing host", url: "https:///watch?v=abc123", wantErr: true},
+		{name: "allowed site", url: "https://youtu.be/abc123", allow: []string{"*.YouTube.com"}, want: watch},
+		{name: "site not allowed", url: "https://vimeo.com/12345", allow: []string{"youtube.com"}, wantErr: true},
+		{name: "denied short link", url: "https://youtu.be/abc123", deny: []string{"youtube.com"}, wantErr: true},
+		{name: "deny wins over allow", url: "https://vimeo.com/1", allow: []string{"vimeo.com"}, deny: []string{"vimeo.com"}, wantErr: true},
+		{name: "deny matches subdomains only", url: "https://notvimeo.com/1", deny: []string{"vimeo.com"}, want: "https://notvimeo.com/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegistry(tt.allow, tt.deny).NormalizeVideo(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeVideo(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeVideo(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePlaylist(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "playlist", url: "https://www.youtube.com/playlist?list=PL1", want: "https://www.youtube.com/playlist?list=PL1"},
+		{name: "watch URL with list", url: "https://www.youtube.com/watch?v=abc123&list=PL1&index=3", want: "https://www.youtube.com/playlist?list=PL1"},
+		{name: "handle", url: "https://www.youtube.com/@name", want: "https://www.youtube.com/@name/videos"},
+		{name: "handle tab", url: "https://m.youtube.com/@name/shorts", want: "https://www.youtube.com/@name/shorts"},
+		{name: "unknown tab", url: "https://www.youtube.com/channel/UC1/about", want: "https://www.youtube.com/channel/UC1/videos"},
+		{name: "empty channel", url: "https://www.youtube.com/@", wantErr: true},
+		{name: "single video", url: "https://youtu.be/abc123", wantErr: true},
+		{name: "other site", url: "https://soundcloud.com/artist/sets/album", want: "https://soundcloud.com/artist/sets/album"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegistry(nil, nil).NormalizePlaylist(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePlaylist(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePlaylist(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+// example canonicalises every example.com URL to its path on www.
+type example struct{}
+
+func (example) Matches(host string) bool { return matchesSite(host, "example.com") }
+
+func (example) Video(u *url.URL) (string, error) { return "https://www.example.com" + u.Path, nil }
+
+func (example) Playlist(u *url.URL) (string, error) { return "https://www.example.com" + u.Path, nil }
+
+func (example) Args() []string { return []string{"--example"} }
+
+func TestRegistryLookup(t *testing.T) {
+	registry := NewRegistry(nil, nil)
+	registry.Register(example{})
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "built-in site", url: "https://youtu.be/abc123", want: "https://www.youtube.com/watch?v=abc123"},
+		{name: "registered site", url: "https://sub.example.com/clip?x=1", want: "https://www.example.com/clip"},
+		{name: "unregistered site", url: "https://example.org/clip?x=1", want: "https://example.org/clip?x=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.NormalizeVideo(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeVideo(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+
+	if got := NewRegistry(nil, nil).normalizers; len(got) != len(builtIn) {
+		t.Errorf("Register() changed the normalizers of other registries: %v", got)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{name: "YouTube", url: "https://www.youtube.com/watch?v=abc123", want: consts.YT_DLP_YOUTUBE_ARGS},
+		{name: "YouTube short link", url: "youtu.be/abc123", want: consts.YT_DLP_YOUTUBE_ARGS},
+		{name: "YouTube Music", url: "https://music.youtube.com/playlist?list=PL1", want: consts.YT_DLP_YOUTUBE_ARGS},
+		{name: "other site", url: "https://vimeo.com/12345", want: nil},
+		{name: "look-alike host", url: "https://notyoutube.com/watch?v=abc123", want: nil},
+		{name: "invalid URL", url: "ftp://youtube.com/watch?v=abc123", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Args(tt.url); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Args(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
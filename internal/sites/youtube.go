@@ -0,0 +1,90 @@
+package sites
+
+import (
+	"Go-Utilities/internal/consts"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// youtube canonicalises watch, short, live, embed and share links from any
+// YouTube host, including m.youtube.com and music.youtube.com.
+type youtube struct{}
+
+func (youtube) Matches(host string) bool {
+	return matchesAny(host, consts.YOUTUBE_DOMAINS)
+}
+
+func (youtube) Video(parsedURL *url.URL) (string, error) {
+	videoID := extractVideoID(parsedURL)
+	if videoID == "" {
+		return "", fmt.Errorf(consts.ERR_EXTRACT_VIDEO_ID)
+	}
+	return fmt.Sprintf(consts.YOUTUBE_WATCH_URL, videoID), nil
+}
+
+// Playlist resolves watch URLs that carry a list parameter to the playlist,
+// and bare channel URLs to their videos tab.
+func (youtube) Playlist(parsedURL *url.URL) (string, error) {
+	if listID := parsedURL.Query().Get(consts.URL_LIST_PARAM); listID != "" {
+		return fmt.Sprintf(consts.YOUTUBE_PLAYLIST_URL, listID), nil
+	}
+
+	if channel, tab := splitChannelPath(parsedURL.Path); channel != "" {
+		return fmt.Sprintf(consts.YOUTUBE_CHANNEL_URL, channel, tab), nil
+	}
+
+	return "", fmt.Errorf(consts.ERR_NOT_PLAYLIST_URL)
+}
+
+func (youtube) Args() []string {
+	return consts.YT_DLP_YOUTUBE_ARGS
+}
+
+func extractVideoID(parsedURL *url.URL) string {
+	if matchesSite(hostname(parsedURL), consts.YOUTU_BE_DOMAIN) {
+		return firstSegment(strings.TrimPrefix(parsedURL.Path, consts.URL_PATH_SEPARATOR))
+	}
+
+	if videoID := parsedURL.Query().Get(consts.URL_VIDEO_PARAM); videoID != "" {
+		return videoID
+	}
+
+	for _, prefix := range consts.YOUTUBE_VIDEO_PATHS {
+		if strings.HasPrefix(parsedURL.Path, prefix) {
+			return firstSegment(strings.TrimPrefix(parsedURL.Path, prefix))
+		}
+	}
+	return ""
+}
+
+func firstSegment(path string) string {
+	return strings.Split(path, consts.URL_PATH_SEPARATOR)[0]
+}
+
+// splitChannelPath splits a channel page path into the channel itself and a
+// video tab, e.g. "/@name/shorts" into "/@name" and "shorts".
+func splitChannelPath(path string) (string, string) {
+	for _, prefix := range consts.YOUTUBE_CHANNEL_PREFIXES {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+
+		rest := strings.Split(strings.TrimPrefix(path, prefix), consts.URL_PATH_SEPARATOR)
+		if rest[0] == "" {
+			return "", ""
+		}
+
+		channel := prefix + rest[0]
+		tab := consts.DEFAULT_CHANNEL_TAB
+		if len(rest) > 1 {
+			for _, known := range consts.YOUTUBE_CHANNEL_TABS {
+				if rest[1] == known {
+					tab = known
+				}
+			}
+		}
+		return channel, tab
+	}
+	return "", ""
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"Go-Utilities/internal/config"
 	"Go-Utilities/internal/consts"
 	"Go-Utilities/internal/handlers"
 	"context"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -14,13 +18,23 @@ import (
 )
 
 func main() {
-	router := handlers.SetupRoutes()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	router, err := handlers.SetupRoutes(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	port := consts.DEFAULT_PORT
-	url := consts.BASE_URL + port
+	url := browserURL(cfg.Server.Address)
 
 	server := &http.Server{
-		Addr:    port,
+		Addr:    cfg.Server.Address,
 		Handler: router,
 	}
 
@@ -33,11 +47,25 @@ func main() {
 
 	time.Sleep(100 * time.Millisecond)
 
-	openBrowser(url)
+	if cfg.Server.OpenBrowser {
+		openBrowser(url)
+	}
 
 	setupGracefulShutdown(server)
 }
 
+// browserURL turns the listen address into a URL the local browser can open.
+func browserURL(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return consts.BASE_URL + address
+	}
+	if host == "" || host == consts.ANY_IPV4_HOST || host == consts.ANY_IPV6_HOST {
+		return consts.BASE_URL + ":" + port
+	}
+	return consts.HTTP_SCHEME_PREFIX + net.JoinHostPort(host, port)
+}
+
 func openBrowser(url string) {
 	err := exec.Command("cmd", "/c", "start", url).Start()
 	
@@ -111,6 +139,7 @@ func setupGracefulShutdown(server *http.Server) {
 	log.Printf(consts.LOG_RECEIVED_SIGNAL, sig)
 
 	handlers.SendShutdownSignal()
+	handlers.Shutdown()
 
 	time.Sleep(1 * time.Second)
 
@@ -1,6 +1,10 @@
-import { initVideoDownloader, hideProgress, isValidYouTubeURL, getCurrentVideoInfo } from './video_downloader.js';
+import { initVideoDownloader, hideProgress, isValidMediaURL, getCurrentVideoInfo } from './video_downloader.js';
 import { initAudioConverter, hideMp3Progress, handleMp3ProgressUpdate } from './audio_converter.js';
 import { initJsonFormatter } from './json_formatter.js';
+import { initHistory, refreshHistory } from './history.js';
+import { initHealthBanner } from './health.js';
+import { initInterruptedBanner } from './interrupted.js';
+import { initPlaylist, handlePlaylistItemUpdate, handlePlaylistProgressUpdate } from './playlist.js';
 import { 
     LOG_MESSAGES, 
     ERROR_MESSAGES, 
@@ -13,7 +17,8 @@ import {
     WS_MESSAGE_TYPES, 
     DOWNLOAD_STATUS, 
     TIMEOUTS, 
-    SHUTDOWN_MESSAGES 
+    SHUTDOWN_MESSAGES,
+    AUDIO_CONFIG
 } from './constants.js';
 
 const API_BASE = API_ENDPOINTS.BASE;
@@ -27,8 +32,12 @@ document.addEventListener('DOMContentLoaded', () => {
     initWebSocket();
     initMenuSystem();
     initVideoDownloader();
+    initPlaylist();
     initAudioConverter();
     initJsonFormatter();
+    initHistory();
+    initHealthBanner();
+    initInterruptedBanner();
     
     function initMenuSystem() {
         const menuButtons = document.querySelectorAll('.menu-btn');
@@ -49,14 +58,17 @@ document.addEventListener('DOMContentLoaded', () => {
                     }
                 });
                 
-                if (currentDownloadId && targetApp !== 'youtube-video') {
+                if (targetApp === 'history') {
+                    refreshHistory();
+                } else if (currentDownloadId && targetApp !== 'youtube-video') {
                     cancelCurrentDownload();
                 }
                 
                 const titles = {
                     'youtube-video': APP_TITLES.YOUTUBE_VIDEO,
                     'youtube-mp3': APP_TITLES.YOUTUBE_MP3, 
-                    'json-formatter': APP_TITLES.JSON_FORMATTER
+                    'json-formatter': APP_TITLES.JSON_FORMATTER,
+                    'history': APP_TITLES.HISTORY
                 };
                 document.title = titles[targetApp] || APP_TITLES.DEFAULT;
             });
@@ -89,6 +101,11 @@ document.addEventListener('DOMContentLoaded', () => {
                 return;
             }
             
+            if (update.type === WS_MESSAGE_TYPES.SNAPSHOT) {
+                handleSnapshot(update.jobs || []);
+                return;
+            }
+            
             handleProgressUpdate(update);
         };
         
@@ -102,12 +119,55 @@ document.addEventListener('DOMContentLoaded', () => {
         };
     }
     
+    // A snapshot of the active jobs arrives on every connect, so the job this
+    // tab follows catches up with whatever happened while it was offline.
+    function handleSnapshot(jobs) {
+        jobs.forEach(job => {
+            if (job.id !== currentDownloadId) return;
+            
+            const toUpdate = item => ({
+                id: item.id,
+                parent_id: item.parent_id,
+                status: item.status,
+                progress: item.progress,
+                message: item.error
+            });
+            
+            if (job.children) {
+                job.children.forEach(child => handlePlaylistItemUpdate(toUpdate(child)));
+                return;
+            }
+            handleProgressUpdate(toUpdate(job));
+        });
+    }
+    
     function handleProgressUpdate(update) {
         console.log(LOG_MESSAGES.HANDLING_PROGRESS_UPDATE, update.id, LOG_MESSAGES.CURRENT_DOWNLOAD, currentDownloadId);
         
+        if ([DOWNLOAD_STATUS.COMPLETED, DOWNLOAD_STATUS.ERROR, DOWNLOAD_STATUS.CANCELLED].includes(update.status)) {
+            refreshHistory();
+        }
+        
+        if (update.parent_id && update.parent_id === currentDownloadId) {
+            if (update.status === DOWNLOAD_STATUS.COMPLETED && update.file_url) {
+                fetchJobFile(update.file_url);
+            }
+            handlePlaylistItemUpdate(update);
+            return;
+        }
+        
         if (update.id !== currentDownloadId) return;
         
-        const isMp3 = update.id.startsWith('mp3_');
+        if (update.status === DOWNLOAD_STATUS.COMPLETED && update.file_url) {
+            fetchJobFile(update.file_url);
+        }
+        
+        if (update.total_items) {
+            handlePlaylistProgressUpdate(update);
+            return;
+        }
+        
+        const isMp3 = update.id.startsWith(AUDIO_CONFIG.JOB_PREFIX);
         
         if (isMp3) {
             handleMp3ProgressUpdate(update);
@@ -116,6 +176,17 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     }
     
+    // Files kept for the browser save target are pulled with a plain link so
+    // the browser's own download handling takes over.
+    function fetchJobFile(fileUrl) {
+        const link = document.createElement('a');
+        link.href = fileUrl;
+        link.download = '';
+        document.body.appendChild(link);
+        link.click();
+        link.remove();
+    }
+    
     function handleVideoProgressUpdate(update) {
         const progressFill = document.querySelector(`#${ELEMENT_IDS.PROGRESS_CONTAINER} ${SELECTORS.PROGRESS_FILL}`);
         const progressPercentage = document.querySelector(`#${ELEMENT_IDS.PROGRESS_CONTAINER} ${SELECTORS.PROGRESS_PERCENTAGE}`);
@@ -136,19 +207,31 @@ document.addEventListener('DOMContentLoaded', () => {
         
         switch (update.status) {
             case DOWNLOAD_STATUS.DOWNLOADING:
-                if (progressText) progressText.textContent = UI_TEXT.DOWNLOADING;
+                if (progressText) progressText.textContent = update.phase ? update.message : UI_TEXT.DOWNLOADING;
                 break;
             case DOWNLOAD_STATUS.PROCESSING:
                 if (progressText) progressText.textContent = UI_TEXT.PROCESSING;
                 break;
+            case DOWNLOAD_STATUS.QUEUED:
+                if (progressText) progressText.textContent = update.message || UI_TEXT.QUEUED;
+                break;
+            case DOWNLOAD_STATUS.PAUSED:
+                if (progressText) progressText.textContent = UI_TEXT.PAUSED;
+                break;
+            case DOWNLOAD_STATUS.RETRYING:
+                if (progressText) progressText.textContent = update.message || UI_TEXT.RETRYING;
+                break;
             case DOWNLOAD_STATUS.COMPLETED:
                 if (progressText) progressText.textContent = UI_TEXT.COMPLETED;
                 setTimeout(() => {
                     hideProgress();
                 }, TIMEOUTS.AUTO_HIDE_PROGRESS);
                 break;
+            case DOWNLOAD_STATUS.CANCELLED:
+                hideProgress();
+                break;
             case DOWNLOAD_STATUS.ERROR:
-                showError(update.message || ERROR_MESSAGES.DOWNLOAD_FAILED);
+                showError(update.message || ERROR_MESSAGES.DOWNLOAD_FAILED, update.hint);
                 hideProgress();
                 break;
         }
@@ -157,7 +240,7 @@ document.addEventListener('DOMContentLoaded', () => {
     function cancelCurrentDownload() {
         if (!currentDownloadId) return;
         
-        const isMp3 = currentDownloadId.startsWith('mp3_');
+        const isMp3 = currentDownloadId.startsWith(AUDIO_CONFIG.JOB_PREFIX);
         
         if (isMp3) {
             document.getElementById(ELEMENT_IDS.MP3_CANCEL_BTN)?.click();
@@ -190,10 +273,18 @@ document.addEventListener('DOMContentLoaded', () => {
         document.body.appendChild(overlay);
     }
     
-    window.showError = function(message) {
+    // showError shows message in a toast, with the server's hint for the
+    // error code below it when there is one.
+    window.showError = function(message, hint) {
         const toast = document.createElement('div');
         toast.className = CSS_CLASSES.ERROR_TOAST;
         toast.textContent = message;
+        if (hint) {
+            const hintLine = document.createElement('div');
+            hintLine.className = CSS_CLASSES.ERROR_HINT;
+            hintLine.textContent = hint;
+            toast.appendChild(hintLine);
+        }
         
         document.body.appendChild(toast);
         
@@ -10,8 +10,11 @@ import {
     HTTP_METHODS, 
     DOWNLOAD_STATUS, 
     TIMEOUTS, 
-    REGEX_PATTERNS 
+    REGEX_PATTERNS,
+    CHAPTER_CONFIG,
+    AUDIO_CONFIG
 } from './constants.js';
+import { renderTagOptions, getTagOverrides } from './tags.js';
 
 const API_BASE = API_ENDPOINTS.BASE;
 
@@ -24,6 +27,9 @@ export function initAudioConverter() {
         return;
     }
     
+    renderAudioOptions();
+    renderTagOptions(document.getElementById(ELEMENT_IDS.AUDIO_TAGS), () => mp3UrlInput.value.trim());
+    
     convertMp3Btn.addEventListener('click', function(e) {
         console.log(LOG_MESSAGES.MP3_BUTTON_CLICKED_DIRECT);
         e.preventDefault();
@@ -48,8 +54,69 @@ export function initAudioConverter() {
     });
 }
 
-export function isValidYouTubeURL(url) {
-    return REGEX_PATTERNS.YOUTUBE_URL.test(url);
+// renderAudioOptions fills the format, quality, sample rate and channel
+// selects. Quality does not apply to lossless formats, and the original
+// stream cannot be resampled or remixed.
+function renderAudioOptions() {
+    const container = document.getElementById(ELEMENT_IDS.AUDIO_OPTIONS);
+    if (!container) return;
+    
+    container.innerHTML = `
+        <label for="${ELEMENT_IDS.AUDIO_FORMAT_SELECT}" class="resolution-label">${UI_TEXT.AUDIO_FORMAT_LABEL}</label>
+        <select id="${ELEMENT_IDS.AUDIO_FORMAT_SELECT}" class="resolution-select">
+            ${AUDIO_CONFIG.FORMATS.map(format => `<option value="${format.value}">${format.label}</option>`).join('')}
+        </select>
+        <label for="${ELEMENT_IDS.AUDIO_QUALITY_SELECT}" class="resolution-label">${UI_TEXT.AUDIO_QUALITY_LABEL}</label>
+        <select id="${ELEMENT_IDS.AUDIO_QUALITY_SELECT}" class="resolution-select">
+            ${AUDIO_CONFIG.QUALITIES.map(quality => `<option value="${quality.value}">${quality.label}</option>`).join('')}
+        </select>
+        <select id="${ELEMENT_IDS.AUDIO_SAMPLE_RATE_SELECT}" class="resolution-select"></select>
+        <select id="${ELEMENT_IDS.AUDIO_CHANNELS_SELECT}" class="resolution-select">
+            <option value="">${UI_TEXT.AUDIO_CHANNELS_ORIGINAL}</option>
+            ${AUDIO_CONFIG.CHANNELS.map(channels => `<option value="${channels.value}">${channels.label}</option>`).join('')}
+        </select>
+    `;
+    
+    const formatSelect = document.getElementById(ELEMENT_IDS.AUDIO_FORMAT_SELECT);
+    formatSelect.addEventListener('change', updateAudioOptions);
+    updateAudioOptions();
+}
+
+function updateAudioOptions() {
+    const format = AUDIO_CONFIG.FORMATS.find(f => f.value === document.getElementById(ELEMENT_IDS.AUDIO_FORMAT_SELECT).value);
+    const sampleRateSelect = document.getElementById(ELEMENT_IDS.AUDIO_SAMPLE_RATE_SELECT);
+    const rates = format.value === 'opus' ? AUDIO_CONFIG.OPUS_SAMPLE_RATES : AUDIO_CONFIG.SAMPLE_RATES;
+    const current = sampleRateSelect.value;
+    
+    sampleRateSelect.innerHTML = `<option value="">${UI_TEXT.AUDIO_SAMPLE_RATE_ORIGINAL}</option>` +
+        rates.map(rate => `<option value="${rate}">${rate}${UI_TEXT.AUDIO_SAMPLE_RATE_SUFFIX}</option>`).join('');
+    if (rates.includes(Number(current))) {
+        sampleRateSelect.value = current;
+    }
+    
+    document.getElementById(ELEMENT_IDS.AUDIO_QUALITY_SELECT).disabled = !!format.lossless;
+    sampleRateSelect.disabled = !!format.original;
+    document.getElementById(ELEMENT_IDS.AUDIO_CHANNELS_SELECT).disabled = !!format.original;
+}
+
+function getAudioOptions() {
+    const format = document.getElementById(ELEMENT_IDS.AUDIO_FORMAT_SELECT);
+    if (!format) return undefined;
+    
+    const value = (id) => {
+        const select = document.getElementById(id);
+        return select.disabled ? '' : select.value;
+    };
+    return {
+        format: format.value,
+        quality: value(ELEMENT_IDS.AUDIO_QUALITY_SELECT),
+        sample_rate: Number(value(ELEMENT_IDS.AUDIO_SAMPLE_RATE_SELECT)) || 0,
+        channels: Number(value(ELEMENT_IDS.AUDIO_CHANNELS_SELECT)) || 0
+    };
+}
+
+export function isValidMediaURL(url) {
+    return REGEX_PATTERNS.MEDIA_URL.test(url);
 }
 
 async function handleMp3Convert() {
@@ -68,12 +135,12 @@ async function handleMp3Convert() {
     
     if (!mp3UrlInput || !mp3UrlInput.value.trim()) {
         console.log(LOG_MESSAGES.NO_URL_ENTERED);
-        window.showError(ERROR_MESSAGES.ENTER_YOUTUBE_URL);
+        window.showError(ERROR_MESSAGES.ENTER_URL);
         return;
     }
     
-    if (!isValidYouTubeURL(mp3UrlInput.value.trim())) {
-        window.showError(ERROR_MESSAGES.ENTER_VALID_YOUTUBE_URL);
+    if (!isValidMediaURL(mp3UrlInput.value.trim())) {
+        window.showError(ERROR_MESSAGES.ENTER_VALID_URL);
         return;
     }
     
@@ -81,13 +148,16 @@ async function handleMp3Convert() {
     convertMp3Btn.disabled = true;
     
     try {
-        const response = await fetch(`${API_BASE}${API_ENDPOINTS.MP3_CONVERT}`, {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.AUDIO_EXTRACT}`, {
             method: HTTP_METHODS.POST,
             headers: {
                 'Content-Type': CONTENT_TYPES.JSON,
             },
             body: JSON.stringify({ 
-                url: mp3UrlInput.value.trim()
+                url: mp3UrlInput.value.trim(),
+                audio: getAudioOptions(),
+                tags: getTagOverrides(),
+                chapters: document.getElementById(ELEMENT_IDS.MP3_SPLIT_CHAPTERS)?.checked ? CHAPTER_CONFIG.MODE_SPLIT : ''
             }),
         });
         
@@ -99,12 +169,12 @@ async function handleMp3Convert() {
             mp3UrlInput.disabled = true;
             mp3UrlInput.style.opacity = '0.5';
         } else {
-            window.showError(data.message || ERROR_MESSAGES.MP3_CONVERSION_FAILED);
+            window.showError(data.message || ERROR_MESSAGES.MP3_CONVERSION_FAILED, data.hint);
         }
     } catch (error) {
         window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
     } finally {
-        convertMp3Btn.innerHTML = UI_TEXT.CONVERT_TO_MP3;
+        convertMp3Btn.innerHTML = UI_TEXT.EXTRACT_AUDIO;
         convertMp3Btn.disabled = false;
     }
 }
@@ -129,12 +199,19 @@ export function hideMp3Progress() {
         mp3UrlInput.style.opacity = '1';
     }
     
+    isMp3Paused = false;
+    const pauseResumeBtn = document.getElementById(ELEMENT_IDS.MP3_PAUSE_RESUME_BTN);
+    if (pauseResumeBtn) {
+        pauseResumeBtn.textContent = UI_TEXT.PAUSE;
+        pauseResumeBtn.className = 'control-btn pause-btn';
+    }
+    
     window.setCurrentDownloadId(null);
 }
 
 async function handleCancelMp3Download() {
     const currentDownloadId = window.getCurrentDownloadId();
-    if (!currentDownloadId || !currentDownloadId.startsWith('mp3_')) return;
+    if (!currentDownloadId || !currentDownloadId.startsWith(AUDIO_CONFIG.JOB_PREFIX)) return;
     
     try {
         const response = await fetch(`${API_BASE}${API_ENDPOINTS.CANCEL}`, {
@@ -159,7 +236,7 @@ let isMp3Paused = false;
 
 async function handlePauseResumeMp3Download() {
     const currentDownloadId = window.getCurrentDownloadId();
-    if (!currentDownloadId || !currentDownloadId.startsWith('mp3_')) return;
+    if (!currentDownloadId || !currentDownloadId.startsWith(AUDIO_CONFIG.JOB_PREFIX)) return;
     
     const pauseResumeBtn = document.getElementById(ELEMENT_IDS.MP3_PAUSE_RESUME_BTN);
     if (!pauseResumeBtn) return;
@@ -214,10 +291,19 @@ export function handleMp3ProgressUpdate(update) {
             if (progressText) progressText.textContent = UI_TEXT.DOWNLOADING;
             break;
         case DOWNLOAD_STATUS.CONVERTING:
-            if (progressText) progressText.textContent = UI_TEXT.CONVERTING_TO_MP3;
+            if (progressText) progressText.textContent = update.phase ? update.message : UI_TEXT.EXTRACTING_AUDIO;
             break;
         case DOWNLOAD_STATUS.PROCESSING:
-            if (progressText) progressText.textContent = UI_TEXT.PROCESSING_MP3;
+            if (progressText) progressText.textContent = UI_TEXT.PROCESSING_AUDIO;
+            break;
+        case DOWNLOAD_STATUS.QUEUED:
+            if (progressText) progressText.textContent = update.message || UI_TEXT.QUEUED;
+            break;
+        case DOWNLOAD_STATUS.PAUSED:
+            if (progressText) progressText.textContent = UI_TEXT.PAUSED;
+            break;
+        case DOWNLOAD_STATUS.RETRYING:
+            if (progressText) progressText.textContent = update.message || UI_TEXT.RETRYING;
             break;
         case DOWNLOAD_STATUS.COMPLETED:
             if (progressText) progressText.textContent = UI_TEXT.COMPLETED;
@@ -225,8 +311,11 @@ export function handleMp3ProgressUpdate(update) {
                 hideMp3Progress();
             }, TIMEOUTS.AUTO_HIDE_PROGRESS);
             break;
+        case DOWNLOAD_STATUS.CANCELLED:
+            hideMp3Progress();
+            break;
         case DOWNLOAD_STATUS.ERROR:
-            window.showError(update.message || ERROR_MESSAGES.MP3_CONVERSION_FAILED);
+            window.showError(update.message || ERROR_MESSAGES.MP3_CONVERSION_FAILED, update.hint);
             hideMp3Progress();
             break;
     }
@@ -0,0 +1,48 @@
+import {
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    CHAPTER_CONFIG
+} from './constants.js';
+
+// renderChapterOptions lists the video's chapters and lets the user keep
+// them as markers or split the download into one file per chapter. Videos
+// without chapters get no picker.
+export function renderChapterOptions(resolutionSection, chapters) {
+    if (!chapters || chapters.length === 0) return;
+
+    const options = document.createElement('div');
+    options.className = CSS_CLASSES.CHAPTER_OPTIONS;
+    options.innerHTML = `
+        <label for="${ELEMENT_IDS.CHAPTER_MODE}" class="resolution-label">${UI_TEXT.CHAPTERS_LABEL} (${chapters.length})</label>
+        <select id="${ELEMENT_IDS.CHAPTER_MODE}" class="resolution-select">
+            <option value="${CHAPTER_CONFIG.MODE_EMBED}">${UI_TEXT.CHAPTERS_EMBED}</option>
+            <option value="${CHAPTER_CONFIG.MODE_SPLIT}">${UI_TEXT.CHAPTERS_SPLIT}</option>
+        </select>
+    `;
+
+    const list = document.createElement('ol');
+    list.className = CSS_CLASSES.CHAPTER_LIST;
+    chapters.forEach(chapter => {
+        const item = document.createElement('li');
+        item.textContent = `${formatChapterTime(chapter.start)} ${chapter.title}`;
+        list.appendChild(item);
+    });
+    options.appendChild(list);
+
+    const confirmDownloadBtn = document.getElementById(ELEMENT_IDS.CONFIRM_DOWNLOAD_BTN);
+    resolutionSection.insertBefore(options, confirmDownloadBtn);
+}
+
+export function getChapterMode() {
+    const chapterMode = document.getElementById(ELEMENT_IDS.CHAPTER_MODE);
+    return chapterMode ? chapterMode.value : '';
+}
+
+function formatChapterTime(seconds) {
+    const total = Math.floor(seconds);
+    const hours = Math.floor(total / 3600);
+    const minutes = String(Math.floor(total % 3600 / 60)).padStart(2, '0');
+    const secs = String(total % 60).padStart(2, '0');
+    return hours > 0 ? `${hours}:${minutes}:${secs}` : `${minutes}:${secs}`;
+}
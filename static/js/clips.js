@@ -0,0 +1,68 @@
+import {
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    CLIP_CONFIG
+} from './constants.js';
+
+// renderClipOptions adds the clip range and chapter inputs to the resolution
+// section, before the download button. Leaving them empty downloads the
+// whole video.
+export function renderClipOptions(resolutionSection) {
+    const options = document.createElement('div');
+    options.className = CSS_CLASSES.CLIP_OPTIONS;
+    options.innerHTML = `
+        <label class="resolution-label">${UI_TEXT.CLIP_LABEL}</label>
+        <div id="${ELEMENT_IDS.CLIP_RANGES}"></div>
+        <button id="${ELEMENT_IDS.ADD_CLIP_RANGE_BTN}" class="control-btn pause-btn">${UI_TEXT.CLIP_ADD_RANGE}</button>
+        <input type="text" id="${ELEMENT_IDS.CLIP_CHAPTERS}" class="resolution-select" placeholder="${UI_TEXT.CLIP_CHAPTERS_PLACEHOLDER}">
+        <label class="${CSS_CLASSES.CHECKBOX_OPTION}">
+            <input type="checkbox" id="${ELEMENT_IDS.CLIP_ACCURATE}">
+            ${UI_TEXT.CLIP_ACCURATE}
+        </label>
+    `;
+
+    const confirmDownloadBtn = document.getElementById(ELEMENT_IDS.CONFIRM_DOWNLOAD_BTN);
+    resolutionSection.insertBefore(options, confirmDownloadBtn);
+    addClipRange();
+    document.getElementById(ELEMENT_IDS.ADD_CLIP_RANGE_BTN).addEventListener('click', addClipRange);
+}
+
+function addClipRange() {
+    const ranges = document.getElementById(ELEMENT_IDS.CLIP_RANGES);
+    if (ranges.children.length >= CLIP_CONFIG.MAX_RANGES) return;
+
+    const row = document.createElement('div');
+    row.className = CSS_CLASSES.CLIP_RANGE;
+    row.innerHTML = `
+        <input type="text" class="resolution-select ${CSS_CLASSES.CLIP_START}" placeholder="${UI_TEXT.CLIP_START_PLACEHOLDER}">
+        <input type="text" class="resolution-select ${CSS_CLASSES.CLIP_END}" placeholder="${UI_TEXT.CLIP_END_PLACEHOLDER}">
+    `;
+    ranges.appendChild(row);
+}
+
+// getClipOptions returns the clip part of a download request, or null when
+// no range or chapter was entered.
+export function getClipOptions() {
+    const ranges = document.getElementById(ELEMENT_IDS.CLIP_RANGES);
+    if (!ranges) return null;
+
+    const clipRanges = [...ranges.children]
+        .map(row => ({
+            start: row.querySelector(`.${CSS_CLASSES.CLIP_START}`).value.trim(),
+            end: row.querySelector(`.${CSS_CLASSES.CLIP_END}`).value.trim()
+        }))
+        .filter(range => range.start || range.end);
+    const chapters = document.getElementById(ELEMENT_IDS.CLIP_CHAPTERS).value
+        .split(CLIP_CONFIG.CHAPTER_SEPARATOR)
+        .map(chapter => chapter.trim())
+        .filter(Boolean);
+
+    if (clipRanges.length === 0 && chapters.length === 0) return null;
+
+    return {
+        ranges: clipRanges,
+        chapters,
+        accurate: document.getElementById(ELEMENT_IDS.CLIP_ACCURATE).checked
+    };
+}
@@ -40,13 +40,18 @@ export const LOG_MESSAGES = {
     FAILED_PAUSE_RESUME_MP3: 'Failed to pause/resume MP3 conversion:',
     
     MP3_CONVERTER_ELEMENTS_NOT_FOUND: 'MP3 converter elements not found',
+    HISTORY_ELEMENTS_NOT_FOUND: 'History elements not found',
+    FAILED_LOAD_HISTORY: 'Failed to load history:',
+    FAILED_HEALTH_CHECK: 'Failed to check dependency health:',
+    FAILED_LOAD_INTERRUPTED: 'Failed to load interrupted jobs:',
+    FAILED_PLAYLIST_ACTION: 'Failed to update playlist item:',
     JSON_FORMATTER_ELEMENTS_NOT_FOUND: 'JSON formatter elements not found'
 };
 
 // ---------- ERROR MESSAGES --------------
 export const ERROR_MESSAGES = {
-    ENTER_YOUTUBE_URL: 'Please enter a YouTube URL',
-    ENTER_VALID_YOUTUBE_URL: 'Please enter a valid YouTube URL',
+    ENTER_URL: 'Please enter a video URL',
+    ENTER_VALID_URL: 'Please enter a valid http(s) video URL',
     SELECT_RESOLUTION: 'Please select a resolution',
     VIDEO_INFO_NOT_AVAILABLE: 'Video information not available',
     CONNECTION_ERROR: 'Connection error. Please try again.',
@@ -59,6 +64,12 @@ export const ERROR_MESSAGES = {
     FAILED_CANCEL_MP3_CONVERSION: 'Failed to cancel MP3 conversion',
     FAILED_PAUSE_RESUME_MP3: 'Failed to pause/resume MP3 conversion',
     FAILED_FETCH_VIDEO_INFO: 'Failed to fetch video information',
+    FAILED_LOAD_HISTORY: 'Failed to load download history',
+    FAILED_FETCH_PLAYLIST: 'Failed to fetch playlist entries',
+    SELECT_PLAYLIST_ENTRIES: 'Select at least one video',
+    PLAYLIST_FAILED: 'Playlist download failed',
+    FAILED_PLAYLIST_ACTION: 'Failed to update playlist item',
+    SELECT_SUBTITLE_LANGUAGE: 'Select at least one subtitle language',
     NO_INPUT_TO_COPY: 'No input to copy',
     NO_OUTPUT_TO_COPY: 'No output to copy',
     NO_JSON_CONTENT_TO_DOWNLOAD: 'No JSON content to download',
@@ -76,22 +87,67 @@ export const SUCCESS_MESSAGES = {
 export const UI_TEXT = {
     STARTING: 'STARTING...',
     START_DOWNLOAD: 'START DOWNLOAD',
-    CONVERT_TO_MP3: 'CONVERT TO MP3',
+    EXTRACT_AUDIO: 'EXTRACT AUDIO',
     PAUSE: 'PAUSE',
     RESUME: 'RESUME',
     CANCEL: 'CANCEL',
     
     DOWNLOADING: 'Downloading...',
-    CONVERTING_TO_MP3: 'Converting to MP3...',
-    PROCESSING_MP3: 'Processing MP3...',
+    EXTRACTING_AUDIO: 'Extracting audio...',
+    PROCESSING_AUDIO: 'Processing audio...',
     PROCESSING: 'Processing...',
     CONVERTING: 'Converting...',
     COMPLETED: 'Completed!',
     PAUSED: 'Paused',
+    QUEUED: 'Queued...',
+    RETRYING: 'Retrying...',
     
     FETCHING_VIDEO_INFO: 'Fetching video information...',
     SELECT_RESOLUTION: 'Select resolution...',
     SELECT_RESOLUTION_LABEL: 'Select Resolution:',
+    RECOMMENDED_FORMATS: 'Recommended',
+    ALL_FORMATS: 'All video formats',
+    BEST_MATCH_FORMATS: 'Best match for preferences',
+    BEST_MATCH: 'Best available',
+    BEST_MATCH_UP_TO: 'Up to ',
+    PREFERENCES_LABEL: 'Format preferences:',
+    MAX_FILESIZE_PLACEHOLDER: 'Max file size, e.g. 500M',
+    EXCLUDE_HDR: 'No HDR',
+    
+    FETCHING_PLAYLIST: 'Fetching playlist entries...',
+    LOAD_PLAYLIST: 'LOAD WHOLE PLAYLIST',
+    DOWNLOAD_SELECTED: 'DOWNLOAD SELECTED',
+    SELECT_ALL: 'Select all',
+    PLAYLIST_VIDEOS_SUFFIX: ' videos',
+    PLAYLIST_TYPE_LABEL: 'Download as:',
+    PLAYLIST_TYPE_VIDEO: 'Video',
+    PLAYLIST_TYPE_AUDIO: 'Audio (MP3)',
+    RETRY: 'RETRY',
+    RETRY_FAILED: 'RETRY FAILED',
+    CLOSE: 'CLOSE',
+    SUBTITLES_LABEL: 'Subtitles:',
+    SUBTITLES_UPLOADED: 'Subtitles',
+    SUBTITLES_AUTO: 'Auto-generated',
+    SUBTITLES_EMBED: 'Embed in video',
+    SUBTITLES_SIDECAR: 'Save as separate files',
+    SUBTITLES_ORIGINAL_FORMAT: 'Original format',
+    SUBTITLES_ONLY: 'SUBTITLES ONLY',
+    CLIP_LABEL: 'Clip (optional):',
+    CLIP_ADD_RANGE: '+ ADD RANGE',
+    CLIP_START_PLACEHOLDER: 'Start, e.g. 1:30',
+    CLIP_END_PLACEHOLDER: 'End, e.g. 2:45 (empty = to the end)',
+    CLIP_CHAPTERS_PLACEHOLDER: 'Chapter names, comma separated',
+    CLIP_ACCURATE: 'Exact cuts (slower, re-encodes)',
+    CHAPTERS_LABEL: 'Chapters',
+    CHAPTERS_EMBED: 'Keep as chapter markers',
+    CHAPTERS_SPLIT: 'Save one file per chapter',
+    AUDIO_FORMAT_LABEL: 'Format:',
+    AUDIO_QUALITY_LABEL: 'Quality:',
+    AUDIO_SAMPLE_RATE_ORIGINAL: 'Original sample rate',
+    AUDIO_CHANNELS_ORIGINAL: 'Original channels',
+    AUDIO_SAMPLE_RATE_SUFFIX: ' Hz',
+    TAGS_LABEL: 'Tags (empty fields are filled in from the video):',
+    LOAD_TAGS: 'LOAD TAGS',
     
     FORMATTED_JSON_PLACEHOLDER: 'Formatted JSON will appear here...',
     READY_TO_FORMAT: 'Ready to format',
@@ -105,6 +161,18 @@ export const UI_TEXT = {
     CHARACTERS_SUFFIX: ' characters',
     ZERO_CHARACTERS: '0 characters',
     
+    HISTORY_EMPTY: 'No downloads yet',
+    HISTORY_DOWNLOAD_FILE: 'Download file',
+    HISTORY_ATTEMPTS: 'attempts',
+    HEALTH_FAIL_TITLE: 'Some required tools are missing. Downloads will not work until this is fixed.',
+    HEALTH_WARN_TITLE: 'Some checks reported warnings.',
+    HEALTH_DIAGNOSTICS_LINK: 'Full diagnostics',
+    DISMISS: 'Dismiss',
+    INTERRUPTED_TITLE: 'downloads were interrupted by the last shutdown.',
+    INTERRUPTED_TITLE_ONE: '1 download was interrupted by the last shutdown.',
+    INTERRUPTED_RESUME_ALL: 'Resume all',
+    INTERRUPTED_DISCARD_ALL: 'Discard all',
+    
     ETA_PREFIX: 'ETA: ',
     ETA_PLACEHOLDER: 'ETA: --:--',
     SPEED_PLACEHOLDER: '0 MB/s'
@@ -113,8 +181,9 @@ export const UI_TEXT = {
 // ---------- APP TITLES --------------
 export const APP_TITLES = {
     YOUTUBE_VIDEO: 'YouTube Video Downloader',
-    YOUTUBE_MP3: 'YouTube Video to MP3 Downloader',
+    YOUTUBE_MP3: 'Audio Extractor',
     JSON_FORMATTER: 'JSON Formatter',
+    HISTORY: 'Download History',
     DEFAULT: 'Go Utilities'
 };
 
@@ -122,6 +191,7 @@ export const APP_TITLES = {
 export const CSS_CLASSES = {
     HIDDEN: 'hidden',
     ERROR_TOAST: 'error-toast',
+    ERROR_HINT: 'error-hint',
     CONTROL_BTN: 'control-btn',
     PAUSE_BTN: 'pause-btn',
     RESUME_BTN: 'resume-btn',
@@ -139,7 +209,42 @@ export const CSS_CLASSES = {
     SHUTDOWN_OVERLAY: 'shutdown-overlay',
     SHUTDOWN_MESSAGE: 'shutdown-message',
     SHUTDOWN_TITLE: 'shutdown-title',
-    SHUTDOWN_TEXT: 'shutdown-text'
+    SHUTDOWN_TEXT: 'shutdown-text',
+    HISTORY_ITEM: 'history-item',
+    HISTORY_TITLE: 'history-title',
+    HISTORY_META: 'history-meta',
+    HISTORY_STATUS: 'history-status',
+    HISTORY_ERROR: 'history-error',
+    HISTORY_PATH: 'history-path',
+    HISTORY_EMPTY: 'history-empty',
+    HEALTH_BANNER_FAIL: 'health-banner-fail',
+    HEALTH_BANNER_WARN: 'health-banner-warn',
+    HEALTH_BANNER_TITLE: 'health-banner-title',
+    HEALTH_BANNER_LIST: 'health-banner-list',
+    HEALTH_BANNER_ACTIONS: 'health-banner-actions',
+    INTERRUPTED_BANNER: 'interrupted-banner',
+    PLAYLIST_HEADER: 'playlist-header',
+    PLAYLIST_ENTRIES: 'playlist-entries',
+    PLAYLIST_ENTRY: 'playlist-entry',
+    PLAYLIST_DURATION: 'playlist-duration',
+    PLAYLIST_OPTIONS: 'playlist-options',
+    PLAYLIST_ITEM: 'playlist-item',
+    PLAYLIST_ITEM_TITLE: 'playlist-item-title',
+    PLAYLIST_ITEM_STATUS: 'playlist-item-status',
+    PLAYLIST_ITEM_STATUS_PREFIX: 'history-status-',
+    PLAYLIST_ACTIONS: 'playlist-actions',
+    SUBTITLE_OPTIONS: 'subtitle-options',
+    SUBTITLE_CONTROLS: 'subtitle-controls',
+    PREFERENCE_OPTIONS: 'preference-options',
+    PREFERENCE_CONTROLS: 'preference-controls',
+    CLIP_OPTIONS: 'clip-options',
+    CLIP_RANGE: 'clip-range',
+    CLIP_START: 'clip-start',
+    CLIP_END: 'clip-end',
+    CHECKBOX_OPTION: 'checkbox-option',
+    CHAPTER_OPTIONS: 'chapter-options',
+    CHAPTER_LIST: 'chapter-list',
+    CANCEL_BTN: 'cancel-btn'
 };
 
 // ---------- HTML ELEMENT IDS --------------
@@ -162,7 +267,42 @@ export const ELEMENT_IDS = {
     INPUT_STATS: 'inputStats',
     OUTPUT_STATS: 'outputStats',
     INPUT_CHARS: 'inputChars',
-    OUTPUT_CHARS: 'outputChars'
+    OUTPUT_CHARS: 'outputChars',
+    HISTORY_LIST: 'historyList',
+    HISTORY_STATUS_FILTER: 'historyStatusFilter',
+    HISTORY_SEARCH: 'historySearch',
+    HISTORY_LOAD_MORE_BTN: 'historyLoadMoreBtn',
+    HEALTH_BANNER: 'healthBanner',
+    INTERRUPTED_BANNER: 'interruptedBanner',
+    PLAYLIST_ITEMS: 'playlistItems',
+    PLAYLIST_SELECT_ALL: 'playlistSelectAll',
+    PLAYLIST_TYPE_SELECT: 'playlistTypeSelect',
+    PLAYLIST_QUALITY_SELECT: 'playlistQualitySelect',
+    PLAYLIST_DOWNLOAD_BTN: 'playlistDownloadBtn',
+    LOAD_PLAYLIST_BTN: 'loadPlaylistBtn',
+    SUBTITLE_LANGUAGES: 'subtitleLanguages',
+    SUBTITLE_MODE: 'subtitleMode',
+    SUBTITLE_FORMAT: 'subtitleFormat',
+    CONTAINER_SELECT: 'containerSelect',
+    CODEC_ORDER_SELECT: 'codecOrderSelect',
+    MAX_FPS_SELECT: 'maxFpsSelect',
+    MAX_FILESIZE_INPUT: 'maxFilesizeInput',
+    EXCLUDE_HDR: 'excludeHdr',
+    SUBTITLES_ONLY_BTN: 'subtitlesOnlyBtn',
+    CLIP_RANGES: 'clipRanges',
+    ADD_CLIP_RANGE_BTN: 'addClipRangeBtn',
+    CLIP_CHAPTERS: 'clipChapters',
+    CLIP_ACCURATE: 'clipAccurate',
+    CHAPTER_MODE: 'chapterMode',
+    MP3_SPLIT_CHAPTERS: 'mp3SplitChapters',
+    AUDIO_OPTIONS: 'audioOptions',
+    AUDIO_FORMAT_SELECT: 'audioFormatSelect',
+    AUDIO_QUALITY_SELECT: 'audioQualitySelect',
+    AUDIO_SAMPLE_RATE_SELECT: 'audioSampleRateSelect',
+    AUDIO_CHANNELS_SELECT: 'audioChannelsSelect',
+    AUDIO_TAGS: 'audioTags',
+    AUDIO_TAG_PREFIX: 'audioTag-',
+    LOAD_TAGS_BTN: 'loadTagsBtn'
 };
 
 // ---------- CSS SELECTORS --------------
@@ -181,10 +321,20 @@ export const API_ENDPOINTS = {
     BASE: '/api',
     VIDEO_INFO: '/video-info',
     DOWNLOAD: '/download',
-    MP3_CONVERT: '/mp3-convert',
+    AUDIO_EXTRACT: '/audio-extract',
     CANCEL: '/cancel',
     PAUSE: '/pause',
     RESUME: '/resume',
+    RETRY: '/retry',
+    PLAYLIST_INFO: '/playlist-info',
+    PLAYLIST_DOWNLOAD: '/playlist-download',
+    SUBTITLES: '/subtitles',
+    HISTORY: '/history',
+    HEALTH: '/health',
+    DIAGNOSTICS: '/diagnostics',
+    INTERRUPTED: '/interrupted',
+    INTERRUPTED_RESUME: '/interrupted/resume',
+    INTERRUPTED_DISCARD: '/interrupted/discard',
     WEBSOCKET: '/ws'
 };
 
@@ -201,16 +351,134 @@ export const CONTENT_TYPES = {
 
 // ---------- DOWNLOAD STATUS --------------
 export const DOWNLOAD_STATUS = {
+    QUEUED: 'queued',
     DOWNLOADING: 'downloading',
     CONVERTING: 'converting',
     PROCESSING: 'processing',
     COMPLETED: 'completed',
+    PAUSED: 'paused',
+    RETRYING: 'retrying',
+    INTERRUPTED: 'interrupted',
+    CANCELLED: 'cancelled',
     ERROR: 'error'
 };
 
+// ---------- JOB TYPES --------------
+export const JOB_TYPES = {
+    VIDEO: 'video',
+    AUDIO: 'audio',
+    SUBTITLES: 'subtitles'
+};
+
+// ---------- PLAYLIST --------------
+export const PLAYLIST_CONFIG = {
+    QUALITIES: ['best', '1080p', '720p', '480p', '360p'],
+    ACTION_CANCEL: 'cancel',
+    ACTION_RETRY: 'retry',
+    ACTION_RETRY_FAILED: 'retry-failed',
+    ACTION_CLOSE: 'close'
+};
+
+// ---------- SUBTITLES --------------
+export const SUBTITLE_CONFIG = {
+    FORMATS: ['srt', 'vtt', 'ass'],
+    MODE_EMBED: 'embed',
+    MODE_SIDECAR: 'sidecar',
+    // Auto-generated tracks share language codes with uploaded ones, so
+    // their option values are prefixed to tell them apart.
+    AUTO_PREFIX: 'auto:',
+    LIST_SIZE: 4
+};
+
+// ---------- CLIPS --------------
+export const CLIP_CONFIG = {
+    MAX_RANGES: 10,
+    CHAPTER_SEPARATOR: ','
+};
+
+// ---------- CHAPTERS --------------
+export const CHAPTER_CONFIG = {
+    MODE_EMBED: 'embed',
+    MODE_SPLIT: 'split'
+};
+
+// ---------- VIDEO FORMATS --------------
+export const FORMAT_CONFIG = {
+    KIND_AUDIO: 'audio',
+    RESOLUTION_UNKNOWN: 'unknown',
+    CODEC_NONE: 'none',
+    // Codec strings such as "avc1.640028" are shown up to the first dot.
+    CODEC_SEPARATOR: '.',
+    CONTAINERS: ['mp4', 'mkv', 'webm'],
+    BEST_QUALITY: 'best',
+    CODEC_ORDERS: [
+        { label: 'Any codec', codecs: [] },
+        { label: 'H.264 first (most compatible)', codecs: ['h264', 'vp9', 'av1'] },
+        { label: 'VP9 first', codecs: ['vp9', 'av1', 'h264'] },
+        { label: 'AV1 first (smallest)', codecs: ['av1', 'vp9', 'h264'] }
+    ],
+    MAX_FPS: [
+        { value: '', label: 'Any frame rate' },
+        { value: '30', label: 'Up to 30 fps' },
+        { value: '60', label: 'Up to 60 fps' }
+    ]
+};
+
+// ---------- AUDIO EXTRACTION --------------
+export const AUDIO_CONFIG = {
+    // Audio job IDs start with this, which tells their updates apart.
+    JOB_PREFIX: 'audio_',
+    FORMATS: [
+        { value: 'mp3', label: 'MP3' },
+        { value: 'm4a', label: 'AAC (M4A)' },
+        { value: 'opus', label: 'Opus' },
+        { value: 'ogg', label: 'OGG Vorbis' },
+        { value: 'flac', label: 'FLAC (lossless)', lossless: true },
+        { value: 'wav', label: 'WAV (lossless)', lossless: true },
+        { value: 'original', label: 'Original (no re-encoding)', lossless: true, original: true }
+    ],
+    QUALITIES: [
+        { value: '0', label: 'Best VBR' },
+        { value: '2', label: 'High VBR' },
+        { value: '5', label: 'Medium VBR' },
+        { value: '320K', label: '320 kbps' },
+        { value: '256K', label: '256 kbps' },
+        { value: '192K', label: '192 kbps' },
+        { value: '128K', label: '128 kbps' },
+        { value: '96K', label: '96 kbps' }
+    ],
+    SAMPLE_RATES: [48000, 44100, 24000, 16000],
+    OPUS_SAMPLE_RATES: [48000, 24000, 16000],
+    CHANNELS: [
+        { value: '2', label: 'Stereo' },
+        { value: '1', label: 'Mono' }
+    ],
+    TAG_FIELDS: [
+        { key: 'title', label: 'Title' },
+        { key: 'artist', label: 'Artist' },
+        { key: 'album', label: 'Album' },
+        { key: 'year', label: 'Year' },
+        { key: 'track', label: 'Track, e.g. 3 or 3/12' }
+    ]
+};
+
+// ---------- HEALTH CHECK STATUS --------------
+export const HEALTH_STATUS = {
+    PASS: 'pass',
+    WARN: 'warn',
+    FAIL: 'fail'
+};
+
+// ---------- HISTORY --------------
+export const HISTORY_CONFIG = {
+    PAGE_SIZE: 20
+};
+
 // ---------- WEBSOCKET MESSAGE TYPES --------------
 export const WS_MESSAGE_TYPES = {
-    SHUTDOWN: 'shutdown'
+    SHUTDOWN: 'shutdown',
+    SNAPSHOT: 'snapshot',
+    SUBSCRIBE: 'subscribe'
 };
 
 // ---------- TIMEOUTS AND DELAYS --------------
@@ -253,7 +521,10 @@ export const HTML_COMPONENTS = {
 
 // ---------- REGEX PATTERNS --------------
 export const REGEX_PATTERNS = {
-    YOUTUBE_URL: /^(https?:\/\/)?(www\.)?(youtube\.com\/(watch\?v=|embed\/|v\/)|youtu\.be\/)[\w-]+/,
+    MEDIA_URL: /^(https?:\/\/)?([\w-]+\.)+[a-z]{2,}(:\d+)?(\/\S*)?$/i,
+    YOUTUBE_URL: /^(https?:\/\/)?((www|m|music)\.)?(youtube\.com\/(watch\?v=|embed\/|v\/|shorts\/|live\/)|youtu\.be\/)[\w-]+/,
+    YOUTUBE_PLAYLIST_URL: /^(https?:\/\/)?((www|m)\.)?(youtube\.com|youtu\.be)\/\S*[?&]list=[\w-]+/,
+    YOUTUBE_CHANNEL_URL: /^(https?:\/\/)?((www|m)\.)?youtube\.com\/(@|channel\/|c\/|user\/)[\w.-]+/,
     NUMERIC_VALUE: /^-?\d+(\.\d+)?([eE][+-]?\d+)?$/,
     LEADING_SPACES: /^(\s*)/,
     JSON_KEY_QUOTES: /^"([^"]*)"$/
@@ -0,0 +1,65 @@
+import {
+    LOG_MESSAGES,
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    API_ENDPOINTS,
+    HEALTH_STATUS
+} from './constants.js';
+
+const API_BASE = API_ENDPOINTS.BASE;
+
+export async function initHealthBanner() {
+    try {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.HEALTH}`);
+        const report = await response.json();
+        if (report.status !== HEALTH_STATUS.PASS) {
+            renderHealthBanner(report);
+        }
+    } catch (error) {
+        console.error(LOG_MESSAGES.FAILED_HEALTH_CHECK, error);
+    }
+}
+
+function renderHealthBanner(report) {
+    const banner = document.getElementById(ELEMENT_IDS.HEALTH_BANNER);
+    if (!banner) return;
+
+    const failed = report.status === HEALTH_STATUS.FAIL;
+    banner.innerHTML = '';
+    banner.classList.add(failed ? CSS_CLASSES.HEALTH_BANNER_FAIL : CSS_CLASSES.HEALTH_BANNER_WARN);
+
+    const title = document.createElement('div');
+    title.className = CSS_CLASSES.HEALTH_BANNER_TITLE;
+    title.textContent = failed ? UI_TEXT.HEALTH_FAIL_TITLE : UI_TEXT.HEALTH_WARN_TITLE;
+    banner.appendChild(title);
+
+    const list = document.createElement('ul');
+    list.className = CSS_CLASSES.HEALTH_BANNER_LIST;
+    report.checks
+        .filter(check => check.status !== HEALTH_STATUS.PASS)
+        .forEach(check => {
+            const item = document.createElement('li');
+            item.textContent = `${check.name}: ${check.message}`;
+            list.appendChild(item);
+        });
+    banner.appendChild(list);
+
+    const actions = document.createElement('div');
+    actions.className = CSS_CLASSES.HEALTH_BANNER_ACTIONS;
+
+    const link = document.createElement('a');
+    link.href = `${API_BASE}${API_ENDPOINTS.DIAGNOSTICS}`;
+    link.target = '_blank';
+    link.textContent = UI_TEXT.HEALTH_DIAGNOSTICS_LINK;
+    actions.appendChild(link);
+
+    const dismiss = document.createElement('button');
+    dismiss.className = CSS_CLASSES.CONTROL_BTN;
+    dismiss.textContent = UI_TEXT.DISMISS;
+    dismiss.addEventListener('click', () => banner.classList.add(CSS_CLASSES.HIDDEN));
+    actions.appendChild(dismiss);
+
+    banner.appendChild(actions);
+    banner.classList.remove(CSS_CLASSES.HIDDEN);
+}
@@ -0,0 +1,143 @@
+import {
+    LOG_MESSAGES,
+    ERROR_MESSAGES,
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    API_ENDPOINTS,
+    HISTORY_CONFIG,
+    TIMEOUTS
+} from './constants.js';
+
+const API_BASE = API_ENDPOINTS.BASE;
+let historyEntries = [];
+let historyTotal = 0;
+
+export function initHistory() {
+    const statusFilter = document.getElementById(ELEMENT_IDS.HISTORY_STATUS_FILTER);
+    const searchInput = document.getElementById(ELEMENT_IDS.HISTORY_SEARCH);
+    const loadMoreBtn = document.getElementById(ELEMENT_IDS.HISTORY_LOAD_MORE_BTN);
+
+    if (!statusFilter || !searchInput || !loadMoreBtn) {
+        console.error(LOG_MESSAGES.HISTORY_ELEMENTS_NOT_FOUND);
+        return;
+    }
+
+    statusFilter.addEventListener('change', () => refreshHistory());
+
+    let debounceTimer;
+    searchInput.addEventListener('input', () => {
+        clearTimeout(debounceTimer);
+        debounceTimer = setTimeout(() => refreshHistory(), TIMEOUTS.DEBOUNCE_INPUT);
+    });
+
+    loadMoreBtn.addEventListener('click', () => loadHistory(historyEntries.length));
+
+    refreshHistory();
+}
+
+export function refreshHistory() {
+    historyEntries = [];
+    return loadHistory(0);
+}
+
+async function loadHistory(offset) {
+    const status = document.getElementById(ELEMENT_IDS.HISTORY_STATUS_FILTER)?.value || '';
+    const search = document.getElementById(ELEMENT_IDS.HISTORY_SEARCH)?.value.trim() || '';
+
+    const params = new URLSearchParams({
+        limit: HISTORY_CONFIG.PAGE_SIZE,
+        offset: offset
+    });
+    if (status) params.set('status', status);
+    if (search) params.set('q', search);
+
+    try {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.HISTORY}?${params}`);
+        const data = await response.json();
+
+        if (!response.ok) {
+            window.showError(data.message || ERROR_MESSAGES.FAILED_LOAD_HISTORY, data.hint);
+            return;
+        }
+
+        historyEntries = offset === 0 ? data.entries : historyEntries.concat(data.entries);
+        historyTotal = data.total;
+        renderHistory();
+    } catch (error) {
+        console.error(LOG_MESSAGES.FAILED_LOAD_HISTORY, error);
+    }
+}
+
+function renderHistory() {
+    const list = document.getElementById(ELEMENT_IDS.HISTORY_LIST);
+    const loadMoreBtn = document.getElementById(ELEMENT_IDS.HISTORY_LOAD_MORE_BTN);
+    if (!list) return;
+
+    list.innerHTML = '';
+
+    if (historyEntries.length === 0) {
+        const empty = document.createElement('div');
+        empty.className = CSS_CLASSES.HISTORY_EMPTY;
+        empty.textContent = UI_TEXT.HISTORY_EMPTY;
+        list.appendChild(empty);
+    }
+
+    historyEntries.forEach(entry => {
+        const item = document.createElement('div');
+        item.className = CSS_CLASSES.HISTORY_ITEM;
+
+        const title = document.createElement('div');
+        title.className = CSS_CLASSES.HISTORY_TITLE;
+        title.textContent = entry.title || entry.url;
+        title.title = entry.url;
+
+        const meta = document.createElement('div');
+        meta.className = CSS_CLASSES.HISTORY_META;
+        // Attempts lists the automatic retries; the last attempt is the
+        // entry itself.
+        const attempts = entry.attempts || [];
+        const parts = [
+            new Date(entry.created_at).toLocaleString(),
+            entry.type.toUpperCase(),
+            entry.quality,
+            attempts.length > 0 ? `${attempts.length + 1} ${UI_TEXT.HISTORY_ATTEMPTS}` : ''
+        ].filter(Boolean);
+        meta.textContent = parts.join(' · ');
+        meta.title = attempts.map(attempt => `#${attempt.attempt}: ${attempt.error}`).join('\n');
+
+        const status = document.createElement('span');
+        status.className = `${CSS_CLASSES.HISTORY_STATUS} ${CSS_CLASSES.HISTORY_STATUS}-${entry.status}`;
+        status.textContent = entry.status.toUpperCase();
+        meta.appendChild(status);
+
+        item.appendChild(title);
+        item.appendChild(meta);
+
+        if (entry.error) {
+            const error = document.createElement('div');
+            error.className = CSS_CLASSES.HISTORY_ERROR;
+            error.textContent = entry.error;
+            item.appendChild(error);
+        } else if (entry.file_path) {
+            const path = document.createElement('div');
+            path.className = CSS_CLASSES.HISTORY_PATH;
+            if (entry.file_path.startsWith(API_BASE)) {
+                const link = document.createElement('a');
+                link.href = entry.file_path;
+                link.download = '';
+                link.textContent = UI_TEXT.HISTORY_DOWNLOAD_FILE;
+                path.appendChild(link);
+            } else {
+                path.textContent = entry.file_path;
+            }
+            item.appendChild(path);
+        }
+
+        list.appendChild(item);
+    });
+
+    if (loadMoreBtn) {
+        loadMoreBtn.classList.toggle(CSS_CLASSES.HIDDEN, historyEntries.length >= historyTotal);
+    }
+}
@@ -0,0 +1,100 @@
+import {
+    LOG_MESSAGES,
+    ERROR_MESSAGES,
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    API_ENDPOINTS,
+    HTTP_METHODS,
+    CONTENT_TYPES
+} from './constants.js';
+import { refreshHistory } from './history.js';
+
+const API_BASE = API_ENDPOINTS.BASE;
+
+export async function initInterruptedBanner() {
+    try {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.INTERRUPTED}`);
+        const jobs = await response.json();
+        if (jobs.length > 0) {
+            renderInterruptedBanner(jobs);
+        }
+    } catch (error) {
+        console.error(LOG_MESSAGES.FAILED_LOAD_INTERRUPTED, error);
+    }
+}
+
+function renderInterruptedBanner(jobs) {
+    const banner = document.getElementById(ELEMENT_IDS.INTERRUPTED_BANNER);
+    if (!banner) return;
+
+    // Playlist items are listed under their playlist rather than on their own.
+    const topLevel = jobs.filter(job => !job.parent_id);
+    banner.innerHTML = '';
+
+    const title = document.createElement('div');
+    title.className = CSS_CLASSES.HEALTH_BANNER_TITLE;
+    title.textContent = topLevel.length === 1
+        ? UI_TEXT.INTERRUPTED_TITLE_ONE
+        : `${topLevel.length} ${UI_TEXT.INTERRUPTED_TITLE}`;
+    banner.appendChild(title);
+
+    const list = document.createElement('ul');
+    list.className = CSS_CLASSES.HEALTH_BANNER_LIST;
+    topLevel.forEach(job => {
+        const item = document.createElement('li');
+        item.textContent = job.title || job.url;
+        item.title = job.url;
+        list.appendChild(item);
+    });
+    banner.appendChild(list);
+
+    const actions = document.createElement('div');
+    actions.className = CSS_CLASSES.HEALTH_BANNER_ACTIONS;
+
+    const resume = document.createElement('button');
+    resume.className = `${CSS_CLASSES.CONTROL_BTN} ${CSS_CLASSES.RESUME_BTN}`;
+    resume.textContent = UI_TEXT.INTERRUPTED_RESUME_ALL;
+    resume.addEventListener('click', () => settleInterrupted(banner, API_ENDPOINTS.INTERRUPTED_RESUME));
+    actions.appendChild(resume);
+
+    const discard = document.createElement('button');
+    discard.className = CSS_CLASSES.CONTROL_BTN;
+    discard.textContent = UI_TEXT.INTERRUPTED_DISCARD_ALL;
+    discard.addEventListener('click', () => settleInterrupted(banner, API_ENDPOINTS.INTERRUPTED_DISCARD));
+    actions.appendChild(discard);
+
+    const dismiss = document.createElement('button');
+    dismiss.className = CSS_CLASSES.CONTROL_BTN;
+    dismiss.textContent = UI_TEXT.DISMISS;
+    dismiss.addEventListener('click', () => banner.classList.add(CSS_CLASSES.HIDDEN));
+    actions.appendChild(dismiss);
+
+    banner.appendChild(actions);
+    banner.classList.remove(CSS_CLASSES.HIDDEN);
+}
+
+// settleInterrupted resumes or discards every interrupted job.
+async function settleInterrupted(banner, endpoint) {
+    try {
+        const response = await fetch(`${API_BASE}${endpoint}`, {
+            method: HTTP_METHODS.POST,
+            headers: {
+                'Content-Type': CONTENT_TYPES.JSON,
+            },
+            body: JSON.stringify({ ids: [] }),
+        });
+        const data = await response.json();
+
+        if (!response.ok) {
+            window.showError(data.message || ERROR_MESSAGES.CONNECTION_ERROR, data.hint);
+            return;
+        }
+
+        banner.classList.add(CSS_CLASSES.HIDDEN);
+        refreshHistory();
+    } catch (error) {
+        console.error(LOG_MESSAGES.FAILED_LOAD_INTERRUPTED, error);
+        window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
+    }
+}
@@ -0,0 +1,325 @@
+import {
+    LOG_MESSAGES,
+    ERROR_MESSAGES,
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    SELECTORS,
+    API_ENDPOINTS,
+    CONTENT_TYPES,
+    HTTP_METHODS,
+    DOWNLOAD_STATUS,
+    JOB_TYPES,
+    PLAYLIST_CONFIG,
+    REGEX_PATTERNS
+} from './constants.js';
+import { trackDownload, hideProgress } from './video_downloader.js';
+
+const API_BASE = API_ENDPOINTS.BASE;
+const TERMINAL_STATUSES = [DOWNLOAD_STATUS.COMPLETED, DOWNLOAD_STATUS.ERROR, DOWNLOAD_STATUS.CANCELLED];
+let currentPlaylist = null;
+
+export function initPlaylist() {
+    const itemsContainer = document.getElementById(ELEMENT_IDS.PLAYLIST_ITEMS);
+    if (!itemsContainer) return;
+
+    itemsContainer.addEventListener('click', (e) => {
+        const action = e.target.dataset.action;
+        if (!action) return;
+
+        switch (action) {
+            case PLAYLIST_CONFIG.ACTION_CANCEL:
+                postJobAction(API_ENDPOINTS.CANCEL, e.target.dataset.id);
+                break;
+            case PLAYLIST_CONFIG.ACTION_RETRY:
+                postJobAction(API_ENDPOINTS.RETRY, e.target.dataset.id);
+                break;
+            case PLAYLIST_CONFIG.ACTION_RETRY_FAILED:
+                postJobAction(API_ENDPOINTS.RETRY, window.getCurrentDownloadId());
+                break;
+            case PLAYLIST_CONFIG.ACTION_CLOSE:
+                hideProgress();
+                break;
+        }
+    });
+}
+
+export function isPlaylistURL(url) {
+    return REGEX_PATTERNS.YOUTUBE_PLAYLIST_URL.test(url) || REGEX_PATTERNS.YOUTUBE_CHANNEL_URL.test(url);
+}
+
+export async function loadPlaylist(url) {
+    const resolutionSection = document.getElementById(ELEMENT_IDS.RESOLUTION_SECTION);
+    resolutionSection.innerHTML = `
+        <div style="text-align: center; padding: 20px;">
+            <span class="loading-spinner"></span>
+            <span style="margin-left: 8px; color: #BBBBBB;">${UI_TEXT.FETCHING_PLAYLIST}</span>
+        </div>
+    `;
+    resolutionSection.classList.remove(CSS_CLASSES.HIDDEN);
+
+    try {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.PLAYLIST_INFO}`, {
+            method: HTTP_METHODS.POST,
+            headers: {
+                'Content-Type': CONTENT_TYPES.JSON,
+            },
+            body: JSON.stringify({ url }),
+        });
+
+        const data = await response.json();
+
+        if (response.ok) {
+            currentPlaylist = data;
+            renderPlaylistSelection(data);
+        } else {
+            window.showError(data.message || ERROR_MESSAGES.FAILED_FETCH_PLAYLIST, data.hint);
+            resolutionSection.classList.add(CSS_CLASSES.HIDDEN);
+        }
+    } catch (error) {
+        window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
+        resolutionSection.classList.add(CSS_CLASSES.HIDDEN);
+    }
+}
+
+function renderPlaylistSelection(playlist) {
+    const resolutionSection = document.getElementById(ELEMENT_IDS.RESOLUTION_SECTION);
+    resolutionSection.innerHTML = '';
+
+    const header = document.createElement('label');
+    header.className = CSS_CLASSES.PLAYLIST_HEADER;
+    const selectAll = document.createElement('input');
+    selectAll.type = 'checkbox';
+    selectAll.id = ELEMENT_IDS.PLAYLIST_SELECT_ALL;
+    selectAll.checked = true;
+    header.appendChild(selectAll);
+    header.appendChild(document.createTextNode(
+        ` ${playlist.title || UI_TEXT.SELECT_ALL} (${playlist.entries.length}${UI_TEXT.PLAYLIST_VIDEOS_SUFFIX})`));
+    resolutionSection.appendChild(header);
+
+    const entries = document.createElement('div');
+    entries.className = CSS_CLASSES.PLAYLIST_ENTRIES;
+    playlist.entries.forEach((entry, i) => {
+        const row = document.createElement('label');
+        row.className = CSS_CLASSES.PLAYLIST_ENTRY;
+
+        const checkbox = document.createElement('input');
+        checkbox.type = 'checkbox';
+        checkbox.checked = true;
+        checkbox.dataset.index = i;
+        row.appendChild(checkbox);
+        row.appendChild(document.createTextNode(` ${entry.index}. ${entry.title || entry.url}`));
+
+        if (entry.duration) {
+            const duration = document.createElement('span');
+            duration.className = CSS_CLASSES.PLAYLIST_DURATION;
+            duration.textContent = entry.duration;
+            row.appendChild(duration);
+        }
+        entries.appendChild(row);
+    });
+    resolutionSection.appendChild(entries);
+
+    selectAll.addEventListener('change', () => {
+        entries.querySelectorAll('input[type="checkbox"]').forEach(box => {
+            box.checked = selectAll.checked;
+        });
+    });
+
+    const options = document.createElement('div');
+    options.className = CSS_CLASSES.PLAYLIST_OPTIONS;
+    options.innerHTML = `
+        <label for="${ELEMENT_IDS.PLAYLIST_TYPE_SELECT}" class="resolution-label">${UI_TEXT.PLAYLIST_TYPE_LABEL}</label>
+        <select id="${ELEMENT_IDS.PLAYLIST_TYPE_SELECT}" class="resolution-select">
+            <option value="${JOB_TYPES.VIDEO}">${UI_TEXT.PLAYLIST_TYPE_VIDEO}</option>
+            <option value="${JOB_TYPES.AUDIO}">${UI_TEXT.PLAYLIST_TYPE_AUDIO}</option>
+        </select>
+        <select id="${ELEMENT_IDS.PLAYLIST_QUALITY_SELECT}" class="resolution-select">
+            ${PLAYLIST_CONFIG.QUALITIES.map(quality => `<option value="${quality}">${quality}</option>`).join('')}
+        </select>
+        <button id="${ELEMENT_IDS.PLAYLIST_DOWNLOAD_BTN}" class="confirm-download-btn">${UI_TEXT.DOWNLOAD_SELECTED}</button>
+    `;
+    resolutionSection.appendChild(options);
+
+    const typeSelect = document.getElementById(ELEMENT_IDS.PLAYLIST_TYPE_SELECT);
+    const qualitySelect = document.getElementById(ELEMENT_IDS.PLAYLIST_QUALITY_SELECT);
+    typeSelect.addEventListener('change', () => {
+        qualitySelect.classList.toggle(CSS_CLASSES.HIDDEN, typeSelect.value === JOB_TYPES.AUDIO);
+    });
+    document.getElementById(ELEMENT_IDS.PLAYLIST_DOWNLOAD_BTN).addEventListener('click', handlePlaylistDownload);
+
+    resolutionSection.classList.remove(CSS_CLASSES.HIDDEN);
+}
+
+async function handlePlaylistDownload() {
+    const resolutionSection = document.getElementById(ELEMENT_IDS.RESOLUTION_SECTION);
+    const downloadBtn = document.getElementById(ELEMENT_IDS.PLAYLIST_DOWNLOAD_BTN);
+    const selected = [...resolutionSection.querySelectorAll(`.${CSS_CLASSES.PLAYLIST_ENTRY} input:checked`)]
+        .map(box => currentPlaylist.entries[box.dataset.index]);
+
+    if (selected.length === 0) {
+        window.showError(ERROR_MESSAGES.SELECT_PLAYLIST_ENTRIES);
+        return;
+    }
+
+    downloadBtn.innerHTML = `<span class="loading-spinner"></span>${UI_TEXT.STARTING}`;
+    downloadBtn.disabled = true;
+
+    try {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.PLAYLIST_DOWNLOAD}`, {
+            method: HTTP_METHODS.POST,
+            headers: {
+                'Content-Type': CONTENT_TYPES.JSON,
+            },
+            body: JSON.stringify({
+                url: currentPlaylist.parsed_url,
+                title: currentPlaylist.title,
+                type: document.getElementById(ELEMENT_IDS.PLAYLIST_TYPE_SELECT).value,
+                quality: document.getElementById(ELEMENT_IDS.PLAYLIST_QUALITY_SELECT).value,
+                entries: selected
+            }),
+        });
+
+        const data = await response.json();
+
+        if (data.success) {
+            renderPlaylistItems(data.job);
+            trackDownload(data.filename);
+            document.getElementById(ELEMENT_IDS.PAUSE_RESUME_BTN)?.classList.add(CSS_CLASSES.HIDDEN);
+        } else {
+            window.showError(data.message || ERROR_MESSAGES.DOWNLOAD_FAILED, data.hint);
+        }
+    } catch (error) {
+        window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
+    } finally {
+        downloadBtn.innerHTML = UI_TEXT.DOWNLOAD_SELECTED;
+        downloadBtn.disabled = false;
+    }
+}
+
+function renderPlaylistItems(job) {
+    const container = document.getElementById(ELEMENT_IDS.PLAYLIST_ITEMS);
+    container.innerHTML = '';
+
+    job.children.forEach(child => {
+        const row = document.createElement('div');
+        row.className = CSS_CLASSES.PLAYLIST_ITEM;
+        row.dataset.id = child.id;
+
+        const title = document.createElement('span');
+        title.className = CSS_CLASSES.PLAYLIST_ITEM_TITLE;
+        title.textContent = child.title || child.url;
+        title.title = child.url;
+
+        const status = document.createElement('span');
+        status.className = CSS_CLASSES.PLAYLIST_ITEM_STATUS;
+
+        const button = document.createElement('button');
+        button.className = `${CSS_CLASSES.CONTROL_BTN} ${CSS_CLASSES.CANCEL_BTN}`;
+        button.dataset.id = child.id;
+
+        row.appendChild(title);
+        row.appendChild(status);
+        row.appendChild(button);
+        container.appendChild(row);
+
+        updateItemRow(row, child.status, UI_TEXT.QUEUED);
+    });
+
+    const actions = document.createElement('div');
+    actions.className = `${CSS_CLASSES.PLAYLIST_ACTIONS} ${CSS_CLASSES.HIDDEN}`;
+    actions.innerHTML = `
+        <button class="${CSS_CLASSES.CONTROL_BTN} ${CSS_CLASSES.PAUSE_BTN}" data-action="${PLAYLIST_CONFIG.ACTION_RETRY_FAILED}">${UI_TEXT.RETRY_FAILED}</button>
+        <button class="${CSS_CLASSES.CONTROL_BTN} ${CSS_CLASSES.CANCEL_BTN}" data-action="${PLAYLIST_CONFIG.ACTION_CLOSE}">${UI_TEXT.CLOSE}</button>
+    `;
+    container.appendChild(actions);
+    container.classList.remove(CSS_CLASSES.HIDDEN);
+}
+
+function updateItemRow(row, status, message) {
+    const statusLabel = row.querySelector(`.${CSS_CLASSES.PLAYLIST_ITEM_STATUS}`);
+    const button = row.querySelector('button');
+
+    statusLabel.textContent = message || status;
+    statusLabel.className = `${CSS_CLASSES.PLAYLIST_ITEM_STATUS} ${CSS_CLASSES.PLAYLIST_ITEM_STATUS_PREFIX}${status}`;
+
+    if (status === DOWNLOAD_STATUS.COMPLETED) {
+        button.classList.add(CSS_CLASSES.HIDDEN);
+    } else if (TERMINAL_STATUSES.includes(status)) {
+        button.classList.remove(CSS_CLASSES.HIDDEN);
+        button.textContent = UI_TEXT.RETRY;
+        button.dataset.action = PLAYLIST_CONFIG.ACTION_RETRY;
+    } else {
+        button.classList.remove(CSS_CLASSES.HIDDEN);
+        button.textContent = UI_TEXT.CANCEL;
+        button.dataset.action = PLAYLIST_CONFIG.ACTION_CANCEL;
+    }
+}
+
+// handlePlaylistItemUpdate applies an update for one item of the playlist
+// currently shown.
+export function handlePlaylistItemUpdate(update) {
+    const container = document.getElementById(ELEMENT_IDS.PLAYLIST_ITEMS);
+    const row = container?.querySelector(`[data-id="${update.id}"]`);
+    if (!row) return;
+
+    let message = update.message;
+    if (update.status === DOWNLOAD_STATUS.DOWNLOADING || update.status === DOWNLOAD_STATUS.CONVERTING) {
+        message = `${Math.round(update.progress)}%${update.speed ? ` · ${update.speed}` : ''}`;
+    }
+    updateItemRow(row, update.status, message);
+}
+
+// handlePlaylistProgressUpdate shows the aggregate progress of the playlist.
+// The item list stays open once the playlist has finished so failed items
+// can be retried.
+export function handlePlaylistProgressUpdate(update) {
+    const progressContainer = document.getElementById(ELEMENT_IDS.PROGRESS_CONTAINER);
+    const progressFill = progressContainer.querySelector(SELECTORS.PROGRESS_FILL);
+    const progressPercentage = progressContainer.querySelector(SELECTORS.PROGRESS_PERCENTAGE);
+    const progressText = progressContainer.querySelector(SELECTORS.PROGRESS_TEXT);
+    const actions = document.querySelector(`#${ELEMENT_IDS.PLAYLIST_ITEMS} .${CSS_CLASSES.PLAYLIST_ACTIONS}`);
+
+    if (progressFill) progressFill.style.width = `${update.progress}%`;
+    if (progressPercentage) progressPercentage.textContent = `${Math.round(update.progress)}%`;
+    if (progressText) progressText.textContent = update.message || UI_TEXT.DOWNLOADING;
+
+    const finished = TERMINAL_STATUSES.includes(update.status);
+    actions?.classList.toggle(CSS_CLASSES.HIDDEN, !finished);
+    const retryButton = actions?.querySelector(`[data-action="${PLAYLIST_CONFIG.ACTION_RETRY_FAILED}"]`);
+    retryButton?.classList.toggle(CSS_CLASSES.HIDDEN, update.status === DOWNLOAD_STATUS.COMPLETED);
+
+    if (update.status === DOWNLOAD_STATUS.ERROR) {
+        window.showError(update.message || ERROR_MESSAGES.PLAYLIST_FAILED, update.hint);
+    }
+}
+
+export function hidePlaylistItems() {
+    const container = document.getElementById(ELEMENT_IDS.PLAYLIST_ITEMS);
+    if (!container) return;
+
+    container.innerHTML = '';
+    container.classList.add(CSS_CLASSES.HIDDEN);
+}
+
+async function postJobAction(endpoint, downloadId) {
+    if (!downloadId) return;
+
+    try {
+        const response = await fetch(`${API_BASE}${endpoint}`, {
+            method: HTTP_METHODS.POST,
+            headers: {
+                'Content-Type': CONTENT_TYPES.JSON,
+            },
+            body: JSON.stringify({ downloadId }),
+        });
+
+        if (!response.ok) {
+            const data = await response.json();
+            window.showError(data.message || ERROR_MESSAGES.FAILED_PLAYLIST_ACTION, data.hint);
+        }
+    } catch (error) {
+        console.error(LOG_MESSAGES.FAILED_PLAYLIST_ACTION, error);
+        window.showError(ERROR_MESSAGES.FAILED_PLAYLIST_ACTION);
+    }
+}
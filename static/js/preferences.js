@@ -0,0 +1,60 @@
+import {
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    FORMAT_CONFIG
+} from './constants.js';
+
+// renderPreferenceOptions adds the codec, frame rate, HDR, size and
+// container pickers to the resolution section. The preferences apply to the
+// "best match" choices; a specific format is downloaded as it is.
+export function renderPreferenceOptions(resolutionSection) {
+    const options = document.createElement('div');
+    options.className = CSS_CLASSES.PREFERENCE_OPTIONS;
+    options.innerHTML = `
+        <label for="${ELEMENT_IDS.CODEC_ORDER_SELECT}" class="resolution-label">${UI_TEXT.PREFERENCES_LABEL}</label>
+        <div class="${CSS_CLASSES.PREFERENCE_CONTROLS}">
+            <select id="${ELEMENT_IDS.CODEC_ORDER_SELECT}" class="resolution-select">
+                ${FORMAT_CONFIG.CODEC_ORDERS.map((order, index) => `<option value="${index}">${order.label}</option>`).join('')}
+            </select>
+            <select id="${ELEMENT_IDS.MAX_FPS_SELECT}" class="resolution-select">
+                ${FORMAT_CONFIG.MAX_FPS.map(fps => `<option value="${fps.value}">${fps.label}</option>`).join('')}
+            </select>
+            <select id="${ELEMENT_IDS.CONTAINER_SELECT}" class="resolution-select">
+                ${FORMAT_CONFIG.CONTAINERS.map(container => `<option value="${container}">${container.toUpperCase()}</option>`).join('')}
+            </select>
+        </div>
+        <div class="${CSS_CLASSES.PREFERENCE_CONTROLS}">
+            <input type="text" id="${ELEMENT_IDS.MAX_FILESIZE_INPUT}" class="resolution-select" placeholder="${UI_TEXT.MAX_FILESIZE_PLACEHOLDER}">
+            <label class="${CSS_CLASSES.CHECKBOX_OPTION}">
+                <input type="checkbox" id="${ELEMENT_IDS.EXCLUDE_HDR}">
+                ${UI_TEXT.EXCLUDE_HDR}
+            </label>
+        </div>
+    `;
+
+    const confirmDownloadBtn = document.getElementById(ELEMENT_IDS.CONFIRM_DOWNLOAD_BTN);
+    resolutionSection.insertBefore(options, confirmDownloadBtn);
+}
+
+// getPreferences returns the preferences part of a download request, or
+// null when nothing is set.
+export function getPreferences() {
+    const codecOrder = document.getElementById(ELEMENT_IDS.CODEC_ORDER_SELECT);
+    if (!codecOrder) return null;
+
+    const preferences = {
+        codecs: FORMAT_CONFIG.CODEC_ORDERS[codecOrder.value].codecs,
+        max_fps: parseInt(document.getElementById(ELEMENT_IDS.MAX_FPS_SELECT).value) || 0,
+        exclude_hdr: document.getElementById(ELEMENT_IDS.EXCLUDE_HDR).checked,
+        max_filesize: document.getElementById(ELEMENT_IDS.MAX_FILESIZE_INPUT).value.trim()
+    };
+    const isSet = preferences.codecs.length > 0 || preferences.max_fps > 0 ||
+        preferences.exclude_hdr || preferences.max_filesize !== '';
+    return isSet ? preferences : null;
+}
+
+export function getContainer() {
+    const containerSelect = document.getElementById(ELEMENT_IDS.CONTAINER_SELECT);
+    return containerSelect ? containerSelect.value : '';
+}
@@ -0,0 +1,118 @@
+import {
+    ERROR_MESSAGES,
+    UI_TEXT,
+    CSS_CLASSES,
+    ELEMENT_IDS,
+    API_ENDPOINTS,
+    CONTENT_TYPES,
+    HTTP_METHODS,
+    SUBTITLE_CONFIG
+} from './constants.js';
+import { trackDownload, getCurrentVideoInfo } from './video_downloader.js';
+
+const API_BASE = API_ENDPOINTS.BASE;
+
+// renderSubtitleOptions adds the subtitle pickers to the resolution
+// section, before the download button.
+export function renderSubtitleOptions(resolutionSection, tracks) {
+    if (!tracks || tracks.length === 0) return;
+
+    const options = document.createElement('div');
+    options.className = CSS_CLASSES.SUBTITLE_OPTIONS;
+    options.innerHTML = `
+        <label for="${ELEMENT_IDS.SUBTITLE_LANGUAGES}" class="resolution-label">${UI_TEXT.SUBTITLES_LABEL}</label>
+        <select id="${ELEMENT_IDS.SUBTITLE_LANGUAGES}" class="resolution-select" multiple size="${SUBTITLE_CONFIG.LIST_SIZE}">
+            ${renderTrackGroup(tracks.filter(track => !track.auto), UI_TEXT.SUBTITLES_UPLOADED)}
+            ${renderTrackGroup(tracks.filter(track => track.auto), UI_TEXT.SUBTITLES_AUTO)}
+        </select>
+        <div class="${CSS_CLASSES.SUBTITLE_CONTROLS}">
+            <select id="${ELEMENT_IDS.SUBTITLE_MODE}" class="resolution-select">
+                <option value="${SUBTITLE_CONFIG.MODE_EMBED}">${UI_TEXT.SUBTITLES_EMBED}</option>
+                <option value="${SUBTITLE_CONFIG.MODE_SIDECAR}">${UI_TEXT.SUBTITLES_SIDECAR}</option>
+            </select>
+            <select id="${ELEMENT_IDS.SUBTITLE_FORMAT}" class="resolution-select">
+                <option value="">${UI_TEXT.SUBTITLES_ORIGINAL_FORMAT}</option>
+                ${SUBTITLE_CONFIG.FORMATS.map(format => `<option value="${format}">${format.toUpperCase()}</option>`).join('')}
+            </select>
+        </div>
+        <button id="${ELEMENT_IDS.SUBTITLES_ONLY_BTN}" class="control-btn pause-btn">${UI_TEXT.SUBTITLES_ONLY}</button>
+    `;
+
+    const confirmDownloadBtn = document.getElementById(ELEMENT_IDS.CONFIRM_DOWNLOAD_BTN);
+    resolutionSection.insertBefore(options, confirmDownloadBtn);
+    document.getElementById(ELEMENT_IDS.SUBTITLES_ONLY_BTN).addEventListener('click', handleSubtitlesOnly);
+}
+
+function renderTrackGroup(tracks, label) {
+    if (tracks.length === 0) return '';
+
+    const options = tracks.map(track => {
+        const value = track.auto ? `${SUBTITLE_CONFIG.AUTO_PREFIX}${track.language}` : track.language;
+        const name = track.name ? `${track.name} (${track.language})` : track.language;
+        return `<option value="${value}">${name}</option>`;
+    }).join('');
+    return `<optgroup label="${label}">${options}</optgroup>`;
+}
+
+// getSubtitleOptions returns the subtitle part of a download request, or
+// null when no language is selected.
+export function getSubtitleOptions() {
+    const languageSelect = document.getElementById(ELEMENT_IDS.SUBTITLE_LANGUAGES);
+    if (!languageSelect) return null;
+
+    const selected = [...languageSelect.selectedOptions].map(option => option.value);
+    if (selected.length === 0) return null;
+
+    const languages = [...new Set(selected.map(value => value.replace(SUBTITLE_CONFIG.AUTO_PREFIX, '')))];
+    return {
+        languages,
+        auto: selected.some(value => value.startsWith(SUBTITLE_CONFIG.AUTO_PREFIX)),
+        mode: document.getElementById(ELEMENT_IDS.SUBTITLE_MODE).value,
+        format: document.getElementById(ELEMENT_IDS.SUBTITLE_FORMAT).value
+    };
+}
+
+async function handleSubtitlesOnly() {
+    const subtitlesOnlyBtn = document.getElementById(ELEMENT_IDS.SUBTITLES_ONLY_BTN);
+    const subtitles = getSubtitleOptions();
+    const videoInfo = getCurrentVideoInfo();
+
+    if (!subtitles) {
+        window.showError(ERROR_MESSAGES.SELECT_SUBTITLE_LANGUAGE);
+        return;
+    }
+
+    if (!videoInfo) {
+        window.showError(ERROR_MESSAGES.VIDEO_INFO_NOT_AVAILABLE);
+        return;
+    }
+
+    subtitlesOnlyBtn.innerHTML = `<span class="loading-spinner"></span>${UI_TEXT.STARTING}`;
+    subtitlesOnlyBtn.disabled = true;
+
+    try {
+        const response = await fetch(`${API_BASE}${API_ENDPOINTS.SUBTITLES}`, {
+            method: HTTP_METHODS.POST,
+            headers: {
+                'Content-Type': CONTENT_TYPES.JSON,
+            },
+            body: JSON.stringify({
+                url: videoInfo.parsed_url,
+                subtitles
+            }),
+        });
+
+        const data = await response.json();
+
+        if (data.success) {
+            trackDownload(data.filename);
+        } else {
+            window.showError(data.message || ERROR_MESSAGES.DOWNLOAD_FAILED, data.hint);
+        }
+    } catch (error) {
+        window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
+    } finally {
+        subtitlesOnlyBtn.innerHTML = UI_TEXT.SUBTITLES_ONLY;
+        subtitlesOnlyBtn.disabled = false;
+    }
+}
@@ -0,0 +1,84 @@
+import {
+    UI_TEXT,
+    ERROR_MESSAGES,
+    ELEMENT_IDS,
+    API_ENDPOINTS,
+    CONTENT_TYPES,
+    HTTP_METHODS,
+    AUDIO_CONFIG
+} from './constants.js';
+
+// renderTagOptions adds one input per tag. Empty inputs keep the tags the
+// server derives from the video; "Load tags" fills them in with those so
+// they can be corrected before the job starts.
+export function renderTagOptions(container, getURL) {
+    if (!container) return;
+
+    container.innerHTML = `
+        <div class="tag-header">
+            <span class="resolution-label">${UI_TEXT.TAGS_LABEL}</span>
+            <button id="${ELEMENT_IDS.LOAD_TAGS_BTN}" class="control-btn">${UI_TEXT.LOAD_TAGS}</button>
+        </div>
+        ${AUDIO_CONFIG.TAG_FIELDS.map(field => `
+            <input type="text" id="${tagInputId(field.key)}" class="url-input" placeholder="${field.label}">
+        `).join('')}
+    `;
+
+    document.getElementById(ELEMENT_IDS.LOAD_TAGS_BTN).addEventListener('click', (e) => {
+        e.preventDefault();
+        loadTags(getURL());
+    });
+}
+
+async function loadTags(url) {
+    if (!url) {
+        window.showError(ERROR_MESSAGES.ENTER_URL);
+        return;
+    }
+
+    const loadTagsBtn = document.getElementById(ELEMENT_IDS.LOAD_TAGS_BTN);
+    loadTagsBtn.innerHTML = `<span class="loading-spinner"></span>`;
+    loadTagsBtn.disabled = true;
+
+    try {
+        const response = await fetch(`${API_ENDPOINTS.BASE}${API_ENDPOINTS.VIDEO_INFO}`, {
+            method: HTTP_METHODS.POST,
+            headers: {
+                'Content-Type': CONTENT_TYPES.JSON,
+            },
+            body: JSON.stringify({ url }),
+        });
+
+        const data = await response.json();
+
+        if (response.ok) {
+            AUDIO_CONFIG.TAG_FIELDS.forEach(field => {
+                document.getElementById(tagInputId(field.key)).value = data.audio_tags?.[field.key] || '';
+            });
+        } else {
+            window.showError(data.message || ERROR_MESSAGES.FAILED_FETCH_VIDEO_INFO, data.hint);
+        }
+    } catch (error) {
+        window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
+    } finally {
+        loadTagsBtn.innerHTML = UI_TEXT.LOAD_TAGS;
+        loadTagsBtn.disabled = false;
+    }
+}
+
+// getTagOverrides returns the filled-in tags, or undefined when every input
+// is empty.
+export function getTagOverrides() {
+    const tags = {};
+    AUDIO_CONFIG.TAG_FIELDS.forEach(field => {
+        const value = document.getElementById(tagInputId(field.key))?.value.trim();
+        if (value) {
+            tags[field.key] = value;
+        }
+    });
+    return Object.keys(tags).length > 0 ? tags : undefined;
+}
+
+function tagInputId(key) {
+    return `${ELEMENT_IDS.AUDIO_TAG_PREFIX}${key}`;
+}
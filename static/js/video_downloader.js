@@ -9,12 +9,19 @@ import {
     CONTENT_TYPES, 
     HTTP_METHODS, 
     TIMEOUTS, 
-    REGEX_PATTERNS 
+    REGEX_PATTERNS,
+    FORMAT_CONFIG
 } from './constants.js';
+import { isPlaylistURL, loadPlaylist, hidePlaylistItems } from './playlist.js';
+import { renderSubtitleOptions, getSubtitleOptions } from './subtitles.js';
+import { renderPreferenceOptions, getPreferences, getContainer } from './preferences.js';
+import { renderClipOptions, getClipOptions } from './clips.js';
+import { renderChapterOptions, getChapterMode } from './chapters.js';
 
 const API_BASE = API_ENDPOINTS.BASE;
 let currentVideoInfo = null;
 let currentDownloadId = null;
+let formatChoices = [];
 
 export function initVideoDownloader() {
     const urlInput = document.getElementById(ELEMENT_IDS.URL_INPUT);
@@ -33,7 +40,11 @@ export function initVideoDownloader() {
         }
         
         debounceTimer = setTimeout(() => {
-            if (isValidYouTubeURL(url)) {
+            if (isYouTubeVideoURL(url)) {
+                fetchVideoInfo(url);
+            } else if (isPlaylistURL(url)) {
+                loadPlaylist(url);
+            } else if (isValidMediaURL(url)) {
                 fetchVideoInfo(url);
             } else {
                 hideResolutionSection();
@@ -52,7 +63,13 @@ export function initVideoDownloader() {
     });
 }
 
-export function isValidYouTubeURL(url) {
+// Any site yt-dlp supports is accepted; the server decides whether the site
+// is allowed.
+export function isValidMediaURL(url) {
+    return REGEX_PATTERNS.MEDIA_URL.test(url);
+}
+
+function isYouTubeVideoURL(url) {
     return REGEX_PATTERNS.YOUTUBE_URL.test(url);
 }
 
@@ -77,7 +94,7 @@ async function fetchVideoInfo(url) {
             populateResolutions(data.formats);
             showResolutionSection();
         } else {
-            window.showError(data.message || ERROR_MESSAGES.FAILED_FETCH_VIDEO_INFO);
+            window.showError(data.message || ERROR_MESSAGES.FAILED_FETCH_VIDEO_INFO, data.hint);
             hideResolutionSection();
         }
     } catch (error) {
@@ -119,18 +136,82 @@ function populateResolutions(formats) {
     });
     newConfirmDownloadBtn.addEventListener('click', handleConfirmDownload);
     
-    const sortedFormats = formats.sort((a, b) => {
-        const resA = parseInt(a.resolution) || 0;
-        const resB = parseInt(b.resolution) || 0;
-        return resB - resA;
-    });
+    const url = document.getElementById(ELEMENT_IDS.URL_INPUT).value.trim();
+    if (isPlaylistURL(url)) {
+        const loadPlaylistBtn = document.createElement('button');
+        loadPlaylistBtn.id = ELEMENT_IDS.LOAD_PLAYLIST_BTN;
+        loadPlaylistBtn.className = 'control-btn pause-btn';
+        loadPlaylistBtn.textContent = UI_TEXT.LOAD_PLAYLIST;
+        loadPlaylistBtn.addEventListener('click', () => loadPlaylist(url));
+        resolutionSection.appendChild(loadPlaylistBtn);
+    }
+    
+    formatChoices = [];
+
+    // The best match choices are built by the server from the format
+    // preferences, or from the resolution alone when none are set.
+    const heights = [...new Set(formats.map(format => parseInt(format.resolution)).filter(Boolean))];
+    addFormatGroup(newResolutionSelect, UI_TEXT.BEST_MATCH_FORMATS, [
+        { label: UI_TEXT.BEST_MATCH, quality: FORMAT_CONFIG.BEST_QUALITY, usePreferences: true },
+        ...heights.map(height => ({
+            label: `${UI_TEXT.BEST_MATCH_UP_TO}${height}p`,
+            quality: `${height}p`,
+            usePreferences: true
+        }))
+    ]);
+    addFormatGroup(newResolutionSelect, UI_TEXT.RECOMMENDED_FORMATS, formats.map(format => ({
+        label: describeFormat(format.quality || format.resolution, format),
+        quality: format.quality || format.resolution,
+        videoFormatId: format.format_id,
+        audioFormatId: format.audio_format_id || ''
+    })));
+
+    // Every video format, including the codecs and frame rates the
+    // recommended list leaves out. The server adds the best audio.
+    const videoFormats = (currentVideoInfo.all_formats || [])
+        .filter(format => format.kind !== FORMAT_CONFIG.KIND_AUDIO)
+        .sort((a, b) => (b.height || 0) - (a.height || 0));
+    addFormatGroup(newResolutionSelect, UI_TEXT.ALL_FORMATS, videoFormats.map(format => ({
+        label: describeFormat(`${format.resolution || FORMAT_CONFIG.RESOLUTION_UNKNOWN} [${format.format_id}]`, format),
+        quality: format.resolution,
+        videoFormatId: format.format_id,
+        audioFormatId: ''
+    })));
     
-    sortedFormats.forEach(format => {
+    renderPreferenceOptions(resolutionSection);
+    renderSubtitleOptions(resolutionSection, currentVideoInfo.subtitles);
+    renderChapterOptions(resolutionSection, currentVideoInfo.chapters);
+    renderClipOptions(resolutionSection);
+}
+
+function addFormatGroup(select, label, choices) {
+    if (choices.length === 0) {
+        return;
+    }
+    const group = document.createElement('optgroup');
+    group.label = label;
+    choices.forEach(choice => {
         const option = document.createElement('option');
-        option.value = format.format_id;
-        option.textContent = `${format.resolution} ${format.ext ? `(${format.ext.toUpperCase()})` : ''}${format.filesize ? ` - ${format.filesize}` : ''}`;
-        newResolutionSelect.appendChild(option);
+        option.value = formatChoices.length;
+        option.textContent = choice.label;
+        formatChoices.push(choice);
+        group.appendChild(option);
     });
+    select.appendChild(group);
+}
+
+function describeFormat(name, format) {
+    const details = [format.ext, shortCodec(format.vcodec), shortCodec(format.acodec)]
+        .filter(Boolean)
+        .map(detail => detail.toUpperCase());
+    return `${name}${details.length ? ` (${details.join(', ')})` : ''}${format.filesize ? ` - ${format.filesize}` : ''}`;
+}
+
+function shortCodec(codec) {
+    if (!codec || codec === FORMAT_CONFIG.CODEC_NONE) {
+        return '';
+    }
+    return codec.split(FORMAT_CONFIG.CODEC_SEPARATOR)[0];
 }
 
 function showResolutionSection() {
@@ -146,9 +227,9 @@ function hideResolutionSection() {
 async function handleConfirmDownload() {
     const resolutionSelect = document.getElementById(ELEMENT_IDS.RESOLUTION_SELECT);
     const confirmDownloadBtn = document.getElementById(ELEMENT_IDS.CONFIRM_DOWNLOAD_BTN);
-    const selectedQuality = resolutionSelect.value;
+    const selectedFormat = formatChoices[resolutionSelect.value];
     
-    if (!selectedQuality) {
+    if (!selectedFormat) {
         window.showError(ERROR_MESSAGES.SELECT_RESOLUTION);
         return;
     }
@@ -169,19 +250,23 @@ async function handleConfirmDownload() {
             },
             body: JSON.stringify({ 
                 url: currentVideoInfo.parsed_url, 
-                quality: selectedQuality 
+                quality: selectedFormat.quality,
+                video_format_id: selectedFormat.videoFormatId || undefined,
+                audio_format_id: selectedFormat.audioFormatId || undefined,
+                preferences: selectedFormat.usePreferences ? getPreferences() : undefined,
+                container: getContainer(),
+                subtitles: getSubtitleOptions(),
+                clip: getClipOptions(),
+                chapters: getChapterMode()
             }),
         });
         
         const data = await response.json();
         
         if (data.success) {
-            currentDownloadId = data.filename;
-            window.setCurrentDownloadId(data.filename);
-            showProgress();
-            hideResolutionSection();
+            trackDownload(data.filename);
         } else {
-            window.showError(data.message || ERROR_MESSAGES.DOWNLOAD_FAILED);
+            window.showError(data.message || ERROR_MESSAGES.DOWNLOAD_FAILED, data.hint);
         }
     } catch (error) {
         window.showError(ERROR_MESSAGES.CONNECTION_ERROR);
@@ -191,6 +276,14 @@ async function handleConfirmDownload() {
     }
 }
 
+// trackDownload shows the progress panel for a job that has just started.
+export function trackDownload(id) {
+    currentDownloadId = id;
+    window.setCurrentDownloadId(id);
+    showProgress();
+    hideResolutionSection();
+}
+
 function showProgress() {
     const progressContainer = document.getElementById(ELEMENT_IDS.PROGRESS_CONTAINER);
     const urlInput = document.getElementById(ELEMENT_IDS.URL_INPUT);
@@ -207,6 +300,14 @@ export function hideProgress() {
     progressContainer.classList.add(CSS_CLASSES.HIDDEN);
     currentDownloadId = null;
     
+    isPaused = false;
+    const pauseResumeBtn = document.getElementById(ELEMENT_IDS.PAUSE_RESUME_BTN);
+    if (pauseResumeBtn) {
+        pauseResumeBtn.textContent = UI_TEXT.PAUSE;
+        pauseResumeBtn.className = 'control-btn pause-btn';
+    }
+    hidePlaylistItems();
+    
     urlInput.disabled = false;
     urlInput.style.opacity = '1';
     
@@ -222,7 +323,10 @@ export function hideProgress() {
     if (downloadEta) downloadEta.textContent = UI_TEXT.ETA_PLACEHOLDER;
     if (progressText) progressText.textContent = UI_TEXT.DOWNLOADING;
     
-    if (currentVideoInfo && document.getElementById(ELEMENT_IDS.URL_INPUT).value.trim() && isValidYouTubeURL(document.getElementById(ELEMENT_IDS.URL_INPUT).value.trim())) {
+    const url = urlInput.value.trim();
+    if (currentVideoInfo && url && isValidMediaURL(url)) {
+        showResolutionSection();
+    } else if (url && isPlaylistURL(url)) {
         showResolutionSection();
     }
 }